@@ -6,28 +6,60 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 )
 
 func main() {
-	var packageNamePip string
-	var packageNameNpm string
-	var packageManager string
+	var kinds string
+	var registry string
+	var framework string
+	var platform bool
+	var pythonEnv string
+	var lockfile string
+	var manifest string
+	var sbom string
+	var format string
+	var ascii bool
 
-	flag.StringVar(&packageNamePip, app.Pip, "", "fetch dependency graph of package from pip")
-	flag.StringVar(&packageNameNpm, app.Npm, "", "fetch dependency graph of package from npm")
-	flag.Parse()
-
-	if packageNameNpm != "" && packageNamePip != "" {
-		exitWithMessage("You may specify only one package manager")
-	} else if packageNameNpm != "" {
-		packageManager = app.Npm
-	} else if packageNamePip != "" {
-		packageManager = app.Pip
+	packageNames := make(map[string]*string, len(app.PackageManagers))
+	for _, manager := range app.PackageManagers {
+		packageNames[manager] = flag.String(manager, "", "fetch dependency graph of package from "+manager)
 	}
+	flag.StringVar(&kinds, "kinds", "", "comma separated list of dependency kinds to traverse (npm: prod,dev,optional,peer; cargo: normal,build,dev; gem: runtime,development)")
+	flag.StringVar(&framework, "framework", "", "target framework moniker of nuget packages, e.g. net8.0 (all frameworks by default)")
+	flag.BoolVar(&platform, "platform", false, "show php, ext-* and lib-* requirements of composer packages")
+	flag.StringVar(&pythonEnv, "python-env", "", "target environment of pip packages used to evaluate markers, e.g. python_version=3.11,sys_platform=linux")
+	flag.StringVar(&lockfile, "lockfile", "", "read dependency graph from a lockfile (package-lock.json, npm-shrinkwrap.json, yarn.lock, pnpm-lock.yaml, poetry.lock, Pipfile.lock, uv.lock, Cargo.lock, Gemfile.lock, go.mod, vendor/modules.txt) or a project directory instead of the registry, \"-\" reads go mod graph output from stdin")
+	flag.StringVar(&manifest, "manifest", "", "draw dependencies of a python project from requirements.txt or pyproject.toml")
+	flag.StringVar(&sbom, "sbom", "", "read dependency graph from a CycloneDX (JSON, XML) or SPDX 2.x (JSON, tag-value) sbom")
+	flag.StringVar(&format, "format", app.FormatDot, "output format: "+strings.Join(app.Formats, ", "))
+	flag.BoolVar(&ascii, "ascii", false, "draw the tree format with ascii characters")
+	flag.StringVar(&registry, "registry", "", "override registry url of the package manager")
+	flag.Parse()
 
 	c := &app.Config{
-		PackageName:    packageNamePip + packageNameNpm,
-		PackageManager: packageManager,
+		RegistryURL:       registry,
+		TargetFramework:   framework,
+		ShowPlatform:      platform,
+		PythonEnvironment: pythonEnv,
+		Lockfile:          lockfile,
+		Manifest:          manifest,
+		SBOM:              sbom,
+		Format:            format,
+		ASCII:             ascii,
+	}
+	for _, manager := range app.PackageManagers {
+		if *packageNames[manager] == "" {
+			continue
+		}
+		if c.PackageManager != "" {
+			exitWithMessage("You may specify only one package manager")
+		}
+		c.PackageManager = manager
+		c.PackageName = *packageNames[manager]
+	}
+	if kinds != "" {
+		c.Kinds = strings.Split(kinds, ",")
 	}
 	ctx := context.Background()
 
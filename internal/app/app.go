@@ -2,29 +2,59 @@ package app
 
 import (
 	"context"
+	"depviz/internal/dependency_provider/cargo"
+	"depviz/internal/dependency_provider/composer"
+	"depviz/internal/dependency_provider/gem"
+	"depviz/internal/dependency_provider/gomod"
+	"depviz/internal/dependency_provider/lockfile"
+	"depviz/internal/dependency_provider/maven"
 	"depviz/internal/dependency_provider/npm"
+	"depviz/internal/dependency_provider/nuget"
 	"depviz/internal/dependency_provider/pip"
+	"depviz/internal/dependency_provider/sbom"
 	"depviz/internal/models"
+	"depviz/internal/serializer/cyclonedx"
 	"depviz/internal/serializer/dot"
-	"errors"
+	"depviz/internal/serializer/gexf"
+	"depviz/internal/serializer/gml"
+	"depviz/internal/serializer/graphml"
+	"depviz/internal/serializer/html"
+	"depviz/internal/serializer/json"
+	"depviz/internal/serializer/mermaid"
+	"depviz/internal/serializer/plantuml"
+	"depviz/internal/serializer/spdx"
+	"depviz/internal/serializer/tree"
 	"fmt"
 	"io"
 	"os"
-	"sync"
 )
 
 const _defaultConcurrency = 256
 
-type fetchTask struct {
-	packageName string
+// fetchResult holds the dependencies of a package fetched by a worker.
+type fetchResult struct {
+	pkg  models.Package
+	deps []models.Dependency
+	err  error
 }
 
 type DepsProvider interface {
-	FetchPackageDeps(ctx context.Context, packageName string) ([]string, error)
+	// Resolve turns a package spec given by the user, e.g. "react@17.0.2", into a package.
+	Resolve(ctx context.Context, spec string) (models.Package, error)
+	FetchPackageDeps(ctx context.Context, pkg models.Package) ([]models.Dependency, error)
+}
+
+// RootDepsProvider is implemented by providers which read more dependencies of
+// the root package than of the packages it depends on, e.g. dev dependencies.
+// The root is fetched with FetchRootDeps instead of FetchPackageDeps.
+type RootDepsProvider interface {
+	FetchRootDeps(ctx context.Context, root models.Package) ([]models.Dependency, error)
 }
 
 type Serializer interface {
-	Serialize(graph []models.Edge, out io.Writer) error
+	// Serialize writes the graph of the root package, which has no edges if
+	// the root has no dependencies.
+	Serialize(root models.Package, graph []models.Edge, out io.Writer) error
 }
 
 type App struct {
@@ -39,90 +69,110 @@ func New(provider DepsProvider, serializer Serializer) *App {
 	}
 }
 
-func (a *App) GetDependencyGraph(ctx context.Context, packageName string) ([]models.Edge, error) {
-
-	// tasksWg counts remaining tasks, not goroutines
-	tasksWg := &sync.WaitGroup{}
-
-	// graph with all package dependencies
-	var result []models.Edge
-	resMutex := &sync.Mutex{}
-
-	// A set of visited dependencies
-	visited := make(map[string]struct{})
-	visMutex := &sync.Mutex{}
-
-	// channel with fetching tasks
-	taskChan := make(chan fetchTask, _defaultConcurrency)
+// GetDependencyGraph resolves the package and returns it with the edges of
+// all packages reachable from it. Python packages with extras are merged into
+// the package without them, see mergeExtras.
+func (a *App) GetDependencyGraph(ctx context.Context, packageName string) (models.Package, []models.Edge, error) {
+	root, err := a.DepsProvider.Resolve(ctx, packageName)
+	if err != nil {
+		return models.Package{}, nil, err
+	}
 
-	// may contain first caught error
-	var firstErr error
-	var once sync.Once
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	tasksWg.Add(1)
-	taskChan <- fetchTask{packageName: packageName}
-	ctx2, cancel := context.WithCancel(ctx)
+	// Workers only fetch. The queue and the visited set belong to the loop
+	// below, so a worker never waits for another one to take its packages.
+	tasks := make(chan models.Package)
+	results := make(chan fetchResult)
 	for i := 0; i < _defaultConcurrency; i++ {
-		go func(ctx context.Context) {
-			for {
+		go func() {
+			for pkg := range tasks {
+				deps, err := a.fetchDeps(ctx, pkg, pkg == root)
 				select {
+				case results <- fetchResult{pkg: pkg, deps: deps, err: err}:
 				case <-ctx.Done():
 					return
-				case task, ok := <-taskChan:
-					if !ok {
-						return
-					}
-					if ok := pushIfNotExist(visited, visMutex, task.packageName); !ok {
-						tasksWg.Done()
-						return
-					}
-
-					deps, err := a.DepsProvider.FetchPackageDeps(ctx, task.packageName)
-
-					if err != nil {
-						tasksWg.Done()
-						if errors.Is(err, context.Canceled) {
-							return
-						}
-
-						once.Do(func() {
-							firstErr = err
-							cancel()
-						})
-						return
-					}
-					resMutex.Lock()
-					for _, dep := range deps {
-						result = append(result, models.Edge{From: task.packageName, To: dep})
-					}
-					resMutex.Unlock()
-
-					tasksWg.Add(len(deps))
-					for _, dep := range deps {
-						taskChan <- fetchTask{dep}
-					}
-					tasksWg.Done()
 				}
 			}
-		}(ctx2)
+		}()
+	}
+	defer close(tasks)
+
+	// graph with all package dependencies
+	var result []models.Edge
+	queue := []models.Package{root}
+	visited := map[models.Package]struct{}{root: {}}
+	for running := 0; len(queue) != 0 || running != 0; {
+		// sending on a nil channel blocks, so nothing is sent while the queue is empty
+		var next chan<- models.Package
+		var pkg models.Package
+		if len(queue) != 0 {
+			next, pkg = tasks, queue[0]
+		}
+
+		select {
+		case next <- pkg:
+			queue = queue[1:]
+			running++
+		case fetched := <-results:
+			running--
+			if fetched.err != nil {
+				return models.Package{}, nil, fetched.err
+			}
+			for _, dep := range fetched.deps {
+				result = append(result, models.Edge{
+					From:       fetched.pkg,
+					To:         dep.Package,
+					Constraint: dep.Constraint,
+					Kind:       dep.Kind,
+					Optional:   dep.Optional,
+				})
+				if _, ok := visited[dep.Package]; !ok {
+					visited[dep.Package] = struct{}{}
+					queue = append(queue, dep.Package)
+				}
+			}
+		}
+	}
+	return root.WithoutExtras(), mergeExtras(result), nil
+}
+
+// mergeExtras draws python packages with extras as the package itself, e.g.
+// requests[socks] as requests: extras only select optional dependencies of the
+// same package, which become its edges. Edges repeated by the merge and loops
+// between a package and its extras are dropped.
+func mergeExtras(graph []models.Edge) []models.Edge {
+	result := make([]models.Edge, 0, len(graph))
+	seen := make(map[models.Edge]struct{}, len(graph))
+	for _, edge := range graph {
+		merged := edge
+		merged.From, merged.To = edge.From.WithoutExtras(), edge.To.WithoutExtras()
+		if merged.From == merged.To && edge.From != edge.To {
+			continue
+		}
+		if _, ok := seen[merged]; !ok {
+			seen[merged] = struct{}{}
+			result = append(result, merged)
+		}
 	}
+	return result
+}
 
-	tasksWg.Wait()
-	close(taskChan)
-	cancel()
-	if firstErr != nil {
-		return nil, firstErr
+func (a *App) fetchDeps(ctx context.Context, pkg models.Package, isRoot bool) ([]models.Dependency, error) {
+	if p, ok := a.DepsProvider.(RootDepsProvider); ok && isRoot {
+		return p.FetchRootDeps(ctx, pkg)
 	}
-	return result, nil
+	return a.DepsProvider.FetchPackageDeps(ctx, pkg)
 }
 
 func (a *App) Run(ctx context.Context, packageName string, output io.Writer) error {
-	graph, err := a.GetDependencyGraph(ctx, packageName)
+	root, graph, err := a.GetDependencyGraph(ctx, packageName)
 	if err != nil {
 		return fmt.Errorf("can't receive dependency graph: %w", err)
 	}
 
-	if err := a.Serializer.Serialize(graph, output); err != nil {
+	if err := a.Serializer.Serialize(root, graph, output); err != nil {
 		return fmt.Errorf("can't write output: %w", err)
 	}
 	return nil
@@ -132,28 +182,156 @@ func Run(ctx context.Context, cfg *Config) error {
 	if err := cfg.Validate(); err != nil {
 		return err
 	}
+	var provider DepsProvider
+	switch {
+	case cfg.Lockfile != "":
+		graph, err := readLockfile(cfg)
+		if err != nil {
+			return err
+		}
+		provider = graph
+	case cfg.SBOM != "":
+		graph, err := sbom.ReadFile(cfg.SBOM)
+		if err != nil {
+			return err
+		}
+		provider = graph
+	case cfg.Manifest != "":
+		manifest, err := pip.ReadManifest(cfg.Manifest)
+		if err != nil {
+			return err
+		}
+		provider = pip.NewManifestProvider(pipProvider(cfg), manifest)
+	default:
+		provider = getProvider(cfg)
+	}
 	app := App{
-		DepsProvider: getProviderByName(cfg.PackageManager),
-		Serializer:   &dot.DotSerializer{},
+		DepsProvider: provider,
+		Serializer:   getSerializer(cfg),
 	}
 	return app.Run(ctx, cfg.PackageName, os.Stdout)
 }
 
-func getProviderByName(name string) DepsProvider {
-	switch name {
-	case "pip":
-		return pip.Default()
-	case "npm":
-		return npm.Default()
+func readLockfile(cfg *Config) (*lockfile.Graph, error) {
+	var graph *lockfile.Graph
+	var err error
+	if cfg.Lockfile == "-" {
+		graph, err = lockfile.ParseModGraph(os.Stdin)
+	} else {
+		graph, err = lockfile.ReadFile(cfg.Lockfile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	graph.Kinds = cfg.Kinds
+	if len(graph.Kinds) == 0 {
+		graph.Kinds = graph.DefaultKinds()
+	}
+	return graph, nil
+}
+
+func getSerializer(cfg *Config) Serializer {
+	switch cfg.Format {
+	case FormatTree:
+		return &tree.TreeSerializer{ASCII: cfg.ASCII, Color: useColor(os.Stdout)}
+	case FormatJSON:
+		return &json.JSONSerializer{}
+	case FormatMermaid:
+		return &mermaid.MermaidSerializer{}
+	case FormatPlantUML:
+		return &plantuml.PlantUMLSerializer{}
+	case FormatGraphML:
+		return &graphml.GraphMLSerializer{}
+	case FormatGEXF:
+		return &gexf.GEXFSerializer{}
+	case FormatGML:
+		return &gml.GMLSerializer{}
+	case FormatHTML:
+		return &html.HTMLSerializer{}
+	case FormatCycloneDX:
+		return &cyclonedx.CycloneDXSerializer{}
+	case FormatSPDX:
+		return &spdx.SPDXSerializer{}
 	default:
-		panic("unknown provider type: " + name)
+		return &dot.DotSerializer{}
 	}
 }
 
-func pushIfNotExist(set map[string]struct{}, mu *sync.Mutex, key string) bool {
-	mu.Lock()
-	defer mu.Unlock()
-	_, ok := set[key]
-	set[key] = struct{}{}
-	return !ok
+// useColor reports whether f is a terminal and colors aren't turned off with NO_COLOR.
+func useColor(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func getProvider(cfg *Config) DepsProvider {
+	switch cfg.PackageManager {
+	case Pip:
+		return pipProvider(cfg)
+	case Npm:
+		p := npm.Default()
+		p.BaseURL = registryURL(cfg, p.BaseURL)
+		if len(cfg.Kinds) != 0 {
+			p.Kinds = cfg.Kinds
+		}
+		return p
+	case Cargo:
+		p := cargo.Default()
+		p.BaseURL = registryURL(cfg, p.BaseURL)
+		if len(cfg.Kinds) != 0 {
+			p.Kinds = cfg.Kinds
+		}
+		return p
+	case Go:
+		p := gomod.Default()
+		p.BaseURL = registryURL(cfg, p.BaseURL)
+		return p
+	case Maven:
+		p := maven.Default()
+		p.BaseURL = registryURL(cfg, p.BaseURL)
+		return p
+	case Gem:
+		p := gem.Default()
+		p.BaseURL = registryURL(cfg, p.BaseURL)
+		if len(cfg.Kinds) != 0 {
+			p.Kinds = cfg.Kinds
+		}
+		return p
+	case NuGet:
+		p := nuget.Default()
+		p.BaseURL = registryURL(cfg, p.BaseURL)
+		p.TargetFramework = cfg.TargetFramework
+		return p
+	case Composer:
+		p := composer.Default()
+		p.BaseURL = registryURL(cfg, p.BaseURL)
+		if len(cfg.Kinds) != 0 {
+			p.Kinds = cfg.Kinds
+		}
+		if cfg.ShowPlatform && !contains(p.Kinds, composer.KindPlatform) {
+			p.Kinds = append(append([]string(nil), p.Kinds...), composer.KindPlatform)
+		}
+		return p
+	default:
+		panic("unknown provider type: " + cfg.PackageManager)
+	}
+}
+
+func pipProvider(cfg *Config) *pip.DependencyProvider {
+	p := pip.Default()
+	p.BaseURL = registryURL(cfg, p.BaseURL)
+	if cfg.PythonEnvironment != "" {
+		// the environment is checked by Config.Validate
+		p.Environment, _ = pip.ParseEnvironment(cfg.PythonEnvironment)
+	}
+	return p
+}
+
+func registryURL(cfg *Config, defaultURL string) string {
+	if cfg.RegistryURL != "" {
+		return cfg.RegistryURL
+	}
+	return defaultURL
 }
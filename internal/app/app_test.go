@@ -4,13 +4,17 @@ import (
 	"bytes"
 	"context"
 	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/dependency_provider/npm"
 	"depviz/internal/dependency_provider/pip"
 	"depviz/internal/dependency_provider/pip/test_utils"
 	"depviz/internal/models"
 	"depviz/internal/serializer/dot"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"sort"
 	"testing"
 	"time"
@@ -43,10 +47,12 @@ func TestApp_GetDependencyGraph(t *testing.T) {
 	defer srv.Close()
 
 	t.Run("test fetching pip graph with sub dependencies", func(t *testing.T) {
+		fastapi := models.Package{Ecosystem: models.PyPI, Name: "fastapi"}
+		starlette := models.Package{Ecosystem: models.PyPI, Name: "starlette"}
 		expected := []models.Edge{
-			{"fastapi", "pydantic"},
-			{"fastapi", "starlette"},
-			{"starlette", "asyncio"},
+			{From: fastapi, To: models.Package{Ecosystem: models.PyPI, Name: "pydantic"}, Constraint: ">=3"},
+			{From: fastapi, To: starlette},
+			{From: starlette, To: models.Package{Ecosystem: models.PyPI, Name: "asyncio"}},
 		}
 		sortEdges(expected)
 
@@ -58,7 +64,7 @@ func TestApp_GetDependencyGraph(t *testing.T) {
 			Client:  &http.Client{},
 		}
 		app := New(d, nil)
-		deps, err := app.GetDependencyGraph(ctx, "fastapi")
+		_, deps, err := app.GetDependencyGraph(ctx, "fastapi")
 		sortEdges(deps)
 
 		assert.NoError(t, err)
@@ -66,6 +72,146 @@ func TestApp_GetDependencyGraph(t *testing.T) {
 	})
 }
 
+type stubProvider map[models.Package][]models.Dependency
+
+func (p stubProvider) Resolve(_ context.Context, spec string) (models.Package, error) {
+	return models.Package{Ecosystem: models.Npm, Name: spec, Version: "1.0.0"}, nil
+}
+
+func (p stubProvider) FetchPackageDeps(_ context.Context, pkg models.Package) ([]models.Dependency, error) {
+	return p[pkg], nil
+}
+
+// rootStubProvider adds dev dependencies to the root of a stubProvider.
+type rootStubProvider struct {
+	stubProvider
+	dev []models.Dependency
+}
+
+func (p rootStubProvider) FetchRootDeps(ctx context.Context, root models.Package) ([]models.Dependency, error) {
+	deps, err := p.FetchPackageDeps(ctx, root)
+	return append(deps, p.dev...), err
+}
+
+func TestApp_GetDependencyGraph_RootDeps(t *testing.T) {
+	t.Run("test only the root is fetched with FetchRootDeps", func(t *testing.T) {
+		app := models.Package{Ecosystem: models.Npm, Name: "app", Version: "1.0.0"}
+		lib := models.Package{Ecosystem: models.Npm, Name: "lib", Version: "1.0.0"}
+		tester := models.Package{Ecosystem: models.Npm, Name: "tester", Version: "1.0.0"}
+		d := rootStubProvider{
+			stubProvider: stubProvider{app: {{Package: lib}}, lib: {{Package: app}}},
+			dev:          []models.Dependency{{Package: tester, Kind: "dev"}},
+		}
+
+		_, graph, err := New(d, nil).GetDependencyGraph(context.Background(), "app")
+		sortEdges(graph)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Edge{
+			{From: app, To: lib},
+			{From: app, To: tester, Kind: "dev"},
+			{From: lib, To: app},
+		}, graph)
+	})
+}
+
+func TestApp_GetDependencyGraph_Versions(t *testing.T) {
+	t.Run("test versions of a package are separate nodes", func(t *testing.T) {
+		app := models.Package{Ecosystem: models.Npm, Name: "app", Version: "1.0.0"}
+		legacy := models.Package{Ecosystem: models.Npm, Name: "legacy", Version: "2.0.0"}
+		lodash4 := models.Package{Ecosystem: models.Npm, Name: "lodash", Version: "4.17.21"}
+		lodash3 := models.Package{Ecosystem: models.Npm, Name: "lodash", Version: "3.10.1"}
+		d := stubProvider{
+			app: {
+				{Package: legacy, Constraint: "^2.0.0"},
+				{Package: lodash4, Constraint: "^4.17.0", Kind: "dev"},
+			},
+			legacy: {
+				{Package: lodash3, Constraint: "^3.0.0", Optional: true},
+				{Package: lodash4, Constraint: "^4.0.0"},
+			},
+		}
+		expected := []models.Edge{
+			{From: app, To: legacy, Constraint: "^2.0.0"},
+			{From: app, To: lodash4, Constraint: "^4.17.0", Kind: "dev"},
+			{From: legacy, To: lodash3, Constraint: "^3.0.0", Optional: true},
+			{From: legacy, To: lodash4, Constraint: "^4.0.0"},
+		}
+		sortEdges(expected)
+
+		root, deps, err := New(d, nil).GetDependencyGraph(context.Background(), "app")
+		sortEdges(deps)
+		assert.NoError(t, err)
+		assert.Equal(t, app, root)
+		assert.Equal(t, expected, deps)
+	})
+}
+
+func TestApp_GetDependencyGraph_Extras(t *testing.T) {
+	t.Run("test python packages with extras are merged", func(t *testing.T) {
+		app := models.Package{Ecosystem: models.Npm, Name: "app", Version: "1.0.0"}
+		requests := models.Package{Ecosystem: models.PyPI, Name: "requests", Version: "2.31.0"}
+		socks := models.Package{Ecosystem: models.PyPI, Name: "requests[socks]", Version: "2.31.0"}
+		urllib3 := models.Package{Ecosystem: models.PyPI, Name: "urllib3", Version: "2.1.0"}
+		pysocks := models.Package{Ecosystem: models.PyPI, Name: "pysocks", Version: "1.7.1"}
+		d := stubProvider{
+			app:      {{Package: requests}, {Package: socks}},
+			requests: {{Package: urllib3, Constraint: ">=1.21.1"}},
+			socks: {
+				{Package: urllib3, Constraint: ">=1.21.1"},
+				{Package: pysocks, Constraint: "!=1.5.7", Optional: true},
+				{Package: requests},
+			},
+		}
+		expected := []models.Edge{
+			{From: app, To: requests},
+			{From: requests, To: pysocks, Constraint: "!=1.5.7", Optional: true},
+			{From: requests, To: urllib3, Constraint: ">=1.21.1"},
+		}
+
+		root, graph, err := New(d, nil).GetDependencyGraph(context.Background(), "app")
+		sortEdges(graph)
+		assert.NoError(t, err)
+		assert.Equal(t, app, root)
+		assert.Equal(t, expected, graph)
+	})
+}
+
+func TestApp_GetDependencyGraph_Wide(t *testing.T) {
+	t.Run("test graph wider than the workers doesn't block", func(t *testing.T) {
+		// every package depends on the next 8, like a large lockfile
+		const size, width = 1200, 8
+		packages := make([]models.Package, size)
+		for i := range packages {
+			packages[i] = models.Package{Ecosystem: models.Npm, Name: fmt.Sprintf("pkg-%d", i), Version: "1.0.0"}
+		}
+		root := models.Package{Ecosystem: models.Npm, Name: "app", Version: "1.0.0"}
+		d := stubProvider{}
+		for _, pkg := range packages {
+			d[root] = append(d[root], models.Dependency{Package: pkg})
+		}
+		for i, pkg := range packages {
+			for j := 1; j <= width; j++ {
+				d[pkg] = append(d[pkg], models.Dependency{Package: packages[(i+j)%size]})
+			}
+		}
+
+		done := make(chan struct{})
+		var graph []models.Edge
+		var err error
+		go func() {
+			_, graph, err = New(d, nil).GetDependencyGraph(context.Background(), "app")
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatal("walking the graph didn't finish")
+		}
+		assert.NoError(t, err)
+		assert.Len(t, graph, size+size*width)
+	})
+}
+
 func TestApp_Run(t *testing.T) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/fastapi/json",
@@ -133,6 +279,22 @@ func TestApp_Run(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, expected, buf.String())
 	})
+	t.Run("test root package without dependencies", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := &pip.DependencyProvider{
+			BaseURL: srv.URL,
+			Client:  &http.Client{},
+		}
+		s := &dot.DotSerializer{}
+
+		buf := &bytes.Buffer{}
+		app := New(d, s)
+		err := app.Run(ctx, "pydantic", buf)
+		assert.NoError(t, err)
+		assert.Equal(t, "digraph dependencies {\n\t1 [label=\"pydantic\"];\n}", buf.String())
+	})
 	t.Run("test if some of dependencies do not exist", func(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
 		defer cancel()
@@ -166,11 +328,36 @@ func TestApp_Run(t *testing.T) {
 	})
 }
 
+func TestReadLockfile(t *testing.T) {
+	t.Run("test dev dependencies are left out by default", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "package-lock.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"lockfileVersion": 3, "packages": {
+			"": {"name": "app", "version": "1.0.0", "dependencies": {"a": "^1.0.0"}, "devDependencies": {"tester": "*"}},
+			"node_modules/a": {"version": "1.2.0"},
+			"node_modules/tester": {"version": "2.0.0", "dev": true}
+		}}`), 0o644))
+
+		graph, err := readLockfile(&Config{Lockfile: path})
+		assert.NoError(t, err)
+		deps, err := graph.FetchPackageDeps(context.Background(), graph.Root)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: models.Package{Ecosystem: models.Npm, Name: "a", Version: "1.2.0"}, Constraint: "^1.0.0", Kind: npm.KindProd},
+		}, deps)
+
+		graph, err = readLockfile(&Config{Lockfile: path, Kinds: []string{npm.KindProd, npm.KindDev}})
+		assert.NoError(t, err)
+		deps, err = graph.FetchPackageDeps(context.Background(), graph.Root)
+		assert.NoError(t, err)
+		assert.Len(t, deps, 2)
+	})
+}
+
 func edgeLess(left models.Edge, right models.Edge) bool {
-	if left.From < right.From {
-		return false
+	if left.From != right.From {
+		return left.From.String() < right.From.String()
 	}
-	return left.To < right.To
+	return left.To.String() < right.To.String()
 }
 
 func sortEdges(edges []models.Edge) {
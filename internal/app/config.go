@@ -1,18 +1,107 @@
 package app
 
-import "fmt"
+import (
+	"depviz/internal/dependency_provider/cargo"
+	"depviz/internal/dependency_provider/composer"
+	"depviz/internal/dependency_provider/gem"
+	"depviz/internal/dependency_provider/npm"
+	"depviz/internal/dependency_provider/pip"
+	"fmt"
+)
+
+const (
+	Npm      = "npm"
+	Pip      = "pip"
+	Cargo    = "cargo"
+	Go       = "go"
+	Maven    = "maven"
+	Gem      = "gem"
+	NuGet    = "nuget"
+	Composer = "composer"
+)
 
+// PackageManagers lists all supported package managers.
+var PackageManagers = []string{Npm, Pip, Cargo, Go, Maven, Gem, NuGet, Composer}
+
+// Output formats.
 const (
-	Npm = "npm"
-	Pip = "pip"
+	FormatDot       = "dot"
+	FormatJSON      = "json"
+	FormatMermaid   = "mermaid"
+	FormatPlantUML  = "plantuml"
+	FormatGraphML   = "graphml"
+	FormatGEXF      = "gexf"
+	FormatGML       = "gml"
+	FormatHTML      = "html"
+	FormatTree      = "tree"
+	FormatCycloneDX = "cyclonedx"
+	FormatSPDX      = "spdx"
 )
 
+// Formats lists all supported output formats.
+var Formats = []string{FormatDot, FormatTree, FormatJSON, FormatMermaid, FormatPlantUML,
+	FormatGraphML, FormatGEXF, FormatGML, FormatHTML, FormatCycloneDX, FormatSPDX}
+
+// dependencyKinds lists kinds accepted by package managers which support them.
+var dependencyKinds = map[string][]string{
+	Npm:      {npm.KindProd, npm.KindDev, npm.KindOptional, npm.KindPeer},
+	Cargo:    {cargo.KindNormal, cargo.KindBuild, cargo.KindDev},
+	Gem:      {gem.KindRuntime, gem.KindDevelopment},
+	Composer: {composer.KindRequire, composer.KindPlatform},
+}
+
 type Config struct {
 	PackageName    string
 	PackageManager string
+	// Kinds lists dependency kinds to traverse, if the package manager supports them.
+	Kinds []string
+	// TargetFramework selects dependency groups of NuGet packages, e.g. net8.0.
+	TargetFramework string
+	// ShowPlatform keeps php, ext-* and lib-* requirements of composer packages,
+	// it adds the platform kind to the kinds.
+	ShowPlatform bool
+	// PythonEnvironment describes the target environment of pip packages,
+	// e.g. "python_version=3.11,sys_platform=linux".
+	PythonEnvironment string
+	// RegistryURL overrides the default registry address of the package manager.
+	RegistryURL string
+	// Lockfile is a path of a lockfile the graph is read from instead of the registry,
+	// a project directory containing one, or "-" for "go mod graph" output on stdin.
+	// PackageName is optional then and selects a subtree of the graph.
+	Lockfile string
+	// Manifest is a path of requirements.txt or pyproject.toml whose requirements
+	// are the roots of the graph, they are resolved through the registry.
+	Manifest string
+	// SBOM is a path of a CycloneDX or SPDX document the graph is read from.
+	// PackageName is optional then and selects a subtree of the graph.
+	SBOM string
+	// Format is the output format, dot if it is empty.
+	Format string
+	// ASCII draws the tree format with ASCII instead of box drawing characters.
+	ASCII bool
 }
 
 func (c *Config) Validate() error {
+	if c.Format != "" && !contains(Formats, c.Format) {
+		return fmt.Errorf("output format is invalid")
+	}
+
+	if c.ASCII && c.Format != FormatTree {
+		return fmt.Errorf("ascii is supported only by the %s format", FormatTree)
+	}
+
+	if c.Lockfile != "" {
+		return c.validateLockfile()
+	}
+
+	if c.SBOM != "" {
+		return c.validateSBOM()
+	}
+
+	if c.Manifest != "" {
+		return c.validateManifest()
+	}
+
 	if c.PackageName == "" {
 		return fmt.Errorf("package name is required")
 	}
@@ -21,8 +110,108 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("package manager is required")
 	}
 
-	if c.PackageManager != Npm && c.PackageManager != Pip {
+	if !contains(PackageManagers, c.PackageManager) {
 		return fmt.Errorf("package manager is invalid")
 	}
+
+	if c.TargetFramework != "" && c.PackageManager != NuGet {
+		return fmt.Errorf("target framework is supported only by %s", NuGet)
+	}
+
+	if c.PythonEnvironment != "" {
+		if c.PackageManager != Pip {
+			return fmt.Errorf("python environment is supported only by %s", Pip)
+		}
+		if _, err := pip.ParseEnvironment(c.PythonEnvironment); err != nil {
+			return err
+		}
+	}
+
+	if len(c.Kinds) != 0 {
+		kinds, ok := dependencyKinds[c.PackageManager]
+		if !ok {
+			return fmt.Errorf("package manager %s does not support dependency kinds", c.PackageManager)
+		}
+		for _, kind := range c.Kinds {
+			if !contains(kinds, kind) {
+				return fmt.Errorf("dependency kind %q is invalid", kind)
+			}
+		}
+	}
 	return nil
 }
+
+func (c *Config) validateLockfile() error {
+	if c.Manifest != "" {
+		return fmt.Errorf("lockfile and manifest can't be used together")
+	}
+
+	if c.SBOM != "" {
+		return fmt.Errorf("lockfile and sbom can't be used together")
+	}
+
+	if c.PackageManager != "" && !contains(PackageManagers, c.PackageManager) {
+		return fmt.Errorf("package manager is invalid")
+	}
+
+	if c.RegistryURL != "" {
+		return fmt.Errorf("registry can't be used with a lockfile")
+	}
+
+	// the package manager is known only after the lockfile is parsed
+	for _, kind := range c.Kinds {
+		valid := false
+		for _, kinds := range dependencyKinds {
+			valid = valid || contains(kinds, kind)
+		}
+		if !valid {
+			return fmt.Errorf("dependency kind %q is invalid", kind)
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateSBOM() error {
+	if c.Manifest != "" {
+		return fmt.Errorf("sbom and manifest can't be used together")
+	}
+
+	if c.PackageManager != "" && !contains(PackageManagers, c.PackageManager) {
+		return fmt.Errorf("package manager is invalid")
+	}
+
+	if c.RegistryURL != "" {
+		return fmt.Errorf("registry can't be used with an sbom")
+	}
+
+	if len(c.Kinds) != 0 {
+		return fmt.Errorf("dependency kinds can't be used with an sbom")
+	}
+	return nil
+}
+
+func (c *Config) validateManifest() error {
+	if c.PackageManager != "" {
+		return fmt.Errorf("package manager can't be used with a manifest")
+	}
+
+	if len(c.Kinds) != 0 {
+		return fmt.Errorf("package manager %s does not support dependency kinds", Pip)
+	}
+
+	if c.PythonEnvironment != "" {
+		if _, err := pip.ParseEnvironment(c.PythonEnvironment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
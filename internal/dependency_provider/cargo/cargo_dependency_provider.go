@@ -0,0 +1,286 @@
+package cargo
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Dependency kinds as reported by crates.io.
+const (
+	KindNormal = "normal"
+	KindBuild  = "build"
+	KindDev    = "dev"
+)
+
+// DefaultKinds are the dependency kinds that end up compiled into a crate.
+var DefaultKinds = []string{KindNormal, KindBuild}
+
+type DependencyProvider struct {
+	BaseURL string
+	Client  *http.Client
+	// Kinds lists dependency kinds that are traversed. DefaultKinds is used if it is empty.
+	Kinds []string
+
+	mu     sync.Mutex
+	crates map[string]*crate
+}
+
+// Dependency is a single entry of a crate version's dependency list.
+type Dependency struct {
+	Name     string
+	Req      string
+	Kind     string
+	Optional bool
+}
+
+func Default() *DependencyProvider {
+	return &DependencyProvider{
+		BaseURL: "https://crates.io/api/v1",
+		Client:  &http.Client{},
+		Kinds:   DefaultKinds,
+	}
+}
+
+func (d *DependencyProvider) fetch(ctx context.Context, packageName string, elem ...string) (io.ReadCloser, error) {
+	uri, err := url.JoinPath(d.BaseURL, append([]string{"crates", url.PathEscape(packageName)}, elem...)...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	// crates.io rejects requests without a user agent
+	req.Header.Set("User-Agent", "depviz (https://github.com/burenotti/depviz)")
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	} else if resp.StatusCode == http.StatusNotFound {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrPackageNotFound, packageName)
+	} else if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%w: unexpected status %d for %s", dep_errors.ErrFetch, resp.StatusCode, packageName)
+	}
+	return resp.Body, nil
+}
+
+// crate holds the data of /crates/{name} needed for resolution.
+type crate struct {
+	// Version is the newest stable version, or the newest one if there are no stable versions
+	Version string
+	// Versions lists versions that are not yanked
+	Versions []string
+}
+
+func parseCrate(reader io.Reader) (*crate, error) {
+	var schema struct {
+		Crate *struct {
+			MaxStableVersion string `json:"max_stable_version"`
+			MaxVersion       string `json:"max_version"`
+		} `json:"crate"`
+		Versions []struct {
+			Num    string `json:"num"`
+			Yanked bool   `json:"yanked"`
+		} `json:"versions"`
+	}
+	if err := json.NewDecoder(reader).Decode(&schema); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	if schema.Crate == nil {
+		return nil, fmt.Errorf("%w: invalid json schema", dep_errors.ErrFetch)
+	}
+
+	result := &crate{Version: schema.Crate.MaxStableVersion}
+	if result.Version == "" {
+		result.Version = schema.Crate.MaxVersion
+	}
+	if result.Version == "" {
+		return nil, fmt.Errorf("%w: crate has no versions", dep_errors.ErrFetch)
+	}
+	for _, v := range schema.Versions {
+		if !v.Yanked {
+			result.Versions = append(result.Versions, v.Num)
+		}
+	}
+	return result, nil
+}
+
+func (d *DependencyProvider) crate(ctx context.Context, name string) (*crate, error) {
+	d.mu.Lock()
+	cached, ok := d.crates[name]
+	d.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	body, err := d.fetch(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+	result, err := parseCrate(body)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	if d.crates == nil {
+		d.crates = make(map[string]*crate)
+	}
+	d.crates[name] = result
+	d.mu.Unlock()
+	return result, nil
+}
+
+// resolve returns the highest version of the crate matching the requirement,
+// or an empty string if there is no such version.
+func (d *DependencyProvider) resolve(ctx context.Context, name string, req string) (string, error) {
+	c, err := d.crate(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	r, ok := parseRequirement(req)
+	if !ok {
+		return "", nil
+	}
+	return highestMatching(r, c.Versions), nil
+}
+
+func parsePackageDeps(reader io.Reader) ([]Dependency, error) {
+	var schema struct {
+		Dependencies *[]struct {
+			CrateID  string `json:"crate_id"`
+			Req      string `json:"req"`
+			Kind     string `json:"kind"`
+			Optional bool   `json:"optional"`
+		} `json:"dependencies"`
+	}
+	if err := json.NewDecoder(reader).Decode(&schema); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	if schema.Dependencies == nil {
+		return nil, fmt.Errorf("%w: invalid json schema", dep_errors.ErrFetch)
+	}
+
+	result := make([]Dependency, 0, len(*schema.Dependencies))
+	for _, dep := range *schema.Dependencies {
+		kind := dep.Kind
+		if kind == "" {
+			kind = KindNormal
+		}
+		result = append(result, Dependency{
+			Name:     dep.CrateID,
+			Req:      dep.Req,
+			Kind:     kind,
+			Optional: dep.Optional,
+		})
+	}
+	return result, nil
+}
+
+// FetchDependencies returns dependencies of the crate version with their kinds preserved.
+func (d *DependencyProvider) FetchDependencies(ctx context.Context, name string, version string) ([]Dependency, error) {
+	body, err := d.fetch(ctx, name, url.PathEscape(version), "dependencies")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+	return parsePackageDeps(body)
+}
+
+// Resolve accepts "name" or "name@requirement". The newest stable version
+// is used if there is no requirement. A bare version pins the package like
+// in the other providers, it isn't a caret requirement as in Cargo.toml.
+func (d *DependencyProvider) Resolve(ctx context.Context, spec string) (models.Package, error) {
+	name, req := spec, ""
+	if i := strings.Index(spec, "@"); i >= 0 {
+		name, req = spec[:i], strings.TrimSpace(spec[i+1:])
+	}
+	if req != "" && '0' <= req[0] && req[0] <= '9' {
+		req = "=" + req
+	}
+	c, err := d.crate(ctx, name)
+	if err != nil {
+		return models.Package{}, err
+	}
+	if req == "" {
+		return models.Package{Ecosystem: models.Cargo, Name: name, Version: c.Version}, nil
+	}
+	version, err := d.resolve(ctx, name, req)
+	if err != nil {
+		return models.Package{}, err
+	} else if version == "" {
+		return models.Package{}, fmt.Errorf("%w: no version of %s matches %q", dep_errors.ErrPackageNotFound, name, req)
+	}
+	return models.Package{Ecosystem: models.Cargo, Name: name, Version: version}, nil
+}
+
+// FetchPackageDeps returns dependencies of the traversed kinds resolved to the
+// highest matching versions. The newest stable version is used if the package has no version.
+func (d *DependencyProvider) FetchPackageDeps(ctx context.Context, pkg models.Package) ([]models.Dependency, error) {
+	version := pkg.Version
+	if version == "" {
+		c, err := d.crate(ctx, pkg.Name)
+		if err != nil {
+			return nil, err
+		}
+		version = c.Version
+	}
+	deps, err := d.FetchDependencies(ctx, pkg.Name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := d.Kinds
+	if len(kinds) == 0 {
+		kinds = DefaultKinds
+	}
+
+	seen := make(map[string]struct{}, len(deps))
+	result := make([]models.Dependency, 0, len(deps))
+	for _, dep := range deps {
+		if !contains(kinds, dep.Kind) {
+			continue
+		}
+		if _, ok := seen[dep.Name]; ok {
+			continue
+		}
+		seen[dep.Name] = struct{}{}
+
+		resolved, err := d.resolve(ctx, dep.Name, dep.Req)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, models.Dependency{
+			Package:    models.Package{Ecosystem: models.Cargo, Name: dep.Name, Version: resolved},
+			Constraint: dep.Req,
+			Kind:       dep.Kind,
+			Optional:   dep.Optional,
+		})
+	}
+	return result, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
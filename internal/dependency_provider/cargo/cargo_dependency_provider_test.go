@@ -0,0 +1,240 @@
+package cargo
+
+import (
+	"bytes"
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_parsePackageDeps(t *testing.T) {
+	t.Run("test parsing valid json", func(t *testing.T) {
+		data := []byte(`{
+			"dependencies": [
+				{"crate_id": "serde", "req": "^1.0", "kind": "normal", "optional": false},
+				{"crate_id": "cc", "req": "^1", "kind": "build", "optional": false},
+				{"crate_id": "criterion", "req": "^0.5", "kind": "dev", "optional": false},
+				{"crate_id": "log", "req": "^0.4", "kind": "normal", "optional": true}
+			]
+		}`)
+		deps, err := parsePackageDeps(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, []Dependency{
+			{Name: "serde", Req: "^1.0", Kind: KindNormal},
+			{Name: "cc", Req: "^1", Kind: KindBuild},
+			{Name: "criterion", Req: "^0.5", Kind: KindDev},
+			{Name: "log", Req: "^0.4", Kind: KindNormal, Optional: true},
+		}, deps)
+	})
+
+	t.Run("test correct error if json is invalid", func(t *testing.T) {
+		_, err := parsePackageDeps(bytes.NewReader([]byte(`{`)))
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+	})
+
+	t.Run("test correct error if json schema is invalid", func(t *testing.T) {
+		_, err := parsePackageDeps(bytes.NewReader([]byte(`{}`)))
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+	})
+}
+
+func Test_parseCrate(t *testing.T) {
+	t.Run("test prefers stable version", func(t *testing.T) {
+		data := []byte(`{
+			"crate": {"max_version": "2.0.0-rc.1", "max_stable_version": "1.9.0"},
+			"versions": [
+				{"num": "2.0.0-rc.1", "yanked": false},
+				{"num": "1.9.0", "yanked": false},
+				{"num": "1.8.0", "yanked": true}
+			]
+		}`)
+		c, err := parseCrate(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, "1.9.0", c.Version)
+		assert.Equal(t, []string{"2.0.0-rc.1", "1.9.0"}, c.Versions)
+	})
+
+	t.Run("test falls back to max version", func(t *testing.T) {
+		data := []byte(`{"crate": {"max_version": "0.1.0-alpha", "max_stable_version": null}}`)
+		c, err := parseCrate(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, "0.1.0-alpha", c.Version)
+	})
+
+	t.Run("test correct error if json schema is invalid", func(t *testing.T) {
+		_, err := parseCrate(bytes.NewReader([]byte(`{}`)))
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+	})
+}
+
+func TestDependencyProvider_FetchPackageDeps(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/crates/tokio",
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("User-Agent") == "" {
+				w.WriteHeader(403)
+				return
+			}
+			_, _ = w.Write([]byte(`{"crate": {"max_stable_version": "1.0.0"}, "versions": [{"num": "1.0.0"}, {"num": "0.9.0"}]}`))
+		})
+	mux.HandleFunc("/crates/bytes",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"crate": {"max_stable_version": "1.5.0"}, "versions": [{"num": "1.5.0"}, {"num": "1.4.0"}]}`))
+		})
+	mux.HandleFunc("/crates/autocfg",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"crate": {"max_stable_version": "1.1.0"}, "versions": [{"num": "1.1.0"}]}`))
+		})
+	mux.HandleFunc("/crates/loom",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"crate": {"max_stable_version": "0.7.0"}, "versions": [{"num": "0.7.0"}, {"num": "0.5.6"}]}`))
+		})
+	mux.HandleFunc("/crates/tokio/1.0.0/dependencies",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"dependencies": [
+				{"crate_id": "bytes", "req": "^1", "kind": "normal"},
+				{"crate_id": "autocfg", "req": "^1", "kind": "build"},
+				{"crate_id": "bytes", "req": "^1", "kind": "dev"},
+				{"crate_id": "loom", "req": "^0.5", "kind": "dev"}
+			]}`))
+		})
+	mux.HandleFunc("/crates/broken",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{`))
+		})
+	mux.HandleFunc("/crates/not-found",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(404)
+		})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	t.Run("test fetching with default kinds", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{
+			BaseURL: srv.URL,
+			Client:  &http.Client{},
+		}
+		pkg, err := d.Resolve(ctx, "tokio")
+		assert.NoError(t, err)
+		assert.Equal(t, models.Package{Ecosystem: models.Cargo, Name: "tokio", Version: "1.0.0"}, pkg)
+
+		deps, err := d.FetchPackageDeps(ctx, pkg)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{
+				Package:    models.Package{Ecosystem: models.Cargo, Name: "bytes", Version: "1.5.0"},
+				Constraint: "^1",
+				Kind:       KindNormal,
+			},
+			{
+				Package:    models.Package{Ecosystem: models.Cargo, Name: "autocfg", Version: "1.1.0"},
+				Constraint: "^1",
+				Kind:       KindBuild,
+			},
+		}, deps)
+	})
+
+	t.Run("test fetching only dev dependencies", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{
+			BaseURL: srv.URL,
+			Client:  &http.Client{},
+			Kinds:   []string{KindDev},
+		}
+		deps, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Cargo, Name: "tokio"})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{
+				Package:    models.Package{Ecosystem: models.Cargo, Name: "bytes", Version: "1.5.0"},
+				Constraint: "^1",
+				Kind:       KindDev,
+			},
+			{
+				Package:    models.Package{Ecosystem: models.Cargo, Name: "loom", Version: "0.5.6"},
+				Constraint: "^0.5",
+				Kind:       KindDev,
+			},
+		}, deps)
+	})
+
+	t.Run("test resolving version requirement", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{
+			BaseURL: srv.URL,
+			Client:  &http.Client{},
+		}
+		pkg, err := d.Resolve(ctx, "tokio@0.9")
+		assert.NoError(t, err)
+		assert.Equal(t, "tokio@0.9.0", pkg.String())
+
+		_, err = d.Resolve(ctx, "tokio@2")
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+
+		// a bare version is exact, operators keep their meaning
+		pkg, err = d.Resolve(ctx, "bytes@1.4.0")
+		assert.NoError(t, err)
+		assert.Equal(t, "bytes@1.4.0", pkg.String())
+
+		pkg, err = d.Resolve(ctx, "bytes@^1.4.0")
+		assert.NoError(t, err)
+		assert.Equal(t, "bytes@1.5.0", pkg.String())
+	})
+
+	t.Run("test fetching if package not found", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{
+			BaseURL: srv.URL,
+			Client:  &http.Client{},
+		}
+		deps, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Cargo, Name: "not-found"})
+		assert.Empty(t, deps)
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+	})
+
+	t.Run("test fetching if server returns invalid json", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{
+			BaseURL: srv.URL,
+			Client:  &http.Client{},
+		}
+		deps, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Cargo, Name: "broken"})
+		assert.Empty(t, deps)
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+	})
+
+	t.Run("test if fetching url is invalid", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{
+			BaseURL: ":::",
+			Client:  &http.Client{},
+		}
+		_, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Cargo, Name: "tokio"})
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+	})
+}
+
+func TestDefault(t *testing.T) {
+	d := Default()
+	assert.NotNil(t, d)
+	assert.NotNil(t, d.Client)
+	assert.Equal(t, "https://crates.io/api/v1", d.BaseURL)
+	assert.Equal(t, DefaultKinds, d.Kinds)
+}
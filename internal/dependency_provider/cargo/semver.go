@@ -0,0 +1,256 @@
+package cargo
+
+import (
+	"strconv"
+	"strings"
+)
+
+type version struct {
+	major, minor, patch uint64
+	pre                 []string
+}
+
+func parseVersion(s string) (version, bool) {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	var v version
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		v.pre = strings.Split(s[i+1:], ".")
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return version{}, false
+	}
+	numbers := make([]uint64, 3)
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return version{}, false
+		}
+		numbers[i] = n
+	}
+	v.major, v.minor, v.patch = numbers[0], numbers[1], numbers[2]
+	return v, true
+}
+
+func compareUint(left, right uint64) int {
+	if left < right {
+		return -1
+	} else if left > right {
+		return 1
+	}
+	return 0
+}
+
+// compareVersions implements semver precedence.
+func compareVersions(left, right version) int {
+	if c := compareUint(left.major, right.major); c != 0 {
+		return c
+	}
+	if c := compareUint(left.minor, right.minor); c != 0 {
+		return c
+	}
+	if c := compareUint(left.patch, right.patch); c != 0 {
+		return c
+	}
+	if len(left.pre) == 0 || len(right.pre) == 0 {
+		// a pre-release precedes the release
+		return compareUint(uint64(len(right.pre)), uint64(len(left.pre)))
+	}
+	for i := 0; i < len(left.pre) && i < len(right.pre); i++ {
+		l, lErr := strconv.ParseUint(left.pre[i], 10, 64)
+		r, rErr := strconv.ParseUint(right.pre[i], 10, 64)
+		var c int
+		switch {
+		case lErr == nil && rErr == nil:
+			c = compareUint(l, r)
+		case lErr == nil:
+			c = -1
+		case rErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(left.pre[i], right.pre[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(left.pre)), uint64(len(right.pre)))
+}
+
+// bound is a primitive comparison such as ">=1.2.0".
+type bound struct {
+	op string
+	v  version
+}
+
+func (b bound) test(v version) bool {
+	c := compareVersions(v, b.v)
+	switch b.op {
+	case "=":
+		return c == 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	default:
+		return c <= 0
+	}
+}
+
+// requirement is a cargo version requirement, a conjunction of comparators.
+type requirement []bound
+
+// parseRequirement parses requirements such as "1.2", "^0.4.1", "~1", ">=1, <2" or "1.*".
+// A bare version means a caret requirement.
+func parseRequirement(s string) (requirement, bool) {
+	var result requirement
+	for _, part := range strings.Split(s, ",") {
+		bounds, ok := parseComparator(strings.TrimSpace(part))
+		if !ok {
+			return nil, false
+		}
+		result = append(result, bounds...)
+	}
+	return result, true
+}
+
+func parseComparator(s string) ([]bound, bool) {
+	op := "^"
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(s, prefix) {
+			op = prefix
+			s = strings.TrimSpace(s[len(prefix):])
+			break
+		}
+	}
+	if s == "*" {
+		return nil, op == "^"
+	}
+
+	var pre []string
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		pre = strings.Split(s[i+1:], ".")
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return nil, false
+	}
+	var numbers []uint64
+	for _, part := range parts {
+		if part == "*" || part == "x" || part == "X" {
+			// "1.*" means the same as "=1"
+			if op == "^" {
+				op = "="
+			}
+			break
+		}
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		numbers = append(numbers, n)
+	}
+	if len(numbers) == 0 {
+		return nil, op == "="
+	}
+
+	v := version{major: numbers[0], pre: pre}
+	if len(numbers) > 1 {
+		v.minor = numbers[1]
+	}
+	if len(numbers) > 2 {
+		v.patch = numbers[2]
+	}
+	next := func(n int) version {
+		// the smallest version greater than all versions matching first n numbers
+		switch n {
+		case 1:
+			return version{major: v.major + 1}
+		case 2:
+			return version{major: v.major, minor: v.minor + 1}
+		}
+		return version{major: v.major, minor: v.minor, patch: v.patch + 1}
+	}
+	n := len(numbers)
+
+	switch op {
+	case "=":
+		if n == 3 {
+			return []bound{{"=", v}}, true
+		}
+		return []bound{{">=", v}, {"<", next(n)}}, true
+	case ">":
+		if n == 3 {
+			return []bound{{">", v}}, true
+		}
+		return []bound{{">=", next(n)}}, true
+	case ">=":
+		return []bound{{">=", v}}, true
+	case "<":
+		return []bound{{"<", v}}, true
+	case "<=":
+		if n == 3 {
+			return []bound{{"<=", v}}, true
+		}
+		return []bound{{"<", next(n)}}, true
+	case "~":
+		if n == 1 {
+			return []bound{{">=", v}, {"<", next(1)}}, true
+		}
+		return []bound{{">=", v}, {"<", next(2)}}, true
+	}
+
+	// caret: changes to the left-most non-zero number are incompatible
+	switch {
+	case v.major > 0 || n == 1:
+		return []bound{{">=", v}, {"<", next(1)}}, true
+	case v.minor > 0 || n == 2:
+		return []bound{{">=", v}, {"<", next(2)}}, true
+	}
+	return []bound{{">=", v}, {"<", next(3)}}, true
+}
+
+// matches reports whether the version satisfies the requirement. Pre-releases
+// only match comparators of the same version with a pre-release.
+func (r requirement) matches(v version) bool {
+	for _, b := range r {
+		if !b.test(v) {
+			return false
+		}
+	}
+	if len(v.pre) == 0 {
+		return true
+	}
+	for _, b := range r {
+		if len(b.v.pre) != 0 && b.v.major == v.major && b.v.minor == v.minor && b.v.patch == v.patch {
+			return true
+		}
+	}
+	return false
+}
+
+// highestMatching returns the highest of versions satisfying the requirement.
+func highestMatching(req requirement, versions []string) string {
+	var best string
+	var bestVersion version
+	for _, s := range versions {
+		v, ok := parseVersion(s)
+		if !ok || !req.matches(v) {
+			continue
+		}
+		if best == "" || compareVersions(v, bestVersion) > 0 {
+			best, bestVersion = s, v
+		}
+	}
+	return best
+}
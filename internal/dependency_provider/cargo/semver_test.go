@@ -0,0 +1,51 @@
+package cargo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_compareVersions(t *testing.T) {
+	ordered := []string{"0.9.0", "1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0", "1.0.1", "1.10.0"}
+	for i := 0; i < len(ordered)-1; i++ {
+		left, ok := parseVersion(ordered[i])
+		assert.True(t, ok)
+		right, ok := parseVersion(ordered[i+1])
+		assert.True(t, ok)
+		assert.Equal(t, -1, compareVersions(left, right), ordered[i])
+		assert.Equal(t, 1, compareVersions(right, left), ordered[i])
+	}
+}
+
+func Test_highestMatching(t *testing.T) {
+	versions := []string{"0.0.3", "0.0.4", "0.1.0", "0.1.7", "0.2.0", "1.0.0", "1.2.3", "1.3.0-rc.1", "1.3.0", "2.0.0-alpha.1"}
+	cases := map[string]string{
+		"1":                 "1.3.0",
+		"1.2":               "1.3.0",
+		"^0.1":              "0.1.7",
+		"0.0.3":             "0.0.3",
+		"^0.0":              "0.0.4",
+		"~1.2":              "1.2.3",
+		"~1":                "1.3.0",
+		"=1.2.3":            "1.2.3",
+		"=0.1":              "0.1.7",
+		">=0.1, <1":         "0.2.0",
+		">1.2":              "1.3.0",
+		"<=1.2":             "1.2.3",
+		"1.*":               "1.3.0",
+		"*":                 "1.3.0",
+		">=1.3.0-rc.0":      "1.3.0",
+		"=1.3.0-rc.1":       "1.3.0-rc.1",
+		"^2.0.0-alpha.0":    "2.0.0-alpha.1",
+		">=3":               "",
+		"not a requirement": "",
+	}
+	for req, expected := range cases {
+		r, ok := parseRequirement(req)
+		if expected == "" && !ok {
+			continue
+		}
+		assert.True(t, ok, req)
+		assert.Equal(t, expected, highestMatching(r, versions), req)
+	}
+}
@@ -0,0 +1,332 @@
+package composer
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// unset marks keys removed from a version in the minified metadata format.
+const unset = "__unset"
+
+// Dependency kinds: requirements of packages from repositories and platform
+// requirements (php, ext-*, lib-*), which are leaves without a version.
+const (
+	KindRequire  = "require"
+	KindPlatform = "platform"
+)
+
+// DefaultKinds leaves platform requirements out.
+var DefaultKinds = []string{KindRequire}
+
+type DependencyProvider struct {
+	BaseURL string
+	Client  *http.Client
+	// Kinds lists dependency kinds that are traversed. DefaultKinds is used if it is empty.
+	Kinds []string
+
+	mu    sync.Mutex
+	cache map[string][]packageVersion
+}
+
+func Default() *DependencyProvider {
+	return &DependencyProvider{
+		BaseURL: "https://repo.packagist.org",
+		Client:  &http.Client{},
+		Kinds:   DefaultKinds,
+	}
+}
+
+// IsPlatformPackage reports whether the name refers to the PHP runtime, an
+// extension, a system library or composer itself rather than a real package.
+func IsPlatformPackage(name string) bool {
+	// packages from repositories are always named vendor/package
+	return !strings.Contains(name, "/")
+}
+
+// kind returns the dependency kind of a required package.
+func kind(name string) string {
+	if IsPlatformPackage(name) {
+		return KindPlatform
+	}
+	return KindRequire
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *DependencyProvider) fetch(ctx context.Context, packageName string) (io.ReadCloser, error) {
+	uri, err := url.JoinPath(d.BaseURL, "p2", packageName+".json")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	} else if resp.StatusCode == http.StatusNotFound {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrPackageNotFound, packageName)
+	} else if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%w: unexpected status %d for %s", dep_errors.ErrFetch, resp.StatusCode, packageName)
+	}
+	return resp.Body, nil
+}
+
+// expandVersions restores full version entries from the minified format, where
+// every entry only holds keys that differ from the previous one.
+func expandVersions(versions []map[string]json.RawMessage) []map[string]json.RawMessage {
+	result := make([]map[string]json.RawMessage, 0, len(versions))
+	var previous map[string]json.RawMessage
+	for _, v := range versions {
+		expanded := make(map[string]json.RawMessage, len(previous)+len(v))
+		for key, value := range previous {
+			expanded[key] = value
+		}
+		for key, value := range v {
+			var s string
+			if err := json.Unmarshal(value, &s); err == nil && s == unset {
+				delete(expanded, key)
+				continue
+			}
+			expanded[key] = value
+		}
+		result = append(result, expanded)
+		previous = expanded
+	}
+	return result
+}
+
+type packageVersion struct {
+	Version           string            `json:"version"`
+	VersionNormalized string            `json:"version_normalized"`
+	Require           map[string]string `json:"require"`
+}
+
+func parsePackageVersions(reader io.Reader, packageName string) ([]packageVersion, error) {
+	var schema struct {
+		Packages map[string][]map[string]json.RawMessage `json:"packages"`
+		Minified string                                  `json:"minified"`
+	}
+	if err := json.NewDecoder(reader).Decode(&schema); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	raw, ok := schema.Packages[packageName]
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid json schema", dep_errors.ErrFetch)
+	}
+	if schema.Minified != "" {
+		raw = expandVersions(raw)
+	}
+
+	result := make([]packageVersion, 0, len(raw))
+	for _, entry := range raw {
+		// "require" is an empty array instead of an object in some entries
+		if string(entry["require"]) == "[]" {
+			delete(entry, "require")
+		}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+		}
+		var v packageVersion
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+// isStable reports whether the version has no dev, alpha, beta or RC suffix.
+func isStable(v *packageVersion) bool {
+	version := strings.ToLower(v.VersionNormalized)
+	if version == "" {
+		version = strings.ToLower(v.Version)
+	}
+	return !strings.Contains(version, "dev") && !strings.Contains(version, "-")
+}
+
+// compareNormalized compares versions normalized by composer, such as "3.5.0.0".
+func compareNormalized(left, right string) int {
+	a, b := strings.Split(left, "."), strings.Split(right, ".")
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var l, r int
+		if i < len(a) {
+			l, _ = strconv.Atoi(a[i])
+		}
+		if i < len(b) {
+			r, _ = strconv.Atoi(b[i])
+		}
+		if l != r {
+			if l < r {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// latestStable returns the highest stable version, or the first listed version
+// (packagist lists the newest first) if there are no stable versions.
+func latestStable(versions []packageVersion) *packageVersion {
+	var best *packageVersion
+	for i := range versions {
+		v := &versions[i]
+		if !isStable(v) {
+			continue
+		}
+		if best == nil || compareNormalized(v.VersionNormalized, best.VersionNormalized) > 0 {
+			best = v
+		}
+	}
+	if best == nil && len(versions) != 0 {
+		best = &versions[0]
+	}
+	return best
+}
+
+func (d *DependencyProvider) versions(ctx context.Context, packageName string) ([]packageVersion, error) {
+	d.mu.Lock()
+	cached, ok := d.cache[packageName]
+	d.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	body, err := d.fetch(ctx, packageName)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+	versions, err := parsePackageVersions(body, packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	if d.cache == nil {
+		d.cache = make(map[string][]packageVersion)
+	}
+	d.cache[packageName] = versions
+	d.mu.Unlock()
+	return versions, nil
+}
+
+// find returns the version of a package, or its latest stable version if version is empty.
+func (d *DependencyProvider) find(ctx context.Context, packageName string, version string) (*packageVersion, error) {
+	versions, err := d.versions(ctx, packageName)
+	if err != nil {
+		return nil, err
+	}
+	if version == "" {
+		if latest := latestStable(versions); latest != nil {
+			return latest, nil
+		}
+		return nil, fmt.Errorf("%w: %s has no versions", dep_errors.ErrPackageNotFound, packageName)
+	}
+	for i := range versions {
+		if versions[i].Version == version || versions[i].VersionNormalized == version {
+			return &versions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s %s", dep_errors.ErrPackageNotFound, packageName, version)
+}
+
+// resolve returns the highest version of the package matching the constraint,
+// or an empty string if there is no such version.
+func (d *DependencyProvider) resolve(ctx context.Context, packageName string, constraint string) (string, error) {
+	c, ok := parseConstraint(constraint)
+	if !ok {
+		return "", nil
+	}
+	versions, err := d.versions(ctx, packageName)
+	if err != nil {
+		return "", err
+	}
+	if v := highestMatching(c, versions); v != nil {
+		return v.Version, nil
+	}
+	return "", nil
+}
+
+// Resolve accepts "vendor/package" or "vendor/package@version". Package names
+// are case-insensitive, versions such as "1.0.0-RC1" or "dev-Main" are not.
+func (d *DependencyProvider) Resolve(ctx context.Context, spec string) (models.Package, error) {
+	name, version, _ := strings.Cut(spec, "@")
+	name = strings.ToLower(name)
+	if IsPlatformPackage(name) {
+		return models.Package{Ecosystem: models.Composer, Name: name, Version: version}, nil
+	}
+	v, err := d.find(ctx, name, version)
+	if err != nil {
+		return models.Package{}, err
+	}
+	return models.Package{Ecosystem: models.Composer, Name: name, Version: v.Version}, nil
+}
+
+// FetchPackageDeps returns requirements of the selected kinds sorted by name and
+// resolved to the highest matching stable versions. Platform requirements are
+// leaves without a version. The latest stable version is used if the package
+// has no version.
+func (d *DependencyProvider) FetchPackageDeps(ctx context.Context, pkg models.Package) ([]models.Dependency, error) {
+	if IsPlatformPackage(pkg.Name) {
+		return nil, nil
+	}
+	v, err := d.find(ctx, strings.ToLower(pkg.Name), pkg.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := d.Kinds
+	if len(kinds) == 0 {
+		kinds = DefaultKinds
+	}
+	names := make([]string, 0, len(v.Require))
+	for name := range v.Require {
+		if contains(kinds, kind(name)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	result := make([]models.Dependency, 0, len(names))
+	for _, name := range names {
+		dep := models.Dependency{
+			Package:    models.Package{Ecosystem: models.Composer, Name: strings.ToLower(name)},
+			Constraint: v.Require[name],
+			Kind:       kind(name),
+		}
+		if dep.Kind == KindRequire {
+			if dep.Package.Version, err = d.resolve(ctx, dep.Package.Name, dep.Constraint); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, dep)
+	}
+	return result, nil
+}
@@ -0,0 +1,206 @@
+package composer
+
+import (
+	"bytes"
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const minifiedMonolog = `{
+	"packages": {
+		"monolog/monolog": [
+			{
+				"name": "monolog/monolog",
+				"version": "3.0.0-RC1",
+				"version_normalized": "3.0.0.0-RC1",
+				"require": {"php": ">=8.1", "psr/log": "^2.0 || ^3.0"}
+			},
+			{
+				"version": "2.9.1",
+				"version_normalized": "2.9.1.0",
+				"require": {"php": ">=7.2", "ext-json": "*", "psr/log": "^1.0.1 || ^2.0 || ^3.0"}
+			},
+			{
+				"version": "2.10.0",
+				"version_normalized": "2.10.0.0"
+			},
+			{
+				"version": "1.0.0",
+				"version_normalized": "1.0.0.0",
+				"require": "__unset"
+			}
+		]
+	},
+	"minified": "composer/2.0"
+}`
+
+func Test_parsePackageVersions(t *testing.T) {
+	t.Run("test expanding minified metadata", func(t *testing.T) {
+		versions, err := parsePackageVersions(bytes.NewReader([]byte(minifiedMonolog)), "monolog/monolog")
+		assert.NoError(t, err)
+		assert.Len(t, versions, 4)
+		assert.Equal(t, map[string]string{"php": ">=8.1", "psr/log": "^2.0 || ^3.0"}, versions[0].Require)
+		assert.Equal(t, "2.10.0", versions[2].Version)
+		assert.Equal(t, versions[1].Require, versions[2].Require)
+		assert.Nil(t, versions[3].Require)
+		assert.Equal(t, "2.10.0", latestStable(versions).Version)
+	})
+
+	t.Run("test parsing not minified metadata", func(t *testing.T) {
+		data := []byte(`{"packages": {"a/b": [
+			{"version": "1.0.0", "version_normalized": "1.0.0.0", "require": []},
+			{"version": "0.1.0", "version_normalized": "0.1.0.0", "require": {"c/d": "^1"}}
+		]}}`)
+		versions, err := parsePackageVersions(bytes.NewReader(data), "a/b")
+		assert.NoError(t, err)
+		assert.Empty(t, versions[0].Require)
+		assert.Equal(t, map[string]string{"c/d": "^1"}, versions[1].Require)
+	})
+
+	t.Run("test correct error if json schema is invalid", func(t *testing.T) {
+		_, err := parsePackageVersions(bytes.NewReader([]byte(`{"packages": {}}`)), "a/b")
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+
+		_, err = parsePackageVersions(bytes.NewReader([]byte(`{`)), "a/b")
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+	})
+}
+
+func TestDependencyProvider_FetchPackageDeps(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/p2/monolog/monolog.json",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(minifiedMonolog))
+		})
+	mux.HandleFunc("/p2/psr/log.json",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"packages": {"psr/log": [
+				{"version": "dev-master", "version_normalized": "dev-master"},
+				{"version": "3.0.0", "version_normalized": "3.0.0.0"},
+				{"version": "1.1.4", "version_normalized": "1.1.4.0"}
+			]}}`))
+		})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	t.Run("test platform requirements are filtered", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}}
+		pkg, err := d.Resolve(ctx, "Monolog/Monolog")
+		assert.NoError(t, err)
+		assert.Equal(t, models.Package{Ecosystem: models.Composer, Name: "monolog/monolog", Version: "2.10.0"}, pkg)
+
+		deps, err := d.FetchPackageDeps(ctx, pkg)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{
+				Package:    models.Package{Ecosystem: models.Composer, Name: "psr/log", Version: "3.0.0"},
+				Constraint: "^1.0.1 || ^2.0 || ^3.0",
+				Kind:       KindRequire,
+			},
+		}, deps)
+	})
+
+	t.Run("test versions keep their case", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}}
+		pkg, err := d.Resolve(ctx, "Monolog/Monolog@3.0.0-RC1")
+		assert.NoError(t, err)
+		assert.Equal(t, models.Package{Ecosystem: models.Composer, Name: "monolog/monolog", Version: "3.0.0-RC1"}, pkg)
+	})
+
+	t.Run("test platform requirements are leaves", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}, Kinds: []string{KindRequire, KindPlatform}}
+		deps, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Composer, Name: "monolog/monolog", Version: "2.9.1"})
+		assert.NoError(t, err)
+		names := make([]string, 0, len(deps))
+		kinds := make([]string, 0, len(deps))
+		for _, dep := range deps {
+			names = append(names, dep.Package.String())
+			kinds = append(kinds, dep.Kind)
+		}
+		assert.Equal(t, []string{"ext-json", "php", "psr/log@3.0.0"}, names)
+		assert.Equal(t, []string{KindPlatform, KindPlatform, KindRequire}, kinds)
+
+		deps, err = d.FetchPackageDeps(ctx, deps[0].Package)
+		assert.NoError(t, err)
+		assert.Empty(t, deps)
+	})
+
+	t.Run("test fetching if package not found", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}}
+		_, err := d.Resolve(ctx, "not/found")
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+
+		_, err = d.Resolve(ctx, "monolog/monolog@9.0.0")
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+	})
+
+	t.Run("test if fetching url is invalid", func(t *testing.T) {
+		d := DependencyProvider{BaseURL: ":::", Client: &http.Client{}}
+		_, err := d.FetchPackageDeps(context.Background(), models.Package{Ecosystem: models.Composer, Name: "monolog/monolog"})
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+	})
+}
+
+func Test_highestMatching(t *testing.T) {
+	var versions []packageVersion
+	for _, v := range []string{"0.3.1", "0.4.0", "1.0.0", "1.2.5", "1.3.0", "2.0.0-RC1", "9999999-dev"} {
+		versions = append(versions, packageVersion{Version: v, VersionNormalized: v + ".0"})
+	}
+	cases := map[string]string{
+		"^1.0":           "1.3.0",
+		"^0.3":           "0.3.1",
+		"~1.2":           "1.3.0",
+		"~1.2.0":         "1.2.5",
+		"1.2.*":          "1.2.5",
+		"1.0.0":          "1.0.0",
+		">=1.0 <1.3":     "1.2.5",
+		">= 1.0, < 1.3":  "1.2.5",
+		"^0.3 || ^1.0":   "1.3.0",
+		"0.3 - 1.2":      "1.2.5",
+		"0.3 - 1.2.0":    "1.0.0",
+		"*":              "1.3.0",
+		"^2.0@RC":        "2.0.0-RC1",
+		"!=1.3.0, >=1.2": "1.2.5",
+		"^3.0":           "",
+	}
+	for constraint, expected := range cases {
+		c, ok := parseConstraint(constraint)
+		assert.True(t, ok, constraint)
+		v := highestMatching(c, versions)
+		if expected == "" {
+			assert.Nil(t, v, constraint)
+			continue
+		}
+		if assert.NotNil(t, v, constraint) {
+			assert.Equal(t, expected, v.Version, constraint)
+		}
+	}
+
+	_, ok := parseConstraint("dev-main")
+	assert.False(t, ok)
+}
+
+func TestDefault(t *testing.T) {
+	d := Default()
+	assert.NotNil(t, d)
+	assert.NotNil(t, d.Client)
+	assert.Equal(t, "https://repo.packagist.org", d.BaseURL)
+}
@@ -0,0 +1,217 @@
+package composer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// bound is a primitive comparison of normalized versions such as ">=1.2.0.0".
+type bound struct {
+	op string
+	v  []int
+}
+
+func (b bound) test(v []int) bool {
+	c := compareParts(v, b.v)
+	switch b.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	default:
+		return c <= 0
+	}
+}
+
+// constraint is a disjunction of conjunctions of bounds, e.g. "^1.0 || >=2.1 <3".
+type constraint [][]bound
+
+func compareParts(left, right []int) int {
+	for i := 0; i < 4; i++ {
+		var l, r int
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		if l != r {
+			if l < r {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// parseParts parses the numeric part of a version, stopping at a wildcard or a suffix.
+// wildcard is true if the version ends with "*" or "x".
+func parseParts(s string) (parts []int, wildcard bool, ok bool) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	for _, part := range strings.Split(s, ".") {
+		if part == "*" || part == "x" || part == "X" {
+			return parts, true, true
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || len(parts) == 4 {
+			return nil, false, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, false, len(parts) != 0
+}
+
+// next returns the smallest version greater than all versions starting with parts[:n].
+func next(parts []int, n int) []int {
+	result := make([]int, n)
+	copy(result, parts)
+	result[n-1]++
+	return result
+}
+
+var (
+	alternatives = regexp.MustCompile(`\s*\|\|?\s*`)
+	hyphenRange  = regexp.MustCompile(`^(\S+)\s+-\s+(\S+)$`)
+	operator     = regexp.MustCompile(`([<>=!^~]+)\s+`)
+)
+
+// parseConstraint parses composer version constraints. Stability flags such as
+// "@dev" are ignored, branch constraints ("dev-main") are not supported.
+func parseConstraint(s string) (constraint, bool) {
+	var result constraint
+	s = operator.ReplaceAllString(strings.TrimSpace(s), "$1")
+	for _, alternative := range alternatives.Split(s, -1) {
+		var conjunction []bound
+		if m := hyphenRange.FindStringSubmatch(alternative); m != nil {
+			lower, _, ok := parseParts(m[1])
+			if !ok {
+				return nil, false
+			}
+			upper, _, ok := parseParts(m[2])
+			if !ok {
+				return nil, false
+			}
+			conjunction = append(conjunction, bound{">=", lower})
+			if len(upper) < 3 {
+				conjunction = append(conjunction, bound{"<", next(upper, len(upper))})
+			} else {
+				conjunction = append(conjunction, bound{"<=", upper})
+			}
+			result = append(result, conjunction)
+			continue
+		}
+		for _, part := range strings.FieldsFunc(alternative, func(r rune) bool { return r == ',' || r == ' ' }) {
+			bounds, ok := parseComparator(part)
+			if !ok {
+				return nil, false
+			}
+			conjunction = append(conjunction, bounds...)
+		}
+		result = append(result, conjunction)
+	}
+	return result, len(result) != 0
+}
+
+func parseComparator(s string) ([]bound, bool) {
+	if i := strings.IndexByte(s, '@'); i >= 0 {
+		s = s[:i]
+	}
+	if s == "" || s == "*" {
+		return nil, true
+	}
+	if strings.HasPrefix(s, "dev-") {
+		return nil, false
+	}
+	op := ""
+	for _, prefix := range []string{">=", "<=", "!=", "==", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(s, prefix) {
+			op, s = prefix, s[len(prefix):]
+			break
+		}
+	}
+	parts, wildcard, ok := parseParts(s)
+	if !ok && !(wildcard && op == "") {
+		return nil, false
+	}
+	n := len(parts)
+
+	switch op {
+	case "^":
+		for i := 0; i < n; i++ {
+			if parts[i] != 0 || i == n-1 {
+				return []bound{{">=", parts}, {"<", next(parts, i+1)}}, true
+			}
+		}
+		return nil, false
+	case "~":
+		if n == 1 {
+			return []bound{{">=", parts}, {"<", next(parts, 1)}}, true
+		}
+		return []bound{{">=", parts}, {"<", next(parts, n-1)}}, true
+	case "", "=", "==":
+		if wildcard {
+			if n == 0 {
+				return nil, true
+			}
+			return []bound{{">=", parts}, {"<", next(parts, n)}}, true
+		}
+		return []bound{{"=", parts}}, true
+	}
+	return []bound{{op, parts}}, true
+}
+
+func (c constraint) matches(v []int) bool {
+	for _, conjunction := range c {
+		matches := true
+		for _, b := range conjunction {
+			if !b.test(v) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// highestMatching returns the highest stable version matching the constraint.
+// Pre-releases are only considered if no stable version matches, and
+// development branches are never picked.
+func highestMatching(c constraint, versions []packageVersion) *packageVersion {
+	var best, bestUnstable *packageVersion
+	var bestParts, bestUnstableParts []int
+	for i := range versions {
+		v := &versions[i]
+		if strings.Contains(strings.ToLower(v.VersionNormalized), "dev") {
+			continue
+		}
+		parts, _, ok := parseParts(v.VersionNormalized)
+		if !ok || !c.matches(parts) {
+			continue
+		}
+		if isStable(v) {
+			if best == nil || compareParts(parts, bestParts) > 0 {
+				best, bestParts = v, parts
+			}
+		} else if bestUnstable == nil || compareParts(parts, bestUnstableParts) > 0 {
+			bestUnstable, bestUnstableParts = v, parts
+		}
+	}
+	if best != nil {
+		return best
+	}
+	return bestUnstable
+}
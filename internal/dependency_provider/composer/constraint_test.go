@@ -0,0 +1,53 @@
+package composer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_parseConstraint(t *testing.T) {
+	cases := []struct {
+		constraint string
+		matching   []string
+		other      []string
+	}{
+		{constraint: "1.2.3", matching: []string{"1.2.3", "1.2.3.0"}, other: []string{"1.2.4", "1.2.2"}},
+		{constraint: "^1.2", matching: []string{"1.2.0", "1.9.9"}, other: []string{"1.1.9", "2.0.0"}},
+		{constraint: "^0.3.1", matching: []string{"0.3.1", "0.3.9"}, other: []string{"0.3.0", "0.4.0"}},
+		{constraint: "^0.0.2", matching: []string{"0.0.2"}, other: []string{"0.0.3"}},
+		{constraint: "~1.2", matching: []string{"1.2.0", "1.9.0"}, other: []string{"1.1.0", "2.0.0"}},
+		{constraint: "~1.2.3", matching: []string{"1.2.3", "1.2.9"}, other: []string{"1.2.2", "1.3.0"}},
+		{constraint: "~1", matching: []string{"1.0.0", "1.9.0"}, other: []string{"2.0.0"}},
+		{constraint: "1.2.*", matching: []string{"1.2.0", "1.2.9"}, other: []string{"1.3.0", "1.1.9"}},
+		{constraint: "2.x", matching: []string{"2.0.0", "2.5.1"}, other: []string{"3.0.0", "1.9.9"}},
+		{constraint: "*", matching: []string{"0.0.1", "9.9.9"}},
+		{constraint: ">=1.0 <2.0", matching: []string{"1.0.0", "1.9.9"}, other: []string{"0.9.9", "2.0.0"}},
+		{constraint: ">= 1.0, < 1.5", matching: []string{"1.4.9"}, other: []string{"1.5.0"}},
+		{constraint: "!=1.2.0", matching: []string{"1.2.1"}, other: []string{"1.2.0"}},
+		{constraint: "1.0 - 2.0", matching: []string{"1.0.0", "2.0.9"}, other: []string{"2.1.0", "0.9.0"}},
+		{constraint: "1.0.0 - 2.1.0", matching: []string{"2.1.0"}, other: []string{"2.1.1"}},
+		{constraint: "^1.0 || ^3.0", matching: []string{"1.5.0", "3.1.0"}, other: []string{"2.0.0", "4.0.0"}},
+		{constraint: "~1.2 | >=3 <3.1", matching: []string{"1.3.0", "3.0.5"}, other: []string{"2.0.0", "3.1.0"}},
+		{constraint: "^2.0@dev", matching: []string{"2.1.0"}, other: []string{"3.0.0"}},
+		{constraint: "v1.2.3", matching: []string{"1.2.3"}, other: []string{"1.2.4"}},
+	}
+	for _, tc := range cases {
+		c, ok := parseConstraint(tc.constraint)
+		assert.True(t, ok, tc.constraint)
+		for _, version := range tc.matching {
+			parts, _, ok := parseParts(version)
+			assert.True(t, ok, version)
+			assert.True(t, c.matches(parts), "%s should match %s", version, tc.constraint)
+		}
+		for _, version := range tc.other {
+			parts, _, ok := parseParts(version)
+			assert.True(t, ok, version)
+			assert.False(t, c.matches(parts), "%s shouldn't match %s", version, tc.constraint)
+		}
+	}
+
+	for _, invalid := range []string{"dev-main", "^", "not a version", ">=1.0 || dev-master"} {
+		_, ok := parseConstraint(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
@@ -5,6 +5,9 @@ import (
 )
 
 var (
-	ErrFetch           = errors.New("invalid json")
-	ErrPackageNotFound = errors.New("package not found")
+	ErrFetch              = errors.New("invalid json")
+	ErrPackageNotFound    = errors.New("package not found")
+	ErrInvalidPackageName = errors.New("invalid package name")
+	ErrInvalidLockfile    = errors.New("invalid lockfile")
+	ErrInvalidSBOM        = errors.New("invalid sbom")
 )
@@ -0,0 +1,215 @@
+package gem
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Dependency kinds as reported by rubygems.org.
+const (
+	KindRuntime     = "runtime"
+	KindDevelopment = "development"
+)
+
+// DefaultKinds are the dependency kinds installed together with a gem.
+var DefaultKinds = []string{KindRuntime}
+
+type DependencyProvider struct {
+	BaseURL string
+	Client  *http.Client
+	// Kinds lists dependency kinds that are traversed. DefaultKinds is used if it is empty.
+	Kinds []string
+
+	mu       sync.Mutex
+	versions map[string][]string
+}
+
+func Default() *DependencyProvider {
+	return &DependencyProvider{
+		BaseURL: "https://rubygems.org",
+		Client:  &http.Client{},
+		Kinds:   DefaultKinds,
+	}
+}
+
+func (d *DependencyProvider) fetch(ctx context.Context, gemName string, elem ...string) (io.ReadCloser, error) {
+	uri, err := url.JoinPath(d.BaseURL, elem...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	} else if resp.StatusCode == http.StatusNotFound {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrPackageNotFound, gemName)
+	} else if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%w: unexpected status %d for %s", dep_errors.ErrFetch, resp.StatusCode, gemName)
+	}
+	return resp.Body, nil
+}
+
+type gemDependency struct {
+	Name         string `json:"name"`
+	Requirements string `json:"requirements"`
+}
+
+// gemVersion is a version of a gem with its dependencies grouped by kind.
+type gemVersion struct {
+	Version      string                     `json:"version"`
+	Dependencies map[string][]gemDependency `json:"dependencies"`
+}
+
+func parseGemVersion(reader io.Reader) (*gemVersion, error) {
+	schema := &gemVersion{}
+	if err := json.NewDecoder(reader).Decode(schema); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	if schema.Dependencies == nil {
+		return nil, fmt.Errorf("%w: invalid json schema", dep_errors.ErrFetch)
+	}
+	return schema, nil
+}
+
+// fetchGemVersion returns the version of a gem, or the latest one if version is empty.
+func (d *DependencyProvider) fetchGemVersion(ctx context.Context, name string, version string) (*gemVersion, error) {
+	var body io.ReadCloser
+	var err error
+	if version == "" {
+		body, err = d.fetch(ctx, name, "api", "v1", "gems", url.PathEscape(name)+".json")
+	} else {
+		body, err = d.fetch(ctx, name+"@"+version, "api", "v2", "rubygems", url.PathEscape(name), "versions", url.PathEscape(version)+".json")
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+	return parseGemVersion(body)
+}
+
+func parseVersions(reader io.Reader) ([]string, error) {
+	var schema []struct {
+		Number   string `json:"number"`
+		Platform string `json:"platform"`
+	}
+	if err := json.NewDecoder(reader).Decode(&schema); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	result := make([]string, 0, len(schema))
+	for _, v := range schema {
+		// platform specific builds share the version of the ruby one
+		if v.Platform == "" || v.Platform == "ruby" {
+			result = append(result, v.Number)
+		}
+	}
+	return result, nil
+}
+
+func (d *DependencyProvider) fetchVersions(ctx context.Context, gemName string) ([]string, error) {
+	d.mu.Lock()
+	cached, ok := d.versions[gemName]
+	d.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	body, err := d.fetch(ctx, gemName, "api", "v1", "versions", url.PathEscape(gemName)+".json")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+	versions, err := parseVersions(body)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	if d.versions == nil {
+		d.versions = make(map[string][]string)
+	}
+	d.versions[gemName] = versions
+	d.mu.Unlock()
+	return versions, nil
+}
+
+// resolve picks the highest version of a dependency matching its requirements.
+// The version is empty if nothing matches, so that the latest version is used.
+func (d *DependencyProvider) resolve(ctx context.Context, dep gemDependency) (string, error) {
+	req, err := parseRequirement(dep.Requirements)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %s", dep_errors.ErrFetch, dep.Name, err)
+	}
+	versions, err := d.fetchVersions(ctx, dep.Name)
+	if err != nil {
+		return "", err
+	}
+	return highestMatching(req, versions), nil
+}
+
+// Resolve accepts "name" or "name@version".
+func (d *DependencyProvider) Resolve(ctx context.Context, spec string) (models.Package, error) {
+	name, version, _ := strings.Cut(spec, "@")
+	g, err := d.fetchGemVersion(ctx, name, version)
+	if err != nil {
+		return models.Package{}, err
+	}
+	if version == "" {
+		version = g.Version
+	}
+	return models.Package{Ecosystem: models.Gem, Name: name, Version: version}, nil
+}
+
+// FetchPackageDeps returns dependencies of the traversed kinds resolved to the
+// highest version matching their requirements. The latest version is used if
+// the package has no version.
+func (d *DependencyProvider) FetchPackageDeps(ctx context.Context, pkg models.Package) ([]models.Dependency, error) {
+	g, err := d.fetchGemVersion(ctx, pkg.Name, pkg.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := d.Kinds
+	if len(kinds) == 0 {
+		kinds = DefaultKinds
+	}
+
+	seen := make(map[string]struct{})
+	var result []models.Dependency
+	for _, kind := range kinds {
+		for _, dep := range g.Dependencies[kind] {
+			if _, ok := seen[dep.Name]; ok {
+				continue
+			}
+			seen[dep.Name] = struct{}{}
+			resolved, err := d.resolve(ctx, dep)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, models.Dependency{
+				Package:    models.Package{Ecosystem: models.Gem, Name: dep.Name, Version: resolved},
+				Constraint: dep.Requirements,
+				Kind:       kind,
+			})
+		}
+	}
+	return result, nil
+}
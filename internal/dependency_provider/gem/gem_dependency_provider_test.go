@@ -0,0 +1,128 @@
+package gem
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDependencyProvider_FetchPackageDeps(t *testing.T) {
+	rails := func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name": "rails", "version": "7.0.0", "dependencies": {
+			"runtime": [{"name": "rack", "requirements": "~> 2.0, >= 2.0.8"}],
+			"development": [{"name": "rake", "requirements": ">= 0"}]
+		}}`))
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/gems/rails.json", rails)
+	mux.HandleFunc("/api/v2/rubygems/rails/versions/7.0.0.json", rails)
+	mux.HandleFunc("/api/v2/rubygems/rack/versions/2.2.8.json",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"name": "rack", "version": "2.2.8", "dependencies": {"runtime": [], "development": []}}`))
+		})
+	mux.HandleFunc("/api/v1/versions/rack.json",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`[
+				{"number": "3.0.0", "platform": "ruby"},
+				{"number": "2.2.8", "platform": "ruby"},
+				{"number": "2.2.9", "platform": "java"},
+				{"number": "2.0.1", "platform": "ruby"}
+			]`))
+		})
+	mux.HandleFunc("/api/v1/versions/rake.json",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`[{"number": "13.0.6", "platform": "ruby"}]`))
+		})
+	mux.HandleFunc("/api/v1/gems/broken.json",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{}`))
+		})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	t.Run("test fetching runtime dependencies", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}}
+		pkg, err := d.Resolve(ctx, "rails")
+		assert.NoError(t, err)
+		assert.Equal(t, models.Package{Ecosystem: models.Gem, Name: "rails", Version: "7.0.0"}, pkg)
+
+		deps, err := d.FetchPackageDeps(ctx, pkg)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{
+				Package:    models.Package{Ecosystem: models.Gem, Name: "rack", Version: "2.2.8"},
+				Constraint: "~> 2.0, >= 2.0.8",
+				Kind:       KindRuntime,
+			},
+		}, deps)
+	})
+
+	t.Run("test fetching development dependencies", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}, Kinds: []string{KindRuntime, KindDevelopment}}
+		deps, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Gem, Name: "rails"})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(deps))
+		assert.Equal(t, models.Dependency{
+			Package:    models.Package{Ecosystem: models.Gem, Name: "rake", Version: "13.0.6"},
+			Constraint: ">= 0",
+			Kind:       KindDevelopment,
+		}, deps[1])
+	})
+
+	t.Run("test fetching specific version", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}}
+		pkg, err := d.Resolve(ctx, "rack@2.2.8")
+		assert.NoError(t, err)
+		assert.Equal(t, "rack@2.2.8", pkg.String())
+
+		deps, err := d.FetchPackageDeps(ctx, pkg)
+		assert.NoError(t, err)
+		assert.Empty(t, deps)
+	})
+
+	t.Run("test fetching if package not found", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}}
+		_, err := d.Resolve(ctx, "not-found")
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+	})
+
+	t.Run("test fetching if server returns json with invalid schema", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}}
+		_, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Gem, Name: "broken"})
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+	})
+
+	t.Run("test if fetching url is invalid", func(t *testing.T) {
+		d := DependencyProvider{BaseURL: ":::", Client: &http.Client{}}
+		_, err := d.FetchPackageDeps(context.Background(), models.Package{Ecosystem: models.Gem, Name: "rails"})
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+	})
+}
+
+func TestDefault(t *testing.T) {
+	d := Default()
+	assert.NotNil(t, d)
+	assert.NotNil(t, d.Client)
+	assert.Equal(t, "https://rubygems.org", d.BaseURL)
+	assert.Equal(t, DefaultKinds, d.Kinds)
+}
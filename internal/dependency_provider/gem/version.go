@@ -0,0 +1,210 @@
+package gem
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// segment of a Gem::Version: either a number or a pre-release string.
+type segment struct {
+	number int
+	str    string
+	isStr  bool
+}
+
+type version []segment
+
+var segmentPattern = regexp.MustCompile(`[0-9]+|[a-zA-Z]+`)
+
+func parseVersion(s string) (version, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return version{{number: 0}}, nil
+	}
+	var result version
+	for _, part := range segmentPattern.FindAllString(s, -1) {
+		if n, err := strconv.Atoi(part); err == nil {
+			result = append(result, segment{number: n})
+		} else {
+			result = append(result, segment{str: part, isStr: true})
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("malformed version %q", s)
+	}
+	return result, nil
+}
+
+func (v version) isPrerelease() bool {
+	for _, s := range v {
+		if s.isStr {
+			return true
+		}
+	}
+	return false
+}
+
+// canonical drops trailing zeros of every numeric run, as Gem::Version does.
+func (v version) canonical() version {
+	result := append(version(nil), v...)
+	for len(result) > 1 && !result[len(result)-1].isStr && result[len(result)-1].number == 0 {
+		result = result[:len(result)-1]
+	}
+	return result
+}
+
+func (v version) compare(other version) int {
+	a, b := v.canonical(), other.canonical()
+	for i := 0; i < len(a) || i < len(b); i++ {
+		l, r := segment{}, segment{}
+		if i < len(a) {
+			l = a[i]
+		}
+		if i < len(b) {
+			r = b[i]
+		}
+		switch {
+		case l.isStr && r.isStr:
+			if c := strings.Compare(l.str, r.str); c != 0 {
+				return c
+			}
+		case l.isStr:
+			return -1
+		case r.isStr:
+			return 1
+		case l.number != r.number:
+			if l.number < r.number {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// bump returns the upper bound of the pessimistic operator: ~> 1.2.3 means < 1.3.
+// Pre-release segments are dropped first, a version without leading numbers
+// such as "a" has no upper bound.
+func (v version) bump() (version, error) {
+	var result version
+	for _, s := range v {
+		if s.isStr {
+			break
+		}
+		result = append(result, s)
+	}
+	if len(result) == 0 {
+		return nil, errors.New("no numeric segment to bump")
+	}
+	if len(result) > 1 {
+		result = result[:len(result)-1]
+	}
+	result[len(result)-1].number++
+	return result, nil
+}
+
+type constraint struct {
+	op      string
+	version version
+	// upper is the exclusive upper bound of "~>".
+	upper version
+}
+
+func (c constraint) satisfiedBy(v version) bool {
+	cmp := v.compare(c.version)
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case "~>":
+		return cmp >= 0 && v.compare(c.upper) < 0
+	}
+	return false
+}
+
+var constraintPattern = regexp.MustCompile(`^\s*(=|!=|>=|<=|>|<|~>)?\s*(\S+)\s*$`)
+
+// requirement is a Gem::Requirement, a list of constraints that must all hold.
+type requirement []constraint
+
+func parseRequirement(s string) (requirement, error) {
+	var result requirement
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		m := constraintPattern.FindStringSubmatch(part)
+		if m == nil {
+			return nil, fmt.Errorf("malformed requirement %q", s)
+		}
+		v, err := parseVersion(m[2])
+		if err != nil {
+			return nil, err
+		}
+		c := constraint{op: m[1], version: v}
+		if c.op == "" {
+			c.op = "="
+		}
+		if c.op == "~>" {
+			if c.upper, err = v.bump(); err != nil {
+				return nil, fmt.Errorf("malformed requirement %q: %s", s, err)
+			}
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+func (r requirement) satisfiedBy(v version) bool {
+	for _, c := range r {
+		if !c.satisfiedBy(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// allowsPrerelease reports whether the requirement explicitly mentions a pre-release.
+func (r requirement) allowsPrerelease() bool {
+	for _, c := range r {
+		if c.version.isPrerelease() {
+			return true
+		}
+	}
+	return false
+}
+
+// highestMatching returns the highest version satisfying the requirement.
+// Pre-releases are only considered if the requirement mentions one.
+func highestMatching(req requirement, versions []string) string {
+	best := ""
+	var bestVersion version
+	for _, s := range versions {
+		v, err := parseVersion(s)
+		if err != nil {
+			continue
+		}
+		if v.isPrerelease() && !req.allowsPrerelease() {
+			continue
+		}
+		if !req.satisfiedBy(v) {
+			continue
+		}
+		if best == "" || v.compare(bestVersion) > 0 {
+			best, bestVersion = s, v
+		}
+	}
+	return best
+}
@@ -0,0 +1,62 @@
+package gem
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_requirement(t *testing.T) {
+	cases := []struct {
+		requirement string
+		version     string
+		satisfied   bool
+	}{
+		{">= 0", "1.0", true},
+		{"~> 1.2", "1.9.9", true},
+		{"~> 1.2", "2.0", false},
+		{"~> 1.2.3", "1.2.9", true},
+		{"~> 1.2.3", "1.3", false},
+		{"~> 1", "1.9", true},
+		{"~> 1", "2.0", false},
+		{"~> 1.a", "1.5", true},
+		{"~> 1.a", "2.0", false},
+		{"~> 2.0.rc1", "2.0.0", true},
+		{"~> 2.0.rc1", "2.9", true},
+		{"~> 2.0.rc1", "3.0", false},
+		{"~> 1.2.3.beta.4", "1.2.9", true},
+		{"~> 1.2.3.beta.4", "1.3", false},
+		{"= 1.0", "1.0.0", true},
+		{"!= 1.0", "1.0.0", false},
+		{"> 1.0, < 2", "1.5", true},
+		{"> 1.0, < 2", "2.0", false},
+		{"1.2", "1.2", true},
+		{">= 1.0", "1.0.rc1", false},
+		{">= 1.0.a", "1.0.rc1", true},
+	}
+	for _, c := range cases {
+		req, err := parseRequirement(c.requirement)
+		assert.NoError(t, err)
+		v, err := parseVersion(c.version)
+		assert.NoError(t, err)
+		assert.Equal(t, c.satisfied, req.satisfiedBy(v), "%s %s", c.version, c.requirement)
+	}
+
+	_, err := parseRequirement("=> 1.0")
+	assert.Error(t, err)
+	_, err = parseRequirement("~> a")
+	assert.Error(t, err)
+	_, err = parseRequirement("~> rc1, >= 1.0")
+	assert.Error(t, err)
+}
+
+func Test_highestMatching(t *testing.T) {
+	versions := []string{"1.0.0", "1.10.0", "1.9.0", "2.0.0.rc1", "2.0.0.beta"}
+	req, _ := parseRequirement(">= 1.0")
+	assert.Equal(t, "1.10.0", highestMatching(req, versions))
+
+	req, _ = parseRequirement(">= 2.0.0.a")
+	assert.Equal(t, "2.0.0.rc1", highestMatching(req, versions))
+
+	req, _ = parseRequirement("~> 3.0")
+	assert.Equal(t, "", highestMatching(req, versions))
+}
@@ -0,0 +1,237 @@
+package gomod
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Dependency kinds of go.mod requirements.
+const (
+	KindDirect   = "direct"
+	KindIndirect = "indirect"
+)
+
+type DependencyProvider struct {
+	// BaseURL is the address of a module proxy implementing the GOPROXY protocol.
+	BaseURL string
+	Client  *http.Client
+	// SkipIndirect drops requirements marked with "// indirect".
+	SkipIndirect bool
+	// main is the go.mod of the resolved module, the main module in terms of
+	// the go command. Its replace and exclude directives apply to the whole graph.
+	main *ModFile
+}
+
+func Default() *DependencyProvider {
+	return &DependencyProvider{
+		BaseURL: "https://proxy.golang.org",
+		Client:  &http.Client{},
+	}
+}
+
+func (d *DependencyProvider) fetch(ctx context.Context, modulePath string, elem ...string) ([]byte, error) {
+	escaped, err := escapePath(modulePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	uri, err := url.JoinPath(d.BaseURL, append([]string{escaped}, elem...)...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	// proxies answer with 404 or 410 if a module or version is unknown
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrPackageNotFound, modulePath)
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %d for %s", dep_errors.ErrFetch, resp.StatusCode, modulePath)
+	}
+
+	result := &bytes.Buffer{}
+	if _, err := io.Copy(result, resp.Body); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	return result.Bytes(), nil
+}
+
+// resolveVersion picks the version the go command would choose for "module@latest":
+// the highest release from @v/list, then the highest pre-release, then @latest.
+func (d *DependencyProvider) resolveVersion(ctx context.Context, modulePath string) (string, error) {
+	data, err := d.fetch(ctx, modulePath, "@v", "list")
+	if err != nil {
+		return "", err
+	}
+	if version := highestVersion(parseVersionList(data)); version != "" {
+		return version, nil
+	}
+
+	data, err = d.fetch(ctx, modulePath, "@latest")
+	if err != nil {
+		return "", err
+	}
+	var info struct {
+		Version string `json:"Version"`
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return "", fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	if info.Version == "" {
+		return "", fmt.Errorf("%w: %s has no versions", dep_errors.ErrPackageNotFound, modulePath)
+	}
+	return info.Version, nil
+}
+
+// Resolve accepts "module/path", "module/path@latest" or "module/path@version".
+func (d *DependencyProvider) Resolve(ctx context.Context, spec string) (models.Package, error) {
+	modulePath, version, _ := strings.Cut(spec, "@")
+	if (version == "" || version == "latest") && !IsLocalPath(modulePath) {
+		var err error
+		if version, err = d.resolveVersion(ctx, modulePath); err != nil {
+			return models.Package{}, err
+		}
+	}
+	d.main = nil
+	if !IsLocalPath(modulePath) {
+		modFile, err := d.modFile(ctx, modulePath, version)
+		if err != nil {
+			return models.Package{}, err
+		}
+		d.main = modFile
+	}
+	return models.Package{Ecosystem: models.Golang, Name: modulePath, Version: version}, nil
+}
+
+func (d *DependencyProvider) modFile(ctx context.Context, modulePath string, version string) (*ModFile, error) {
+	escapedVersion, err := escapeVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	data, err := d.fetch(ctx, modulePath, "@v", escapedVersion+".mod")
+	if err != nil {
+		return nil, err
+	}
+	return ParseModFile(data)
+}
+
+// FetchPackageDeps returns requirements of the module's go.mod with replace and
+// exclude directives of the resolved module applied. A replaced requirement
+// points to its replacement and keeps the required version as the constraint.
+// The latest version is used if the package has no version.
+func (d *DependencyProvider) FetchPackageDeps(ctx context.Context, pkg models.Package) ([]models.Dependency, error) {
+	// modules replaced with local directories can't be fetched from a proxy
+	if IsLocalPath(pkg.Name) {
+		return nil, nil
+	}
+
+	version := pkg.Version
+	if version == "" {
+		var err error
+		if version, err = d.resolveVersion(ctx, pkg.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	modFile, err := d.modFile(ctx, pkg.Name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// The go command applies replace and exclude directives of the main module
+	// to every go.mod, those of dependencies are ignored.
+	result := make([]models.Dependency, 0, len(modFile.Require))
+	for _, r := range modFile.Require {
+		if d.SkipIndirect && r.Indirect {
+			continue
+		}
+		target := r.ModuleVersion
+		if d.main != nil {
+			if d.main.IsExcluded(r.ModuleVersion) {
+				continue
+			}
+			target = d.main.Replacement(r.ModuleVersion)
+		}
+		kind := KindDirect
+		if r.Indirect {
+			kind = KindIndirect
+		}
+		result = append(result, models.Dependency{
+			Package:    models.Package{Ecosystem: models.Golang, Name: target.Path, Version: target.Version},
+			Constraint: r.Version,
+			Kind:       kind,
+		})
+	}
+	return result, nil
+}
+
+func parseVersionList(data []byte) []string {
+	var versions []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) != 0 {
+			versions = append(versions, fields[0])
+		}
+	}
+	return versions
+}
+
+// escapePath implements the module proxy case encoding: every upper case
+// letter is replaced with an exclamation mark followed by its lower case.
+func escapePath(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("empty module path")
+	}
+	var b strings.Builder
+	for _, r := range path {
+		switch {
+		case r == '!' || r > 0x7f:
+			return "", fmt.Errorf("invalid module path %q", path)
+		case 'A' <= r && r <= 'Z':
+			b.WriteByte('!')
+			b.WriteRune(r + 'a' - 'A')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String(), nil
+}
+
+func escapeVersion(version string) (string, error) {
+	if strings.ContainsAny(version, "/\\") {
+		return "", fmt.Errorf("invalid version %q", version)
+	}
+	return escapePath(version)
+}
+
+// CachePath returns the path of a go.mod file of a module version relative to
+// the download directory of the module cache, $GOMODCACHE/cache/download.
+func CachePath(m ModuleVersion) (string, error) {
+	escapedPath, err := escapePath(m.Path)
+	if err != nil {
+		return "", err
+	}
+	escapedVersion, err := escapeVersion(m.Version)
+	if err != nil {
+		return "", err
+	}
+	return escapedPath + "/@v/" + escapedVersion + ".mod", nil
+}
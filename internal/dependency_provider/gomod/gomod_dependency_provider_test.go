@@ -0,0 +1,200 @@
+package gomod
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/dependency_provider/gomod/test_utils"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDependencyProvider_FetchPackageDeps(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/github.com/!burenotti/app/@v/list",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("v1.0.0\nv1.2.0\nv1.10.0-rc.1\n"))
+		})
+	mux.HandleFunc("/github.com/!burenotti/app/@v/v1.2.0.mod",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(test_utils.NewModFile("github.com/Burenotti/app",
+				"golang.org/x/text v0.14.0",
+				"golang.org/x/sys v0.1.0 // indirect",
+			))
+		})
+	mux.HandleFunc("/example.com/replacing/@v/list",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("v1.0.0\n"))
+		})
+	mux.HandleFunc("/example.com/replacing/@v/v1.0.0.mod",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("module example.com/replacing\n\n" +
+				"require (\n\tgolang.org/x/text v0.14.0\n\tgolang.org/x/sys v0.1.0\n\texample.com/lib v1.0.0\n)\n\n" +
+				"replace golang.org/x/text => golang.org/x/text v0.15.0\n" +
+				"exclude golang.org/x/sys v0.1.0\n"))
+		})
+	mux.HandleFunc("/example.com/lib/@v/v1.0.0.mod",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("module example.com/lib\n\n" +
+				"require (\n\tgolang.org/x/text v0.14.0\n\tgolang.org/x/sys v0.1.0\n\tgolang.org/x/net v0.20.0\n)\n\n" +
+				"replace golang.org/x/net => golang.org/x/net v0.21.0\n"))
+		})
+	mux.HandleFunc("/example.com/pseudo/@v/list",
+		func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/example.com/pseudo/@latest",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"Version": "v0.0.0-20230101000000-abcdefabcdef"}`))
+		})
+	mux.HandleFunc("/example.com/pseudo/@v/v0.0.0-20230101000000-abcdefabcdef.mod",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(test_utils.NewModFile("example.com/pseudo"))
+		})
+	mux.HandleFunc("/example.com/broken/@v/v1.0.0.mod",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("require (\n"))
+		})
+	mux.HandleFunc("/example.com/gone/@v/list",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusGone)
+		})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	t.Run("test fetching latest release", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}}
+		pkg, err := d.Resolve(ctx, "github.com/Burenotti/app")
+		assert.NoError(t, err)
+		assert.Equal(t, models.Package{Ecosystem: models.Golang, Name: "github.com/Burenotti/app", Version: "v1.2.0"}, pkg)
+
+		deps, err := d.FetchPackageDeps(ctx, pkg)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{
+				Package:    models.Package{Ecosystem: models.Golang, Name: "golang.org/x/text", Version: "v0.14.0"},
+				Constraint: "v0.14.0",
+				Kind:       KindDirect,
+			},
+			{
+				Package:    models.Package{Ecosystem: models.Golang, Name: "golang.org/x/sys", Version: "v0.1.0"},
+				Constraint: "v0.1.0",
+				Kind:       KindIndirect,
+			},
+		}, deps)
+	})
+
+	t.Run("test replace and exclude of the root module apply to the whole graph", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}}
+		pkg, err := d.Resolve(ctx, "example.com/replacing")
+		assert.NoError(t, err)
+		text := models.Dependency{
+			Package:    models.Package{Ecosystem: models.Golang, Name: "golang.org/x/text", Version: "v0.15.0"},
+			Constraint: "v0.14.0",
+			Kind:       KindDirect,
+		}
+		lib := models.Package{Ecosystem: models.Golang, Name: "example.com/lib", Version: "v1.0.0"}
+
+		deps, err := d.FetchPackageDeps(ctx, pkg)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{text, {Package: lib, Constraint: "v1.0.0", Kind: KindDirect}}, deps)
+
+		// directives of a dependency are ignored like the go command does
+		deps, err = d.FetchPackageDeps(ctx, lib)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{text, {
+			Package:    models.Package{Ecosystem: models.Golang, Name: "golang.org/x/net", Version: "v0.20.0"},
+			Constraint: "v0.20.0",
+			Kind:       KindDirect,
+		}}, deps)
+
+		// another root brings its own directives
+		_, err = d.Resolve(ctx, "github.com/Burenotti/app")
+		assert.NoError(t, err)
+		deps, err = d.FetchPackageDeps(ctx, lib)
+		assert.NoError(t, err)
+		assert.Len(t, deps, 3)
+	})
+
+	t.Run("test skipping indirect requirements", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}, SkipIndirect: true}
+		deps, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Golang, Name: "github.com/Burenotti/app", Version: "v1.2.0"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(deps))
+		assert.Equal(t, "golang.org/x/text@v0.14.0", deps[0].Package.String())
+	})
+
+	t.Run("test fetching module without tagged versions", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}}
+		pkg, err := d.Resolve(ctx, "example.com/pseudo@latest")
+		assert.NoError(t, err)
+		assert.Equal(t, "v0.0.0-20230101000000-abcdefabcdef", pkg.Version)
+
+		deps, err := d.FetchPackageDeps(ctx, pkg)
+		assert.NoError(t, err)
+		assert.Empty(t, deps)
+	})
+
+	t.Run("test local replacement is a leaf", func(t *testing.T) {
+		d := DependencyProvider{BaseURL: ":::", Client: &http.Client{}}
+		deps, err := d.FetchPackageDeps(context.Background(), models.Package{Ecosystem: models.Golang, Name: "../local"})
+		assert.NoError(t, err)
+		assert.Empty(t, deps)
+	})
+
+	t.Run("test fetching if module not found", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}}
+		_, err := d.Resolve(ctx, "example.com/gone")
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+
+		_, err = d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Golang, Name: "example.com/missing", Version: "v1.0.0"})
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+	})
+
+	t.Run("test fetching if go.mod is invalid", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}}
+		_, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Golang, Name: "example.com/broken", Version: "v1.0.0"})
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+	})
+
+	t.Run("test if fetching url is invalid", func(t *testing.T) {
+		d := DependencyProvider{BaseURL: ":::", Client: &http.Client{}}
+		_, err := d.FetchPackageDeps(context.Background(), models.Package{Ecosystem: models.Golang, Name: "example.com/app"})
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+	})
+}
+
+func Test_escapePath(t *testing.T) {
+	escaped, err := escapePath("github.com/BurntSushi/toml")
+	assert.NoError(t, err)
+	assert.Equal(t, "github.com/!burnt!sushi/toml", escaped)
+
+	_, err = escapePath("bad!path")
+	assert.Error(t, err)
+}
+
+func TestDefault(t *testing.T) {
+	d := Default()
+	assert.NotNil(t, d)
+	assert.NotNil(t, d.Client)
+	assert.Equal(t, "https://proxy.golang.org", d.BaseURL)
+}
@@ -0,0 +1,254 @@
+package gomod
+
+import (
+	"bufio"
+	"bytes"
+	"depviz/internal/dependency_provider/dep_errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ModuleVersion is a module path with an optional version.
+type ModuleVersion struct {
+	Path    string
+	Version string
+}
+
+func (m ModuleVersion) String() string {
+	if m.Version == "" {
+		return m.Path
+	}
+	return m.Path + "@" + m.Version
+}
+
+// Require is a single requirement of a go.mod file.
+type Require struct {
+	ModuleVersion
+	Indirect bool
+}
+
+// Replace is a replace directive. Old.Version is empty if all versions are replaced,
+// New.Version is empty if the module is replaced with a local directory.
+type Replace struct {
+	Old ModuleVersion
+	New ModuleVersion
+}
+
+// ModFile holds directives of a go.mod file that affect the module graph.
+type ModFile struct {
+	Module  string
+	Require []Require
+	Replace []Replace
+	Exclude []ModuleVersion
+}
+
+// ParseModFile parses a go.mod file.
+func ParseModFile(data []byte) (*ModFile, error) {
+	result := &ModFile{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	block := ""
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line, comment := splitComment(scanner.Text())
+		fields, err := splitFields(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: go.mod:%d: %s", dep_errors.ErrFetch, lineNo, err)
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		verb := block
+		if block == "" {
+			verb, fields = fields[0], fields[1:]
+			if len(fields) == 1 && fields[0] == "(" {
+				block = verb
+				continue
+			}
+		} else if len(fields) == 1 && fields[0] == ")" {
+			block = ""
+			continue
+		}
+
+		if err := result.addDirective(verb, fields, comment); err != nil {
+			return nil, fmt.Errorf("%w: go.mod:%d: %s", dep_errors.ErrFetch, lineNo, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	if block != "" {
+		return nil, fmt.Errorf("%w: go.mod: unterminated %s block", dep_errors.ErrFetch, block)
+	}
+	return result, nil
+}
+
+func (f *ModFile) addDirective(verb string, args []string, comment string) error {
+	switch verb {
+	case "module":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: module path")
+		}
+		f.Module = args[0]
+	case "require":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: require module/path v1.2.3")
+		}
+		f.Require = append(f.Require, Require{
+			ModuleVersion: ModuleVersion{Path: args[0], Version: args[1]},
+			Indirect:      isIndirect(comment),
+		})
+	case "exclude":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: exclude module/path v1.2.3")
+		}
+		f.Exclude = append(f.Exclude, ModuleVersion{Path: args[0], Version: args[1]})
+	case "replace":
+		arrow := -1
+		for i, arg := range args {
+			if arg == "=>" {
+				arrow = i
+			}
+		}
+		if arrow < 1 || arrow > 2 || len(args)-arrow-1 < 1 || len(args)-arrow-1 > 2 {
+			return fmt.Errorf("usage: replace module/path [v1.2.3] => other/module [v1.4.5]")
+		}
+		r := Replace{Old: ModuleVersion{Path: args[0]}, New: ModuleVersion{Path: args[arrow+1]}}
+		if arrow == 2 {
+			r.Old.Version = args[1]
+		}
+		if len(args)-arrow-1 == 2 {
+			r.New.Version = args[arrow+2]
+		}
+		f.Replace = append(f.Replace, r)
+	}
+	// go, toolchain, retract and other directives don't affect the graph
+	return nil
+}
+
+// Requirements applies replace and exclude directives to the requirement list.
+func (f *ModFile) Requirements() []Require {
+	excluded := make(map[ModuleVersion]struct{}, len(f.Exclude))
+	for _, e := range f.Exclude {
+		excluded[e] = struct{}{}
+	}
+
+	result := make([]Require, 0, len(f.Require))
+	for _, r := range f.Require {
+		// since go 1.16 requirements on excluded versions are ignored
+		if _, ok := excluded[r.ModuleVersion]; ok {
+			continue
+		}
+		r.ModuleVersion = f.Replacement(r.ModuleVersion)
+		result = append(result, r)
+	}
+	return result
+}
+
+// Replacement returns the module m is replaced with, or m itself if it isn't replaced.
+func (f *ModFile) Replacement(m ModuleVersion) ModuleVersion {
+	var wildcard *Replace
+	for i := range f.Replace {
+		r := &f.Replace[i]
+		if r.Old.Path != m.Path {
+			continue
+		}
+		if r.Old.Version == m.Version {
+			return r.New
+		}
+		if r.Old.Version == "" {
+			wildcard = r
+		}
+	}
+	if wildcard != nil {
+		return wildcard.New
+	}
+	return m
+}
+
+// IsExcluded reports whether an exclude directive lists the module version.
+func (f *ModFile) IsExcluded(m ModuleVersion) bool {
+	for _, e := range f.Exclude {
+		if e == m {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLocalPath reports whether a replacement target is a directory rather than a module.
+func IsLocalPath(path string) bool {
+	return strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") ||
+		strings.HasPrefix(path, "/") || path == "." || path == ".." ||
+		(len(path) > 2 && path[1] == ':' && (path[2] == '\\' || path[2] == '/'))
+}
+
+func isIndirect(comment string) bool {
+	comment = strings.TrimSpace(comment)
+	return comment == "indirect" || strings.HasPrefix(comment, "indirect;")
+}
+
+func splitComment(line string) (string, string) {
+	inQuote := byte(0)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case inQuote != 0:
+			if c == '\\' && inQuote == '"' {
+				i++
+			} else if c == inQuote {
+				inQuote = 0
+			}
+		case c == '"' || c == '`':
+			inQuote = c
+		case c == '/' && i+1 < len(line) && line[i+1] == '/':
+			return line[:i], line[i+2:]
+		}
+	}
+	return line, ""
+}
+
+func splitFields(line string) ([]string, error) {
+	var fields []string
+	for {
+		line = strings.TrimLeft(line, " \t\r")
+		if line == "" {
+			return fields, nil
+		}
+		switch line[0] {
+		case '"':
+			end := 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				return nil, fmt.Errorf("unterminated quoted string")
+			}
+			value, err := strconv.Unquote(line[:end+1])
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, value)
+			line = line[end+1:]
+		case '`':
+			end := strings.IndexByte(line[1:], '`')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated raw string")
+			}
+			fields = append(fields, line[1:end+1])
+			line = line[end+2:]
+		default:
+			end := strings.IndexAny(line, " \t\r")
+			if end < 0 {
+				end = len(line)
+			}
+			fields = append(fields, line[:end])
+			line = line[end:]
+		}
+	}
+}
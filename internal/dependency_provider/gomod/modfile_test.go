@@ -0,0 +1,94 @@
+package gomod
+
+import (
+	"depviz/internal/dependency_provider/dep_errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseModFile(t *testing.T) {
+	t.Run("test parsing go.mod with blocks and single line directives", func(t *testing.T) {
+		data := []byte(`// comment
+module example.com/app
+
+go 1.21
+
+require github.com/stretchr/testify v1.8.4
+
+require (
+	golang.org/x/text v0.14.0 // indirect
+	"example.com/quoted" v1.0.0
+	example.com/old v1.2.0 // indirect; for tests
+)
+
+replace example.com/old v1.2.0 => example.com/new v1.3.0
+
+replace (
+	example.com/local => ../local
+)
+
+exclude golang.org/x/net v0.1.0
+retract v0.9.0
+`)
+		f, err := ParseModFile(data)
+		assert.NoError(t, err)
+		assert.Equal(t, "example.com/app", f.Module)
+		assert.Equal(t, []Require{
+			{ModuleVersion: ModuleVersion{"github.com/stretchr/testify", "v1.8.4"}},
+			{ModuleVersion: ModuleVersion{"golang.org/x/text", "v0.14.0"}, Indirect: true},
+			{ModuleVersion: ModuleVersion{"example.com/quoted", "v1.0.0"}},
+			{ModuleVersion: ModuleVersion{"example.com/old", "v1.2.0"}, Indirect: true},
+		}, f.Require)
+		assert.Equal(t, []Replace{
+			{Old: ModuleVersion{"example.com/old", "v1.2.0"}, New: ModuleVersion{"example.com/new", "v1.3.0"}},
+			{Old: ModuleVersion{Path: "example.com/local"}, New: ModuleVersion{Path: "../local"}},
+		}, f.Replace)
+		assert.Equal(t, []ModuleVersion{{"golang.org/x/net", "v0.1.0"}}, f.Exclude)
+	})
+
+	t.Run("test invalid directives", func(t *testing.T) {
+		_, err := ParseModFile([]byte("require a\n"))
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+
+		_, err = ParseModFile([]byte("replace a => \n"))
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+
+		_, err = ParseModFile([]byte("require (\n a v1.0.0\n"))
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+	})
+}
+
+func TestModFile_Requirements(t *testing.T) {
+	f, err := ParseModFile([]byte(`module m
+require (
+	a v1.0.0
+	b v1.0.0
+	c v1.0.0
+	d v2.0.0
+)
+replace a v1.0.0 => a2 v1.1.0
+replace a v0.9.0 => a3 v0.9.1
+replace b => ./b
+replace d v1.0.0 => d2 v1.0.0
+exclude c v1.0.0
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []Require{
+		{ModuleVersion: ModuleVersion{"a2", "v1.1.0"}},
+		{ModuleVersion: ModuleVersion{Path: "./b"}},
+		{ModuleVersion: ModuleVersion{"d", "v2.0.0"}},
+	}, f.Requirements())
+}
+
+func Test_highestVersion(t *testing.T) {
+	assert.Equal(t, "v1.10.0", highestVersion([]string{"v1.2.0", "v1.10.0", "v1.9.9", "v2.0.0-rc.1", "bad"}))
+	assert.Equal(t, "v2.0.0-rc.2", highestVersion([]string{"v2.0.0-rc.1", "v2.0.0-rc.2", "v2.0.0-beta"}))
+	assert.Equal(t, "", highestVersion(nil))
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, 1, CompareVersions("v1.10.0", "v1.9.0"))
+	assert.Equal(t, -1, CompareVersions("v1.0.0-rc.1", "v1.0.0"))
+	assert.Equal(t, 0, CompareVersions("v0.0.0-20231010000000-abcdef123456", "v0.0.0-20231010000000-abcdef123456"))
+	assert.Equal(t, 1, CompareVersions("v0.1.0", "bad"))
+}
@@ -0,0 +1,125 @@
+package gomod
+
+import (
+	"strconv"
+	"strings"
+)
+
+type semver struct {
+	major, minor, patch int
+	prerelease          []string
+}
+
+func parseSemver(version string) (semver, bool) {
+	if !strings.HasPrefix(version, "v") {
+		return semver{}, false
+	}
+	version, _, _ = strings.Cut(version[1:], "+")
+	core, pre, hasPre := strings.Cut(version, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return semver{}, false
+	}
+	var nums [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return semver{}, false
+		}
+		nums[i] = n
+	}
+	v := semver{major: nums[0], minor: nums[1], patch: nums[2]}
+	if hasPre {
+		v.prerelease = strings.Split(pre, ".")
+	}
+	return v, true
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (v semver) compare(other semver) int {
+	if c := compareInts(v.major, other.major); c != 0 {
+		return c
+	}
+	if c := compareInts(v.minor, other.minor); c != 0 {
+		return c
+	}
+	if c := compareInts(v.patch, other.patch); c != 0 {
+		return c
+	}
+	switch {
+	case len(v.prerelease) == 0 && len(other.prerelease) == 0:
+		return 0
+	case len(v.prerelease) == 0:
+		return 1
+	case len(other.prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.prerelease) && i < len(other.prerelease); i++ {
+		a, b := v.prerelease[i], other.prerelease[i]
+		an, aErr := strconv.Atoi(a)
+		bn, bErr := strconv.Atoi(b)
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareInts(an, bn); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(a, b); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInts(len(v.prerelease), len(other.prerelease))
+}
+
+// CompareVersions compares two module versions the way minimal version
+// selection does. Invalid versions are lower than valid ones.
+func CompareVersions(a, b string) int {
+	va, okA := parseSemver(a)
+	vb, okB := parseSemver(b)
+	switch {
+	case okA && okB:
+		return va.compare(vb)
+	case okA:
+		return 1
+	case okB:
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+// highestVersion returns the highest release version, or the highest
+// pre-release if there are no releases. Invalid versions are ignored.
+func highestVersion(versions []string) string {
+	best, bestRelease := "", ""
+	var bestV, bestReleaseV semver
+	for _, version := range versions {
+		v, ok := parseSemver(version)
+		if !ok {
+			continue
+		}
+		if best == "" || v.compare(bestV) > 0 {
+			best, bestV = version, v
+		}
+		if len(v.prerelease) == 0 && (bestRelease == "" || v.compare(bestReleaseV) > 0) {
+			bestRelease, bestReleaseV = version, v
+		}
+	}
+	if bestRelease != "" {
+		return bestRelease
+	}
+	return best
+}
@@ -0,0 +1,21 @@
+package test_utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewModFile renders a go.mod file of module which requires the given
+// "module/path version" lines.
+func NewModFile(module string, requires ...string) []byte {
+	b := &strings.Builder{}
+	_, _ = fmt.Fprintf(b, "module %s\n\ngo 1.19\n", module)
+	if len(requires) != 0 {
+		b.WriteString("\nrequire (\n")
+		for _, r := range requires {
+			_, _ = fmt.Fprintf(b, "\t%s\n", r)
+		}
+		b.WriteString(")\n")
+	}
+	return []byte(b.String())
+}
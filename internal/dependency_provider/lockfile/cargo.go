@@ -0,0 +1,259 @@
+package lockfile
+
+import (
+	"depviz/internal/dependency_provider/cargo"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+func cargoPackage(name, version string) models.Package {
+	return models.Package{Ecosystem: models.Cargo, Name: name, Version: version}
+}
+
+// cargoRequirement is a value of [dependencies] in Cargo.toml: a version
+// requirement or a table.
+type cargoRequirement struct {
+	Version  string
+	Package  string
+	Optional bool
+}
+
+func (r *cargoRequirement) UnmarshalTOML(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		r.Version = v
+	case map[string]interface{}:
+		r.Version, _ = v["version"].(string)
+		r.Package, _ = v["package"].(string)
+		r.Optional, _ = v["optional"].(bool)
+	default:
+		return fmt.Errorf("invalid dependency %v", value)
+	}
+	return nil
+}
+
+type cargoDependencies struct {
+	Dependencies      map[string]cargoRequirement `toml:"dependencies"`
+	BuildDependencies map[string]cargoRequirement `toml:"build-dependencies"`
+	DevDependencies   map[string]cargoRequirement `toml:"dev-dependencies"`
+}
+
+type cargoManifest struct {
+	Package struct {
+		Name string `toml:"name"`
+	} `toml:"package"`
+	Workspace struct {
+		Members []string `toml:"members"`
+		Exclude []string `toml:"exclude"`
+	} `toml:"workspace"`
+	cargoDependencies
+	Target map[string]cargoDependencies `toml:"target"`
+}
+
+// declared returns dependencies of the manifest by package name, a package
+// may be declared for several kinds.
+func (m *cargoManifest) declared() map[string][]models.Dependency {
+	result := make(map[string][]models.Dependency)
+	add := func(deps map[string]cargoRequirement, kind string) {
+		for key, dep := range deps {
+			// a dependency may be renamed, the key is the name in the code then
+			name := key
+			if dep.Package != "" {
+				name = dep.Package
+			}
+			result[name] = append(result[name], models.Dependency{Constraint: dep.Version, Kind: kind, Optional: dep.Optional})
+		}
+	}
+	all := []cargoDependencies{m.cargoDependencies}
+	for _, target := range sortedTargets(m.Target) {
+		all = append(all, m.Target[target])
+	}
+	for _, deps := range all {
+		add(deps.Dependencies, cargo.KindNormal)
+		add(deps.BuildDependencies, cargo.KindBuild)
+		add(deps.DevDependencies, cargo.KindDev)
+	}
+	return result
+}
+
+// readCargoManifest reads Cargo.toml of the directory dir. It returns nil if
+// there is no manifest.
+func readCargoManifest(fsys fs.FS, dir string) (*cargoManifest, error) {
+	name := path.Join(dir, "Cargo.toml")
+	data, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var m cargoManifest
+	if err := toml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("can't parse %s: %w", name, err)
+	}
+	return &m, nil
+}
+
+type cargoLockPackage struct {
+	Name         string   `toml:"name"`
+	Version      string   `toml:"version"`
+	Source       string   `toml:"source"`
+	Dependencies []string `toml:"dependencies"`
+}
+
+// local reports whether the package is a member of the workspace or a path dependency.
+func (p *cargoLockPackage) local() bool {
+	return p.Source == ""
+}
+
+// cargoLockPackages indexes packages of Cargo.lock by name.
+type cargoLockPackages map[string][]*cargoLockPackage
+
+// lookup finds the package of a dependency entry: "name", "name version" or
+// "name version (source)". Version and source are only written if they are
+// needed to tell packages apart, version 1 lockfiles always write both.
+func (p cargoLockPackages) lookup(entry string) (*cargoLockPackage, bool) {
+	fields := strings.Fields(entry)
+	if len(fields) == 0 {
+		return nil, false
+	}
+	version, source := "", ""
+	if len(fields) > 1 {
+		version = fields[1]
+	}
+	if len(fields) > 2 {
+		source = strings.TrimSuffix(strings.TrimPrefix(fields[2], "("), ")")
+	}
+	for _, pkg := range p[fields[0]] {
+		if (version == "" || pkg.Version == version) && (source == "" || pkg.Source == source) {
+			return pkg, true
+		}
+	}
+	return nil, false
+}
+
+// parseCargoLock reads Cargo.lock. The lockfile doesn't tell the root, so
+// Cargo.toml next to it and the manifests of workspace members are read too,
+// they give kinds and requirements of dependencies of local packages.
+// Dependencies of other packages are drawn as normal ones.
+func parseCargoLock(fsys fs.FS, data []byte, project string) (*Graph, error) {
+	var schema struct {
+		Version *int                `toml:"version"`
+		Package []*cargoLockPackage `toml:"package"`
+	}
+	if err := toml.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidLockfile, err)
+	}
+	// version 1 and 2 lockfiles have no version
+	if schema.Version != nil && (*schema.Version < 3 || *schema.Version > 4) {
+		return nil, fmt.Errorf("%w: unsupported Cargo.lock version %d", dep_errors.ErrInvalidLockfile, *schema.Version)
+	}
+	if len(schema.Package) == 0 {
+		return nil, fmt.Errorf("%w: no packages", dep_errors.ErrInvalidLockfile)
+	}
+
+	packages := make(cargoLockPackages)
+	local := make(map[string]*cargoLockPackage)
+	for _, p := range schema.Package {
+		packages[p.Name] = append(packages[p.Name], p)
+		if p.local() {
+			local[p.Name] = p
+		}
+	}
+
+	rootManifest, err := readCargoManifest(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	manifests := make(map[string]*cargoManifest)
+	var members []string
+	if rootManifest != nil {
+		if rootManifest.Package.Name != "" {
+			manifests[rootManifest.Package.Name] = rootManifest
+		}
+		dirs, err := workspaceDirs(fsys, rootManifest.Workspace.Members, "Cargo.toml")
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range dirs {
+			if isWorkspace(rootManifest.Workspace.Exclude, dir) {
+				continue
+			}
+			m, err := readCargoManifest(fsys, dir)
+			if err != nil {
+				return nil, err
+			}
+			manifests[m.Package.Name] = m
+			members = append(members, m.Package.Name)
+		}
+	} else {
+		// without manifests the local packages nothing requires are the workspace
+		required := make(map[*cargoLockPackage]struct{})
+		for _, p := range schema.Package {
+			for _, entry := range p.Dependencies {
+				if dep, ok := packages.lookup(entry); ok {
+					required[dep] = struct{}{}
+				}
+			}
+		}
+		for _, p := range schema.Package {
+			if _, ok := required[p]; !ok && p.local() {
+				members = append(members, p.Name)
+			}
+		}
+	}
+
+	root := cargoPackage(project, "")
+	if rootManifest != nil && local[rootManifest.Package.Name] != nil {
+		root = cargoPackage(rootManifest.Package.Name, local[rootManifest.Package.Name].Version)
+	} else if rootManifest == nil && len(members) == 1 {
+		root = cargoPackage(members[0], local[members[0]].Version)
+	}
+	g := NewGraph(root)
+
+	for _, p := range schema.Package {
+		from := cargoPackage(p.Name, p.Version)
+		g.AddPackage(from)
+		var declared map[string][]models.Dependency
+		if m, ok := manifests[p.Name]; ok && p.local() {
+			declared = m.declared()
+		}
+		for _, entry := range p.Dependencies {
+			dep, ok := packages.lookup(entry)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s requires %s which is not locked", dep_errors.ErrInvalidLockfile, from, entry)
+			}
+			to := cargoPackage(dep.Name, dep.Version)
+			kinds := declared[dep.Name]
+			if len(kinds) == 0 {
+				kinds = []models.Dependency{{Kind: cargo.KindNormal}}
+			}
+			for _, d := range kinds {
+				d.Package = to
+				g.AddDependency(from, d)
+			}
+		}
+	}
+	for _, name := range members {
+		if p, ok := local[name]; ok && name != root.Name {
+			g.AddDependency(root, models.Dependency{Package: cargoPackage(p.Name, p.Version), Kind: KindWorkspace})
+		}
+	}
+	return g, nil
+}
+
+func sortedTargets(targets map[string]cargoDependencies) []string {
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
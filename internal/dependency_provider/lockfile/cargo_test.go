@@ -0,0 +1,171 @@
+package lockfile
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/fstest"
+)
+
+func cargoPackageAt(name, version string) models.Package {
+	return models.Package{Ecosystem: models.Cargo, Name: name, Version: version}
+}
+
+func TestParseCargoLock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("test reads workspace with manifests", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"Cargo.lock": {Data: []byte(`# This file is automatically @generated by Cargo.
+# It is not intended for manual editing.
+version = 3
+
+[[package]]
+name = "app"
+version = "0.1.0"
+dependencies = [
+ "cc",
+ "rand 0.7.3",
+ "serde",
+ "util",
+ "winapi",
+]
+
+[[package]]
+name = "cc"
+version = "1.0.83"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+checksum = "0000"
+
+[[package]]
+name = "libc"
+version = "0.2.150"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "rand"
+version = "0.7.3"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "rand"
+version = "0.8.5"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+dependencies = [
+ "libc",
+]
+
+[[package]]
+name = "serde"
+version = "1.0.193"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "tool"
+version = "0.1.0"
+
+[[package]]
+name = "util"
+version = "0.1.0"
+dependencies = [
+ "rand 0.8.5 (registry+https://github.com/rust-lang/crates.io-index)",
+]
+
+[[package]]
+name = "winapi"
+version = "0.3.9"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+`)},
+			"Cargo.toml": {Data: []byte(`
+[package]
+name = "app"
+version = "0.1.0"
+
+[workspace]
+members = ["crates/*"]
+
+[dependencies]
+util = { path = "crates/util" }
+serde = { version = "1", features = ["derive"] }
+rand07 = { package = "rand", version = "0.7", optional = true }
+
+[build-dependencies]
+cc = "1.0"
+
+[dev-dependencies]
+serde = "1"
+
+[target.'cfg(windows)'.dependencies]
+winapi = "0.3"
+`)},
+			"crates/util/Cargo.toml": {Data: []byte("[package]\nname = \"util\"\n\n[dependencies]\nrand = \"0.8\"\n")},
+			"crates/tool/Cargo.toml": {Data: []byte("[package]\nname = \"tool\"\n")},
+		}
+		g, err := parse(fsys, "Cargo.lock", "project")
+		assert.NoError(t, err)
+		assert.Equal(t, cargoPackageAt("app", "0.1.0"), g.Root)
+
+		deps, err := g.FetchPackageDeps(ctx, g.Root)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: cargoPackageAt("cc", "1.0.83"), Constraint: "1.0", Kind: "build"},
+			{Package: cargoPackageAt("rand", "0.7.3"), Constraint: "0.7", Kind: "normal", Optional: true},
+			{Package: cargoPackageAt("serde", "1.0.193"), Constraint: "1", Kind: "normal"},
+			{Package: cargoPackageAt("serde", "1.0.193"), Constraint: "1", Kind: "dev"},
+			{Package: cargoPackageAt("util", "0.1.0"), Kind: "normal"},
+			{Package: cargoPackageAt("winapi", "0.3.9"), Constraint: "0.3", Kind: "normal"},
+			{Package: cargoPackageAt("tool", "0.1.0"), Kind: KindWorkspace},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, cargoPackageAt("util", "0.1.0"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: cargoPackageAt("rand", "0.8.5"), Constraint: "0.8", Kind: "normal"},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, cargoPackageAt("rand", "0.8.5"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: cargoPackageAt("libc", "0.2.150"), Kind: "normal"},
+		}, deps)
+	})
+
+	t.Run("test reads version 1 lockfile without manifest", func(t *testing.T) {
+		g, err := parse(fstest.MapFS{"Cargo.lock": {Data: []byte(`[[package]]
+name = "hello"
+version = "0.1.0"
+dependencies = [
+ "itoa 1.0.9 (registry+https://github.com/rust-lang/crates.io-index)",
+]
+
+[[package]]
+name = "itoa"
+version = "1.0.9"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[metadata]
+"checksum itoa 1.0.9 (registry+https://github.com/rust-lang/crates.io-index)" = "0000"
+`)}}, "Cargo.lock", "project")
+		assert.NoError(t, err)
+		assert.Equal(t, cargoPackageAt("hello", "0.1.0"), g.Root)
+
+		deps, err := g.FetchPackageDeps(ctx, g.Root)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: cargoPackageAt("itoa", "1.0.9"), Kind: "normal"},
+		}, deps)
+	})
+
+	t.Run("test returns error for invalid lockfile", func(t *testing.T) {
+		for _, data := range []string{
+			"version = 5\n\n[[package]]\nname = \"a\"\nversion = \"0.1.0\"\n",
+			"version = 4\n",
+			"version = 4\n\n[[package]]\nname = \"a\"\nversion = \"0.1.0\"\ndependencies = [\"b\"]\n",
+		} {
+			_, err := parse(fstest.MapFS{"Cargo.lock": {Data: []byte(data)}}, "Cargo.lock", "project")
+			assert.ErrorIs(t, err, dep_errors.ErrInvalidLockfile)
+		}
+	})
+}
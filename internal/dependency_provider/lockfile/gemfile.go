@@ -0,0 +1,162 @@
+package lockfile
+
+import (
+	"bufio"
+	"bytes"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/dependency_provider/gem"
+	"depviz/internal/models"
+	"fmt"
+	"strings"
+)
+
+func gemPackage(name, version string) models.Package {
+	return models.Package{Ecosystem: models.Gem, Name: name, Version: version}
+}
+
+// gemRequirement is a gem name with an optional requirement in parentheses,
+// e.g. "rack (~> 2.0, >= 2.0.8)".
+type gemRequirement struct {
+	name       string
+	constraint string
+}
+
+type gemSpec struct {
+	pkg models.Package
+	// local is set for gems of a PATH source at the project directory,
+	// i.e. the gem of a gemspec
+	local bool
+	deps  []gemRequirement
+}
+
+func parseGemRequirement(s string) (gemRequirement, bool) {
+	name, rest, found := strings.Cut(strings.TrimSpace(s), " ")
+	if name == "" {
+		return gemRequirement{}, false
+	}
+	if !found {
+		return gemRequirement{name: name}, true
+	}
+	rest = strings.TrimSpace(rest)
+	if !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+		return gemRequirement{}, false
+	}
+	return gemRequirement{name: name, constraint: rest[1 : len(rest)-1]}, true
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// parseGemfileLock reads Gemfile.lock of Bundler. Gems of the GEM, GIT and
+// PATH sources are specs indented by four spaces with their requirements
+// indented by six. Dependencies of the Gemfile are listed under DEPENDENCIES,
+// a "!" marks gems of a GIT or PATH source. If the Gemfile loads the gemspec
+// of the project, the gem is the root and other dependencies of the Gemfile
+// are drawn as its development dependencies.
+func parseGemfileLock(data []byte, project string) (*Graph, error) {
+	var specs []*gemSpec
+	byName := make(map[string]*gemSpec)
+	var rootDeps []gemRequirement
+	hasDependencies := false
+
+	section, remote := "", ""
+	var spec *gemSpec
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := indentation(line)
+		if indent == 0 {
+			section, remote, spec = line, "", nil
+			if section == "DEPENDENCIES" {
+				hasDependencies = true
+			}
+			continue
+		}
+
+		switch section {
+		case "GEM", "GIT", "PATH", "PLUGIN SOURCE":
+			switch {
+			case indent == 2:
+				if value := strings.TrimPrefix(strings.TrimSpace(line), "remote:"); value != strings.TrimSpace(line) {
+					remote = strings.TrimSpace(value)
+				}
+			case indent == 4:
+				req, ok := parseGemRequirement(line)
+				if !ok || req.constraint == "" {
+					return nil, fmt.Errorf("%w: line %d: invalid spec %q", dep_errors.ErrInvalidLockfile, lineNo, strings.TrimSpace(line))
+				}
+				// specs of native gems have the platform appended, e.g.
+				// "1.15.4-x86_64-linux", gem versions can't contain dashes
+				version, _, _ := strings.Cut(req.constraint, "-")
+				pkg := gemPackage(req.name, version)
+				if existing, ok := byName[req.name]; ok && existing.pkg == pkg {
+					// the same gem for another platform
+					spec = existing
+					continue
+				}
+				spec = &gemSpec{pkg: pkg, local: section == "PATH" && remote == "."}
+				specs = append(specs, spec)
+				if _, ok := byName[req.name]; !ok {
+					byName[req.name] = spec
+				}
+			case indent == 6 && spec != nil:
+				req, ok := parseGemRequirement(line)
+				if !ok {
+					return nil, fmt.Errorf("%w: line %d: invalid requirement %q", dep_errors.ErrInvalidLockfile, lineNo, strings.TrimSpace(line))
+				}
+				spec.deps = append(spec.deps, req)
+			}
+		case "DEPENDENCIES":
+			req, ok := parseGemRequirement(line)
+			if !ok {
+				return nil, fmt.Errorf("%w: line %d: invalid dependency %q", dep_errors.ErrInvalidLockfile, lineNo, strings.TrimSpace(line))
+			}
+			req.name = strings.TrimSuffix(req.name, "!")
+			rootDeps = append(rootDeps, req)
+		}
+		// PLATFORMS, RUBY VERSION, BUNDLED WITH and CHECKSUMS don't affect the graph
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidLockfile, err)
+	}
+	if !hasDependencies {
+		return nil, fmt.Errorf("%w: DEPENDENCIES section is missing", dep_errors.ErrInvalidLockfile)
+	}
+
+	root := gemPackage(project, "")
+	rootKind := gem.KindRuntime
+	for _, spec := range specs {
+		if spec.local {
+			root, rootKind = spec.pkg, gem.KindDevelopment
+			break
+		}
+	}
+	g := NewGraph(root)
+	// gems that aren't locked, e.g. for other platforms, have no version
+	lookup := func(name string) models.Package {
+		if spec, ok := byName[name]; ok {
+			return spec.pkg
+		}
+		return gemPackage(name, "")
+	}
+	for _, spec := range specs {
+		g.AddPackage(spec.pkg)
+		for _, dep := range spec.deps {
+			g.AddDependency(spec.pkg, models.Dependency{Package: lookup(dep.name), Constraint: dep.constraint, Kind: gem.KindRuntime})
+		}
+	}
+	for _, dep := range rootDeps {
+		pkg := lookup(dep.name)
+		if pkg == root {
+			continue
+		}
+		g.AddDependency(root, models.Dependency{Package: pkg, Constraint: dep.constraint, Kind: rootKind})
+	}
+	return g, nil
+}
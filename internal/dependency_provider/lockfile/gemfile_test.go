@@ -0,0 +1,118 @@
+package lockfile
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/fstest"
+)
+
+func gemPackageAt(name, version string) models.Package {
+	return models.Package{Ecosystem: models.Gem, Name: name, Version: version}
+}
+
+func TestParseGemfileLock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("test reads application lockfile", func(t *testing.T) {
+		g, err := parse(fstest.MapFS{"Gemfile.lock": {Data: []byte(`GIT
+  remote: https://github.com/rack/rack-session.git
+  revision: 0000000000000000000000000000000000000000
+  branch: main
+  specs:
+    rack-session (2.0.0)
+      rack (>= 3.0.0)
+
+GEM
+  remote: https://rubygems.org/
+  specs:
+    nokogiri (1.15.4-arm64-darwin)
+      racc (~> 1.4)
+    nokogiri (1.15.4-x86_64-linux)
+      racc (~> 1.4)
+    racc (1.7.1)
+    rack (3.0.8)
+    rspec (3.12.0)
+
+PLATFORMS
+  arm64-darwin
+  x86_64-linux
+
+DEPENDENCIES
+  nokogiri (~> 1.15)
+  rack-session!
+  rspec
+  tzinfo-data
+
+RUBY VERSION
+   ruby 3.2.2p53
+
+BUNDLED WITH
+   2.4.19
+`)}}, "Gemfile.lock", "app")
+		assert.NoError(t, err)
+		assert.Equal(t, gemPackageAt("app", ""), g.Root)
+
+		// tzinfo-data is only locked for windows
+		deps, err := g.FetchPackageDeps(ctx, g.Root)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: gemPackageAt("nokogiri", "1.15.4"), Constraint: "~> 1.15", Kind: "runtime"},
+			{Package: gemPackageAt("rack-session", "2.0.0"), Kind: "runtime"},
+			{Package: gemPackageAt("rspec", "3.12.0"), Kind: "runtime"},
+			{Package: gemPackageAt("tzinfo-data", ""), Kind: "runtime"},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, gemPackageAt("nokogiri", "1.15.4"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: gemPackageAt("racc", "1.7.1"), Constraint: "~> 1.4", Kind: "runtime"},
+		}, deps)
+	})
+
+	t.Run("test reads gem lockfile", func(t *testing.T) {
+		g, err := parse(fstest.MapFS{"Gemfile.lock": {Data: []byte(`PATH
+  remote: .
+  specs:
+    mygem (0.1.0)
+      rack (>= 2.0)
+
+GEM
+  remote: https://rubygems.org/
+  specs:
+    rack (3.0.8)
+    rake (13.1.0)
+
+PLATFORMS
+  ruby
+
+DEPENDENCIES
+  mygem!
+  rake (~> 13.0)
+
+BUNDLED WITH
+   2.4.19
+`)}}, "Gemfile.lock", "mygem")
+		assert.NoError(t, err)
+		assert.Equal(t, gemPackageAt("mygem", "0.1.0"), g.Root)
+
+		deps, err := g.FetchPackageDeps(ctx, g.Root)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: gemPackageAt("rack", "3.0.8"), Constraint: ">= 2.0", Kind: "runtime"},
+			{Package: gemPackageAt("rake", "13.1.0"), Constraint: "~> 13.0", Kind: "development"},
+		}, deps)
+	})
+
+	t.Run("test returns error for invalid lockfile", func(t *testing.T) {
+		for _, data := range []string{
+			"GEM\n  remote: https://rubygems.org/\n  specs:\n    rack\n\nDEPENDENCIES\n  rack\n",
+			"GEM\n  remote: https://rubygems.org/\n  specs:\n    rack (3.0.8)\n",
+		} {
+			_, err := parse(fstest.MapFS{"Gemfile.lock": {Data: []byte(data)}}, "Gemfile.lock", "app")
+			assert.ErrorIs(t, err, dep_errors.ErrInvalidLockfile)
+		}
+	})
+}
@@ -0,0 +1,332 @@
+package lockfile
+
+import (
+	"bufio"
+	"bytes"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/dependency_provider/gomod"
+	"depviz/internal/models"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+func goPackage(m gomod.ModuleVersion) models.Package {
+	return models.Package{Ecosystem: models.Golang, Name: m.Path, Version: m.Version}
+}
+
+// goRequirement is an edge of the module graph. The kind is empty if the
+// source doesn't tell direct and indirect requirements apart.
+type goRequirement struct {
+	gomod.ModuleVersion
+	kind string
+}
+
+// goRequirements returns requirements of a module version of the module graph.
+type goRequirements func(m gomod.ModuleVersion) []goRequirement
+
+// selectVersions applies minimal version selection: every module reachable
+// from the main module is selected at the highest version any reachable
+// module version requires.
+func selectVersions(main gomod.ModuleVersion, requirements goRequirements) map[string]string {
+	selected := map[string]string{main.Path: main.Version}
+	seen := map[gomod.ModuleVersion]struct{}{main: {}}
+	queue := []gomod.ModuleVersion{main}
+	for len(queue) != 0 {
+		m := queue[0]
+		queue = queue[1:]
+		for _, r := range requirements(m) {
+			// the main module is always selected, whatever others require
+			if r.Path == main.Path {
+				continue
+			}
+			if version, ok := selected[r.Path]; !ok || gomod.CompareVersions(r.Version, version) > 0 {
+				selected[r.Path] = r.Version
+			}
+			if _, ok := seen[r.ModuleVersion]; !ok {
+				seen[r.ModuleVersion] = struct{}{}
+				queue = append(queue, r.ModuleVersion)
+			}
+		}
+	}
+	return selected
+}
+
+// addGoModules adds requirements between selected module versions to the
+// graph, a requirement on an older version points to the selected one.
+// Modules that aren't selected are dropped.
+func addGoModules(g *Graph, main gomod.ModuleVersion, requirements goRequirements, selected map[string]string) {
+	seen := map[gomod.ModuleVersion]struct{}{main: {}}
+	queue := []gomod.ModuleVersion{main}
+	for len(queue) != 0 {
+		m := queue[0]
+		queue = queue[1:]
+		for _, r := range requirements(m) {
+			version, ok := selected[r.Path]
+			if !ok || r.Path == main.Path {
+				continue
+			}
+			to := gomod.ModuleVersion{Path: r.Path, Version: version}
+			g.AddDependency(goPackage(m), models.Dependency{Package: goPackage(to), Constraint: r.Version, Kind: r.kind})
+			if _, ok := seen[to]; !ok {
+				seen[to] = struct{}{}
+				queue = append(queue, to)
+			}
+		}
+	}
+}
+
+// ParseModGraph reads the output of "go mod graph". The main module is the
+// only one printed without a version.
+func ParseModGraph(r io.Reader) (*Graph, error) {
+	var main *gomod.ModuleVersion
+	graph := make(map[gomod.ModuleVersion][]goRequirement)
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w: line %d: expected a module and its requirement", dep_errors.ErrInvalidLockfile, lineNo)
+		}
+		from, to := parseModuleVersion(fields[0]), parseModuleVersion(fields[1])
+		// since go 1.21 the go version and the toolchain are printed as requirements
+		if to.Path == "go" || to.Path == "toolchain" || from.Path == "go" || from.Path == "toolchain" {
+			continue
+		}
+		if to.Version == "" {
+			return nil, fmt.Errorf("%w: line %d: requirement %s has no version", dep_errors.ErrInvalidLockfile, lineNo, to.Path)
+		}
+		if from.Version == "" && main == nil {
+			main = &from
+		}
+		graph[from] = append(graph[from], goRequirement{ModuleVersion: to})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("can't read module graph: %w", err)
+	}
+	if main == nil {
+		return nil, fmt.Errorf("%w: the main module is missing", dep_errors.ErrInvalidLockfile)
+	}
+
+	requirements := func(m gomod.ModuleVersion) []goRequirement {
+		return graph[m]
+	}
+	g := NewGraph(goPackage(*main))
+	addGoModules(g, *main, requirements, selectVersions(*main, requirements))
+	return g, nil
+}
+
+func parseModuleVersion(s string) gomod.ModuleVersion {
+	modulePath, version, _ := strings.Cut(s, "@")
+	return gomod.ModuleVersion{Path: modulePath, Version: version}
+}
+
+// goModules reads go.mod files of the module graph: the main one from the
+// project directory and those of dependencies from the module cache.
+type goModules struct {
+	main  *gomod.ModFile
+	fsys  fs.FS
+	cache fs.FS
+	// sums lists module versions of go.sum, nil if there is no go.sum
+	sums  map[gomod.ModuleVersion]struct{}
+	known map[gomod.ModuleVersion][]goRequirement
+}
+
+// requirements returns requirements of a module version. Replace and exclude
+// directives of the main module apply to the whole graph. A module whose
+// go.mod is neither in the project nor in the module cache has no requirements.
+func (m *goModules) requirements(mv gomod.ModuleVersion) []goRequirement {
+	if reqs, ok := m.known[mv]; ok {
+		return reqs
+	}
+	var modFile *gomod.ModFile
+	if mv.Path == m.main.Module {
+		modFile = m.main
+	} else if data, err := m.readModFile(m.main.Replacement(mv)); err == nil {
+		modFile, _ = gomod.ParseModFile(data)
+	}
+
+	var reqs []goRequirement
+	if modFile != nil {
+		for _, r := range modFile.Require {
+			if m.main.IsExcluded(r.ModuleVersion) {
+				continue
+			}
+			kind := gomod.KindDirect
+			if r.Indirect {
+				kind = gomod.KindIndirect
+			}
+			reqs = append(reqs, goRequirement{ModuleVersion: r.ModuleVersion, kind: kind})
+		}
+	}
+	m.known[mv] = reqs
+	return reqs
+}
+
+func (m *goModules) readModFile(mv gomod.ModuleVersion) ([]byte, error) {
+	if mv.Version == "" {
+		// a directory replacement, only those inside the project can be read
+		dir := path.Clean(filepath.ToSlash(mv.Path))
+		if !gomod.IsLocalPath(mv.Path) || !fs.ValidPath(dir) {
+			return nil, fs.ErrNotExist
+		}
+		return fs.ReadFile(m.fsys, path.Join(dir, "go.mod"))
+	}
+	// go.sum lists every go.mod the go command reads, so pruned parts of the
+	// graph stay pruned even if the module cache has their go.mod files
+	if _, ok := m.sums[mv]; m.sums != nil && !ok {
+		return nil, fs.ErrNotExist
+	}
+	if m.cache == nil {
+		return nil, fs.ErrNotExist
+	}
+	name, err := gomod.CachePath(mv)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(m.cache, name)
+}
+
+// newGoModules reads go.mod and go.sum of the project. project names the main
+// module if there is no go.mod.
+func newGoModules(fsys fs.FS, project string, cache fs.FS) (*goModules, error) {
+	m := &goModules{fsys: fsys, cache: cache, known: make(map[gomod.ModuleVersion][]goRequirement)}
+	data, err := fs.ReadFile(fsys, "go.mod")
+	if errors.Is(err, fs.ErrNotExist) {
+		m.main = &gomod.ModFile{Module: project}
+	} else if err != nil {
+		return nil, err
+	} else if m.main, err = gomod.ParseModFile(data); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidLockfile, err)
+	} else if m.main.Module == "" {
+		return nil, fmt.Errorf("%w: go.mod has no module directive", dep_errors.ErrInvalidLockfile)
+	}
+
+	data, err = fs.ReadFile(fsys, "go.sum")
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	} else if err != nil {
+		return nil, err
+	}
+	m.sums = make(map[gomod.ModuleVersion]struct{})
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		// module version[/go.mod] hash
+		if fields := strings.Fields(scanner.Text()); len(fields) == 3 {
+			version := strings.TrimSuffix(fields[1], "/go.mod")
+			m.sums[gomod.ModuleVersion{Path: fields[0], Version: version}] = struct{}{}
+		}
+	}
+	return m, nil
+}
+
+// moduleCache returns the download directory of the module cache.
+func moduleCache() fs.FS {
+	dir := os.Getenv("GOMODCACHE")
+	if dir == "" {
+		gopath := os.Getenv("GOPATH")
+		if gopath == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil
+			}
+			gopath = filepath.Join(home, "go")
+		}
+		dir = filepath.Join(filepath.SplitList(gopath)[0], "pkg", "mod")
+	}
+	return os.DirFS(filepath.Join(dir, "cache", "download"))
+}
+
+// parseGoMod reads go.mod of the project. Requirements of dependencies are
+// read from go.mod files in the module cache, then minimal version selection
+// drops the versions the go command wouldn't build with.
+func parseGoMod(fsys fs.FS, cache fs.FS) (*Graph, error) {
+	modules, err := newGoModules(fsys, "", cache)
+	if err != nil {
+		return nil, err
+	}
+	main := gomod.ModuleVersion{Path: modules.main.Module}
+	g := NewGraph(goPackage(main))
+	addGoModules(g, main, modules.requirements, selectVersions(main, modules.requirements))
+	return g, nil
+}
+
+// parseModulesTxt reads vendor/modules.txt which lists the selected version
+// of every module. Requirements between them are read from the module cache,
+// vendored modules nothing requires are drawn as indirect requirements of the
+// main module.
+func parseModulesTxt(fsys fs.FS, data []byte, project string, cache fs.FS) (*Graph, error) {
+	modules, err := newGoModules(fsys, project, cache)
+	if err != nil {
+		return nil, err
+	}
+	indirect := make(map[string]bool, len(modules.main.Require))
+	for _, r := range modules.main.Require {
+		indirect[r.Path] = r.Indirect
+	}
+
+	main := gomod.ModuleVersion{Path: modules.main.Module}
+	selected := map[string]string{main.Path: ""}
+	var vendored []gomod.ModuleVersion
+	var explicit []goRequirement
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "## "):
+			// ## explicit; go 1.21 marks requirements of go.mod
+			if len(vendored) == 0 {
+				continue
+			}
+			for _, marker := range strings.Split(line[3:], ";") {
+				if strings.TrimSpace(marker) != "explicit" {
+					continue
+				}
+				last := vendored[len(vendored)-1]
+				kind := gomod.KindDirect
+				if indirect[last.Path] {
+					kind = gomod.KindIndirect
+				}
+				explicit = append(explicit, goRequirement{ModuleVersion: last, kind: kind})
+			}
+		case strings.HasPrefix(line, "# "):
+			// # module version [=> replacement [version]], replacements of
+			// modules that aren't required have no version
+			fields := strings.Fields(line[2:])
+			if len(fields) < 2 || fields[1] == "=>" {
+				continue
+			}
+			m := gomod.ModuleVersion{Path: fields[0], Version: fields[1]}
+			vendored = append(vendored, m)
+			selected[m.Path] = m.Version
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidLockfile, err)
+	}
+
+	requirements := func(m gomod.ModuleVersion) []goRequirement {
+		if m == main {
+			return explicit
+		}
+		return modules.requirements(m)
+	}
+	reachable := selectVersions(main, requirements)
+	for _, m := range vendored {
+		if _, ok := reachable[m.Path]; !ok {
+			explicit = append(explicit, goRequirement{ModuleVersion: m, kind: gomod.KindIndirect})
+		}
+	}
+	g := NewGraph(goPackage(main))
+	addGoModules(g, main, requirements, selected)
+	return g, nil
+}
@@ -0,0 +1,200 @@
+package lockfile
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func goPackageAt(name, version string) models.Package {
+	return models.Package{Ecosystem: models.Golang, Name: name, Version: version}
+}
+
+func TestParseGoMod(t *testing.T) {
+	ctx := context.Background()
+	fsys := fstest.MapFS{
+		"go.mod": {Data: []byte(`module example.com/app
+
+go 1.21
+
+require (
+	example.com/a v1.1.0
+	example.com/b v1.0.0
+	example.com/c v1.2.0 // indirect
+	example.com/local v0.0.0
+)
+
+replace example.com/local => ./local
+
+exclude example.com/d v1.0.0
+`)},
+		"go.sum": {Data: []byte(`example.com/a v1.1.0 h1:0000=
+example.com/a v1.1.0/go.mod h1:0000=
+example.com/b v1.0.0/go.mod h1:0000=
+example.com/c v1.2.0/go.mod h1:0000=
+example.com/c v1.3.0/go.mod h1:0000=
+github.com/Masterminds/semver v1.5.0/go.mod h1:0000=
+`)},
+		"local/go.mod": {Data: []byte("module example.com/local\n\nrequire github.com/Masterminds/semver v1.5.0\n")},
+	}
+	cache := fstest.MapFS{
+		"example.com/a/@v/v1.1.0.mod": {Data: []byte(`module example.com/a
+
+require (
+	example.com/c v1.1.0
+	example.com/d v1.0.0
+	example.com/e v1.0.0 // indirect
+)
+`)},
+		"example.com/b/@v/v1.0.0.mod": {Data: []byte("module example.com/b\n\nrequire example.com/c v1.3.0\n")},
+		"example.com/c/@v/v1.2.0.mod": {Data: []byte("module example.com/c\n\nrequire example.com/f v1.0.0\n")},
+		"example.com/c/@v/v1.3.0.mod": {Data: []byte("module example.com/c\n\nrequire example.com/app v0.1.0\n")},
+		// go.sum doesn't list it, the go command doesn't read it
+		"example.com/e/@v/v1.0.0.mod":                  {Data: []byte("module example.com/e\n\nrequire example.com/f v1.0.0\n")},
+		"github.com/!masterminds/semver/@v/v1.5.0.mod": {Data: []byte("module github.com/Masterminds/semver\n")},
+	}
+	g, err := parseGoMod(fsys, cache)
+	assert.NoError(t, err)
+	assert.Equal(t, goPackageAt("example.com/app", ""), g.Root)
+
+	deps, err := g.FetchPackageDeps(ctx, g.Root)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Dependency{
+		{Package: goPackageAt("example.com/a", "v1.1.0"), Constraint: "v1.1.0", Kind: "direct"},
+		{Package: goPackageAt("example.com/b", "v1.0.0"), Constraint: "v1.0.0", Kind: "direct"},
+		{Package: goPackageAt("example.com/c", "v1.3.0"), Constraint: "v1.2.0", Kind: "indirect"},
+		{Package: goPackageAt("example.com/local", "v0.0.0"), Constraint: "v0.0.0", Kind: "direct"},
+	}, deps)
+
+	deps, err = g.FetchPackageDeps(ctx, goPackageAt("example.com/a", "v1.1.0"))
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Dependency{
+		{Package: goPackageAt("example.com/c", "v1.3.0"), Constraint: "v1.1.0", Kind: "direct"},
+		{Package: goPackageAt("example.com/e", "v1.0.0"), Constraint: "v1.0.0", Kind: "indirect"},
+	}, deps)
+
+	deps, err = g.FetchPackageDeps(ctx, goPackageAt("example.com/local", "v0.0.0"))
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Dependency{
+		{Package: goPackageAt("github.com/Masterminds/semver", "v1.5.0"), Constraint: "v1.5.0", Kind: "direct"},
+	}, deps)
+
+	// requirements of the main module and of versions that aren't selected are dropped
+	for _, spec := range []string{"example.com/c@v1.2.0", "example.com/f", "example.com/d"} {
+		_, err = g.Resolve(ctx, spec)
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+	}
+	deps, err = g.FetchPackageDeps(ctx, goPackageAt("example.com/c", "v1.3.0"))
+	assert.NoError(t, err)
+	assert.Empty(t, deps)
+
+	_, err = parseGoMod(fstest.MapFS{"go.mod": {Data: []byte("go 1.21\n")}}, cache)
+	assert.ErrorIs(t, err, dep_errors.ErrInvalidLockfile)
+}
+
+func TestParseModGraph(t *testing.T) {
+	ctx := context.Background()
+	g, err := ParseModGraph(strings.NewReader(`example.com/app example.com/a@v1.0.0
+example.com/app example.com/b@v1.0.0
+example.com/app go@1.21
+example.com/a@v1.0.0 example.com/c@v1.1.0
+example.com/b@v1.0.0 example.com/c@v1.2.0
+example.com/c@v1.1.0 example.com/d@v1.0.0
+example.com/c@v1.2.0 go@1.20
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, goPackageAt("example.com/app", ""), g.Root)
+
+	deps, err := g.FetchPackageDeps(ctx, goPackageAt("example.com/a", "v1.0.0"))
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Dependency{
+		{Package: goPackageAt("example.com/c", "v1.2.0"), Constraint: "v1.1.0"},
+	}, deps)
+
+	deps, err = g.FetchPackageDeps(ctx, goPackageAt("example.com/c", "v1.2.0"))
+	assert.NoError(t, err)
+	assert.Empty(t, deps)
+
+	_, err = g.Resolve(ctx, "example.com/c@v1.1.0")
+	assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+
+	_, err = ParseModGraph(strings.NewReader("example.com/a@v1.0.0 example.com/c@v1.1.0\n"))
+	assert.ErrorIs(t, err, dep_errors.ErrInvalidLockfile)
+	_, err = ParseModGraph(strings.NewReader("example.com/app\n"))
+	assert.ErrorIs(t, err, dep_errors.ErrInvalidLockfile)
+}
+
+func TestParseModulesTxt(t *testing.T) {
+	ctx := context.Background()
+	modulesTxt := `# example.com/a v1.1.0
+## explicit; go 1.20
+example.com/a
+# example.com/c v1.3.0
+## explicit
+example.com/c/pkg
+# example.com/x v0.1.0
+example.com/x
+# example.com/old => ./old
+`
+	fsys := fstest.MapFS{
+		"go.mod":             {Data: []byte("module example.com/app\n\nrequire (\n\texample.com/a v1.1.0\n\texample.com/c v1.3.0 // indirect\n)\n")},
+		"vendor/modules.txt": {Data: []byte(modulesTxt)},
+	}
+	cache := fstest.MapFS{
+		"example.com/a/@v/v1.1.0.mod": {Data: []byte("module example.com/a\n\nrequire example.com/c v1.1.0\n")},
+	}
+	g, err := parseModulesTxt(fsys, []byte(modulesTxt), "project", cache)
+	assert.NoError(t, err)
+	assert.Equal(t, goPackageAt("example.com/app", ""), g.Root)
+
+	// x is vendored, but no requirement is known
+	deps, err := g.FetchPackageDeps(ctx, g.Root)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Dependency{
+		{Package: goPackageAt("example.com/a", "v1.1.0"), Constraint: "v1.1.0", Kind: "direct"},
+		{Package: goPackageAt("example.com/c", "v1.3.0"), Constraint: "v1.3.0", Kind: "indirect"},
+		{Package: goPackageAt("example.com/x", "v0.1.0"), Constraint: "v0.1.0", Kind: "indirect"},
+	}, deps)
+
+	deps, err = g.FetchPackageDeps(ctx, goPackageAt("example.com/a", "v1.1.0"))
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Dependency{
+		{Package: goPackageAt("example.com/c", "v1.3.0"), Constraint: "v1.1.0", Kind: "direct"},
+	}, deps)
+
+	g, err = parseModulesTxt(fstest.MapFS{}, []byte(modulesTxt), "project", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, goPackageAt("project", ""), g.Root)
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "vendor"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "vendor", "modules.txt"), []byte("# example.com/a v1.0.0\n## explicit\n"), 0o644))
+
+	t.Run("test finds lockfile in directory", func(t *testing.T) {
+		g, err := ReadFile(dir)
+		assert.NoError(t, err)
+		assert.Equal(t, goPackageAt("example.com/app", ""), g.Root)
+	})
+
+	t.Run("test reads modules.txt with go.mod of the module", func(t *testing.T) {
+		g, err := ReadFile(filepath.Join(dir, "vendor", "modules.txt"))
+		assert.NoError(t, err)
+		assert.Equal(t, goPackageAt("example.com/app", ""), g.Root)
+		_, err = g.Resolve(context.Background(), "example.com/a@v1.0.0")
+		assert.NoError(t, err)
+	})
+
+	t.Run("test returns error for directory without lockfile", func(t *testing.T) {
+		_, err := ReadFile(t.TempDir())
+		assert.ErrorIs(t, err, dep_errors.ErrInvalidLockfile)
+	})
+}
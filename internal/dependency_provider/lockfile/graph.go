@@ -0,0 +1,244 @@
+package lockfile
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/cargo"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/dependency_provider/gem"
+	"depviz/internal/dependency_provider/npm"
+	"depviz/internal/models"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// KindWorkspace marks edges from the root of a monorepo to its workspaces.
+// They are traversed whatever Graph.Kinds are.
+const KindWorkspace = "workspace"
+
+// Kinds of python lockfiles, named like the npm kinds so -kinds selects
+// them the same way.
+const (
+	KindProd = "prod"
+	KindDev  = "dev"
+)
+
+// devKinds are kinds of development dependencies in all lockfiles.
+var devKinds = []string{KindDev, npm.KindDev, cargo.KindDev, gem.KindDevelopment}
+
+// Graph is a dependency graph read from a lockfile. It provides dependencies
+// without registry access.
+type Graph struct {
+	Root models.Package
+	// Kinds lists dependency kinds that are traversed. All kinds are traversed if it is empty.
+	Kinds []string
+
+	deps map[models.Package][]models.Dependency
+}
+
+func NewGraph(root models.Package) *Graph {
+	g := &Graph{Root: root, deps: make(map[models.Package][]models.Dependency)}
+	g.AddPackage(root)
+	return g
+}
+
+// AddPackage adds a package without dependencies to the graph.
+func (g *Graph) AddPackage(pkg models.Package) {
+	if _, ok := g.deps[pkg]; !ok {
+		g.deps[pkg] = nil
+	}
+}
+
+// AddDependency adds an edge to the graph. A package is a dependency of
+// another one at most once for every kind. Workspace edges are only kept
+// if the root doesn't declare a dependency on the workspace.
+func (g *Graph) AddDependency(from models.Package, dep models.Dependency) {
+	g.AddPackage(from)
+	g.AddPackage(dep.Package)
+	for i, existing := range g.deps[from] {
+		if existing.Package != dep.Package {
+			continue
+		}
+		if existing.Kind == dep.Kind || dep.Kind == KindWorkspace {
+			return
+		}
+		if existing.Kind == KindWorkspace {
+			g.deps[from][i] = dep
+			return
+		}
+	}
+	g.deps[from] = append(g.deps[from], dep)
+}
+
+// Packages returns all packages of the graph sorted by name and version.
+func (g *Graph) Packages() []models.Package {
+	result := make([]models.Package, 0, len(g.deps))
+	for pkg := range g.deps {
+		result = append(result, pkg)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].Version < result[j].Version
+	})
+	return result
+}
+
+// Resolve returns the root of the lockfile if spec is empty. Otherwise it
+// looks up a package given as "name" or "name@version" to draw its subtree.
+func (g *Graph) Resolve(_ context.Context, spec string) (models.Package, error) {
+	if spec == "" {
+		return g.Root, nil
+	}
+	name, version := spec, ""
+	if i := strings.LastIndex(spec, "@"); i > 0 {
+		name, version = spec[:i], spec[i+1:]
+	}
+
+	var found []models.Package
+	for _, pkg := range g.Packages() {
+		if pkg.Name == name && (version == "" || pkg.Version == version) {
+			found = append(found, pkg)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return models.Package{}, fmt.Errorf("%w: %s is not in the lockfile", dep_errors.ErrPackageNotFound, spec)
+	case 1:
+		return found[0], nil
+	}
+	versions := make([]string, 0, len(found))
+	for _, pkg := range found {
+		versions = append(versions, pkg.Version)
+	}
+	return models.Package{}, fmt.Errorf("%w: %s is locked at several versions (%s), specify one of them",
+		dep_errors.ErrInvalidPackageName, name, strings.Join(versions, ", "))
+}
+
+func (g *Graph) FetchPackageDeps(_ context.Context, pkg models.Package) ([]models.Dependency, error) {
+	deps, ok := g.deps[pkg]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not in the lockfile", dep_errors.ErrPackageNotFound, pkg)
+	}
+	if len(g.Kinds) == 0 {
+		return deps, nil
+	}
+	result := make([]models.Dependency, 0, len(deps))
+	for _, dep := range deps {
+		if dep.Kind == KindWorkspace || contains(g.Kinds, dep.Kind) {
+			result = append(result, dep)
+		}
+	}
+	return result, nil
+}
+
+// DefaultKinds returns the kinds found in the graph but those of development
+// dependencies, which registry providers leave out unless they are asked for.
+// Workspaces are always listed, so the result is never empty.
+func (g *Graph) DefaultKinds() []string {
+	seen := map[string]struct{}{KindWorkspace: {}}
+	result := []string{KindWorkspace}
+	for _, deps := range g.deps {
+		for _, dep := range deps {
+			if _, ok := seen[dep.Kind]; ok || contains(devKinds, dep.Kind) {
+				continue
+			}
+			seen[dep.Kind] = struct{}{}
+			result = append(result, dep.Kind)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// lockfileNames lists supported lockfiles in the order they are looked up in
+// a project directory.
+var lockfileNames = []string{
+	"package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml",
+	"poetry.lock", "Pipfile.lock", "uv.lock", "Cargo.lock", "Gemfile.lock", "go.mod",
+	"vendor/modules.txt",
+}
+
+// ReadFile reads a lockfile, its format is detected by the file name. If path
+// is a directory the first lockfile found in it is read. Manifests of the
+// project next to the lockfile are read too if the lockfile lacks package
+// names or declared dependencies.
+func ReadFile(path string) (*Graph, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("can't read lockfile: %w", err)
+	}
+
+	name := ""
+	if info.IsDir() {
+		fsys := os.DirFS(dir)
+		for _, candidate := range lockfileNames {
+			if _, err := fs.Stat(fsys, candidate); err == nil {
+				name = candidate
+				break
+			}
+		}
+		if name == "" {
+			return nil, fmt.Errorf("%w: no lockfile in %s", dep_errors.ErrInvalidLockfile, path)
+		}
+	} else {
+		dir, name = filepath.Dir(dir), filepath.Base(dir)
+		// modules.txt is read along with go.mod of the vendoring module
+		if name == "modules.txt" && filepath.Base(dir) == "vendor" {
+			dir, name = filepath.Dir(dir), "vendor/modules.txt"
+		}
+	}
+	return parse(os.DirFS(dir), name, filepath.Base(dir))
+}
+
+// parse reads the lockfile name from the project directory fsys. project names
+// the root package if neither the lockfile nor a manifest does.
+func parse(fsys fs.FS, name string, project string) (*Graph, error) {
+	if !contains(lockfileNames, name) {
+		return nil, fmt.Errorf("%w: unsupported lockfile %s", dep_errors.ErrInvalidLockfile, name)
+	}
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("can't read lockfile: %w", err)
+	}
+
+	switch name {
+	case "yarn.lock":
+		return parseYarnLock(fsys, data, project)
+	case "pnpm-lock.yaml":
+		return parsePnpmLock(fsys, data, project)
+	case "poetry.lock":
+		return parsePoetryLock(fsys, data, project)
+	case "Pipfile.lock":
+		return parsePipfileLock(data, project)
+	case "uv.lock":
+		return parseUvLock(data, project)
+	case "Cargo.lock":
+		return parseCargoLock(fsys, data, project)
+	case "Gemfile.lock":
+		return parseGemfileLock(data, project)
+	case "go.mod":
+		return parseGoMod(fsys, moduleCache())
+	case "vendor/modules.txt":
+		return parseModulesTxt(fsys, data, project, moduleCache())
+	default:
+		return parsePackageLock(fsys, data)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
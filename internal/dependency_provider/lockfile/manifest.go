@@ -0,0 +1,78 @@
+package lockfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+)
+
+// workspaceGlobs is the "workspaces" field of package.json, either a list of
+// globs or an object with the globs in "packages".
+type workspaceGlobs []string
+
+func (w *workspaceGlobs) UnmarshalJSON(data []byte) error {
+	var globs []string
+	if err := json.Unmarshal(data, &globs); err == nil {
+		*w = globs
+		return nil
+	}
+	var object struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	*w = object.Packages
+	return nil
+}
+
+// readManifest reads package.json of the project directory dir. It returns
+// nil if there is no manifest.
+func readManifest(fsys fs.FS, dir string) (*packageEntry, error) {
+	data, err := fs.ReadFile(fsys, path.Join(dir, "package.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var m packageEntry
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("can't parse %s: %w", path.Join(dir, "package.json"), err)
+	}
+	return &m, nil
+}
+
+// workspaceDirs expands workspace globs of the root manifest to directories
+// containing the manifest file, e.g. package.json.
+func workspaceDirs(fsys fs.FS, globs []string, manifest string) ([]string, error) {
+	var dirs []string
+	seen := make(map[string]struct{})
+	for _, glob := range globs {
+		matches, err := fs.Glob(fsys, path.Join(glob, manifest))
+		if err != nil {
+			return nil, fmt.Errorf("invalid workspace %q: %w", glob, err)
+		}
+		for _, match := range matches {
+			dir := path.Dir(match)
+			if _, ok := seen[dir]; !ok {
+				seen[dir] = struct{}{}
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// isWorkspace reports whether dir is matched by one of the workspace globs.
+func isWorkspace(globs []string, dir string) bool {
+	for _, glob := range globs {
+		if ok, _ := path.Match(path.Clean(glob), dir); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,346 @@
+package lockfile
+
+import (
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/dependency_provider/npm"
+	"depviz/internal/models"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// packageEntry is an entry of "packages" in lockfileVersion 2 and 3, keyed by
+// its location such as "node_modules/a/node_modules/b". The root is keyed by "".
+type packageEntry struct {
+	Name                 string                     `json:"name"`
+	Version              string                     `json:"version"`
+	Resolved             string                     `json:"resolved"`
+	Link                 bool                       `json:"link"`
+	Dependencies         map[string]string          `json:"dependencies"`
+	OptionalDependencies map[string]string          `json:"optionalDependencies"`
+	PeerDependencies     map[string]string          `json:"peerDependencies"`
+	PeerDependenciesMeta map[string]json.RawMessage `json:"peerDependenciesMeta"`
+	DevDependencies      map[string]string          `json:"devDependencies"`
+	Workspaces           workspaceGlobs             `json:"workspaces"`
+}
+
+// legacyEntry is an entry of the nested "dependencies" tree of lockfileVersion 1.
+type legacyEntry struct {
+	Version      string                  `json:"version"`
+	Dev          bool                    `json:"dev"`
+	Optional     bool                    `json:"optional"`
+	Requires     map[string]string       `json:"requires"`
+	Dependencies map[string]*legacyEntry `json:"dependencies"`
+}
+
+// ParsePackageLock reads package-lock.json or npm-shrinkwrap.json. The
+// "packages" section is used if it is present, otherwise the nested
+// "dependencies" tree of lockfileVersion 1.
+func ParsePackageLock(data []byte) (*Graph, error) {
+	return parsePackageLock(nil, data)
+}
+
+// parsePackageLock reads a package lock from the project directory fsys,
+// which may be nil. lockfileVersion 1 doesn't record dependencies of the
+// root, they are read from package.json if there is one.
+func parsePackageLock(fsys fs.FS, data []byte) (*Graph, error) {
+	var schema struct {
+		Name            string                   `json:"name"`
+		Version         string                   `json:"version"`
+		LockfileVersion int                      `json:"lockfileVersion"`
+		Packages        map[string]*packageEntry `json:"packages"`
+		Dependencies    json.RawMessage          `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidLockfile, err)
+	}
+	if schema.LockfileVersion == 0 {
+		return nil, fmt.Errorf("%w: lockfileVersion is missing", dep_errors.ErrInvalidLockfile)
+	}
+	root := models.Package{Ecosystem: models.Npm, Name: schema.Name, Version: schema.Version}
+
+	if schema.Packages != nil {
+		return parsePackages(root, schema.Packages), nil
+	}
+	var deps map[string]*legacyEntry
+	if len(schema.Dependencies) != 0 {
+		if err := json.Unmarshal(schema.Dependencies, &deps); err != nil {
+			return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidLockfile, err)
+		}
+	}
+	var manifest *packageEntry
+	if fsys != nil {
+		var err error
+		if manifest, err = readManifest(fsys, "."); err != nil {
+			return nil, err
+		}
+	}
+	return parseLegacyDependencies(root, deps, manifest), nil
+}
+
+// parentLocation returns the location whose node_modules holds the package at location.
+func parentLocation(location string) string {
+	i := strings.LastIndex(location, "node_modules/")
+	if i < 0 {
+		// workspaces are linked from the root node_modules
+		return ""
+	}
+	return strings.TrimSuffix(location[:i], "/")
+}
+
+func lookupPackage(packages map[string]*packageEntry, from string, name string) (string, *packageEntry) {
+	for dir := from; ; dir = parentLocation(dir) {
+		location := path.Join(dir, "node_modules", name)
+		if entry, ok := packages[location]; ok {
+			if entry.Link {
+				return entry.Resolved, packages[entry.Resolved]
+			}
+			return location, entry
+		}
+		if dir == "" {
+			return "", nil
+		}
+	}
+}
+
+// locationName returns the name of a package installed at location.
+func locationName(location string, entry *packageEntry) string {
+	if entry.Name != "" {
+		return entry.Name
+	}
+	if i := strings.LastIndex(location, "node_modules/"); i >= 0 {
+		return location[i+len("node_modules/"):]
+	}
+	return path.Base(location)
+}
+
+func parsePackages(root models.Package, packages map[string]*packageEntry) *Graph {
+	var workspaces workspaceGlobs
+	if entry, ok := packages[""]; ok {
+		if entry.Name != "" {
+			root.Name = entry.Name
+		}
+		if entry.Version != "" {
+			root.Version = entry.Version
+		}
+		workspaces = entry.Workspaces
+	}
+	g := NewGraph(root)
+
+	locations := make([]string, 0, len(packages))
+	for location := range packages {
+		locations = append(locations, location)
+	}
+	sort.Strings(locations)
+
+	packageAt := func(location string, entry *packageEntry) models.Package {
+		if location == "" {
+			return root
+		}
+		return models.Package{Ecosystem: models.Npm, Name: locationName(location, entry), Version: entry.Version}
+	}
+
+	for _, location := range locations {
+		entry := packages[location]
+		if entry == nil || entry.Link {
+			continue
+		}
+		from := packageAt(location, entry)
+		g.AddPackage(from)
+		if location != "" && !strings.Contains(location, "node_modules/") && isWorkspace(workspaces, location) {
+			g.AddDependency(root, models.Dependency{Package: from, Kind: KindWorkspace})
+		}
+
+		for _, declared := range declaredDependencies(entry) {
+			targetLocation, target := lookupPackage(packages, location, declared.name)
+			if target == nil {
+				// optional dependencies may be missing on this platform
+				if declared.optional {
+					continue
+				}
+				g.AddDependency(from, models.Dependency{
+					Package:    models.Package{Ecosystem: models.Npm, Name: declared.name},
+					Constraint: declared.constraint,
+					Kind:       declared.kind,
+				})
+				continue
+			}
+			g.AddDependency(from, models.Dependency{
+				Package:    packageAt(targetLocation, target),
+				Constraint: declared.constraint,
+				Kind:       declared.kind,
+				Optional:   declared.optional,
+			})
+		}
+	}
+	return g
+}
+
+type declaredDependency struct {
+	name       string
+	constraint string
+	kind       string
+	optional   bool
+}
+
+// declaredDependencies lists dependencies of an entry sorted by name. A package
+// listed under several kinds keeps the first of prod, optional, peer and dev.
+func declaredDependencies(entry *packageEntry) []declaredDependency {
+	peerOptional := func(name string) bool {
+		var meta struct {
+			Optional bool `json:"optional"`
+		}
+		_ = json.Unmarshal(entry.PeerDependenciesMeta[name], &meta)
+		return meta.Optional
+	}
+
+	seen := make(map[string]struct{})
+	var result []declaredDependency
+	add := func(deps map[string]string, kind string) {
+		names := make([]string, 0, len(deps))
+		for name := range deps {
+			if _, ok := seen[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			seen[name] = struct{}{}
+			result = append(result, declaredDependency{
+				name:       name,
+				constraint: deps[name],
+				kind:       kind,
+				optional:   kind == npm.KindOptional || kind == npm.KindPeer && peerOptional(name),
+			})
+		}
+	}
+	// optionalDependencies are also listed in dependencies
+	prod := make(map[string]string, len(entry.Dependencies))
+	for name, constraint := range entry.Dependencies {
+		if _, ok := entry.OptionalDependencies[name]; !ok {
+			prod[name] = constraint
+		}
+	}
+	add(prod, npm.KindProd)
+	add(entry.OptionalDependencies, npm.KindOptional)
+	add(entry.PeerDependencies, npm.KindPeer)
+	add(entry.DevDependencies, npm.KindDev)
+	return result
+}
+
+// parseLegacyDependencies reads the dependency tree of lockfileVersion 1. It
+// does not record dependencies of the root, they are taken from the manifest.
+// Without one top level packages that no other package requires are treated
+// as direct dependencies of the root.
+func parseLegacyDependencies(root models.Package, deps map[string]*legacyEntry, manifest *packageEntry) *Graph {
+	if manifest != nil && root.Name == "" {
+		root.Name, root.Version = manifest.Name, manifest.Version
+	}
+	g := NewGraph(root)
+	required := make(map[string]struct{})
+
+	var walk func(scopes []map[string]*legacyEntry)
+	walk = func(scopes []map[string]*legacyEntry) {
+		current := scopes[len(scopes)-1]
+		names := make([]string, 0, len(current))
+		for name := range current {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			entry := current[name]
+			from := models.Package{Ecosystem: models.Npm, Name: name, Version: entry.Version}
+			g.AddPackage(from)
+
+			childScopes := scopes
+			if len(entry.Dependencies) != 0 {
+				childScopes = append(append([]map[string]*legacyEntry(nil), scopes...), entry.Dependencies)
+			}
+			requires := make([]string, 0, len(entry.Requires))
+			for dep := range entry.Requires {
+				requires = append(requires, dep)
+			}
+			sort.Strings(requires)
+			for _, dep := range requires {
+				required[dep] = struct{}{}
+				target := lookupLegacy(childScopes, dep)
+				if target == nil {
+					continue
+				}
+				g.AddDependency(from, models.Dependency{
+					Package:    models.Package{Ecosystem: models.Npm, Name: dep, Version: target.Version},
+					Constraint: entry.Requires[dep],
+					Kind:       legacyKind(target),
+					Optional:   target.Optional,
+				})
+			}
+			if len(entry.Dependencies) != 0 {
+				walk(childScopes)
+			}
+		}
+	}
+	if len(deps) != 0 {
+		walk([]map[string]*legacyEntry{deps})
+	}
+
+	if manifest != nil {
+		for _, declared := range declaredDependencies(manifest) {
+			entry, ok := deps[declared.name]
+			if !ok && declared.optional {
+				// optional dependencies may be missing on this platform
+				continue
+			}
+			dep := models.Dependency{
+				Package:    models.Package{Ecosystem: models.Npm, Name: declared.name},
+				Constraint: declared.constraint,
+				Kind:       declared.kind,
+				Optional:   declared.optional,
+			}
+			if ok {
+				dep.Package.Version = entry.Version
+			}
+			g.AddDependency(root, dep)
+		}
+		return g
+	}
+
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		if _, ok := required[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		entry := deps[name]
+		g.AddDependency(root, models.Dependency{
+			Package:  models.Package{Ecosystem: models.Npm, Name: name, Version: entry.Version},
+			Kind:     legacyKind(entry),
+			Optional: entry.Optional,
+		})
+	}
+	return g
+}
+
+func lookupLegacy(scopes []map[string]*legacyEntry, name string) *legacyEntry {
+	for i := len(scopes) - 1; i >= 0; i-- {
+		if entry, ok := scopes[i][name]; ok {
+			return entry
+		}
+	}
+	return nil
+}
+
+func legacyKind(entry *legacyEntry) string {
+	switch {
+	case entry.Dev:
+		return npm.KindDev
+	case entry.Optional:
+		return npm.KindOptional
+	}
+	return npm.KindProd
+}
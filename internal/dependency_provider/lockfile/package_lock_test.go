@@ -0,0 +1,284 @@
+package lockfile
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/fstest"
+)
+
+func npmPackage(name, version string) models.Package {
+	return models.Package{Ecosystem: models.Npm, Name: name, Version: version}
+}
+
+func TestParsePackageLock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("test parses nested node_modules of lockfileVersion 3", func(t *testing.T) {
+		data := []byte(`
+{
+	"name": "app",
+	"version": "1.0.0",
+	"lockfileVersion": 3,
+	"packages": {
+		"": {
+			"name": "app",
+			"version": "1.0.0",
+			"dependencies": {"a": "^1.0.0", "b": "^1.0.0"},
+			"devDependencies": {"tester": "*"}
+		},
+		"node_modules/a": {
+			"version": "1.2.0",
+			"dependencies": {"lodash": "^4.17.0"}
+		},
+		"node_modules/b": {
+			"version": "1.0.1",
+			"dependencies": {"lodash": "^3.10.0"}
+		},
+		"node_modules/b/node_modules/lodash": {"version": "3.10.1"},
+		"node_modules/lodash": {"version": "4.17.21"},
+		"node_modules/tester": {"version": "2.0.0", "dev": true}
+	}
+}
+`)
+		g, err := ParsePackageLock(data)
+		assert.NoError(t, err)
+		assert.Equal(t, npmPackage("app", "1.0.0"), g.Root)
+
+		root, err := g.Resolve(ctx, "")
+		assert.NoError(t, err)
+		deps, err := g.FetchPackageDeps(ctx, root)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("a", "1.2.0"), Constraint: "^1.0.0", Kind: "prod"},
+			{Package: npmPackage("b", "1.0.1"), Constraint: "^1.0.0", Kind: "prod"},
+			{Package: npmPackage("tester", "2.0.0"), Constraint: "*", Kind: "dev"},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, npmPackage("a", "1.2.0"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("lodash", "4.17.21"), Constraint: "^4.17.0", Kind: "prod"},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, npmPackage("b", "1.0.1"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("lodash", "3.10.1"), Constraint: "^3.10.0", Kind: "prod"},
+		}, deps)
+
+		g.Kinds = []string{"prod"}
+		deps, err = g.FetchPackageDeps(ctx, root)
+		assert.NoError(t, err)
+		assert.Len(t, deps, 2)
+
+		// dev dependencies are left out by default like the registry does
+		assert.Equal(t, []string{"prod", "workspace"}, g.DefaultKinds())
+	})
+
+	t.Run("test parses optional, peer and missing dependencies", func(t *testing.T) {
+		data := []byte(`
+{
+	"lockfileVersion": 2,
+	"packages": {
+		"": {
+			"name": "app",
+			"dependencies": {"plugin": "^1.0.0", "fsevents": "^2.0.0", "gone": "^1.0.0"},
+			"optionalDependencies": {"fsevents": "^2.0.0"}
+		},
+		"node_modules/plugin": {
+			"version": "1.0.0",
+			"peerDependencies": {"host": "^2.0.0", "types": "*"},
+			"peerDependenciesMeta": {"types": {"optional": true}}
+		},
+		"node_modules/host": {"version": "2.1.0", "peer": true}
+	}
+}
+`)
+		g, err := ParsePackageLock(data)
+		assert.NoError(t, err)
+		assert.Equal(t, npmPackage("app", ""), g.Root)
+
+		deps, err := g.FetchPackageDeps(ctx, g.Root)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("gone", ""), Constraint: "^1.0.0", Kind: "prod"},
+			{Package: npmPackage("plugin", "1.0.0"), Constraint: "^1.0.0", Kind: "prod"},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, npmPackage("plugin", "1.0.0"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("host", "2.1.0"), Constraint: "^2.0.0", Kind: "peer"},
+		}, deps)
+	})
+
+	t.Run("test follows workspace links", func(t *testing.T) {
+		data := []byte(`
+{
+	"lockfileVersion": 3,
+	"packages": {
+		"": {"name": "monorepo", "workspaces": ["packages/*"]},
+		"../shared": {"name": "shared", "version": "1.0.0"},
+		"node_modules/@scope/core": {"resolved": "packages/core", "link": true},
+		"node_modules/@scope/web": {"resolved": "packages/web", "link": true},
+		"node_modules/react": {"version": "18.2.0"},
+		"packages/core": {"name": "@scope/core", "version": "0.1.0"},
+		"packages/web": {
+			"name": "@scope/web",
+			"version": "0.2.0",
+			"dependencies": {"@scope/core": "*", "react": "^18.0.0"}
+		}
+	}
+}
+`)
+		g, err := ParsePackageLock(data)
+		assert.NoError(t, err)
+
+		deps, err := g.FetchPackageDeps(ctx, g.Root)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("@scope/core", "0.1.0"), Kind: KindWorkspace},
+			{Package: npmPackage("@scope/web", "0.2.0"), Kind: KindWorkspace},
+		}, deps)
+
+		web, err := g.Resolve(ctx, "@scope/web")
+		assert.NoError(t, err)
+		assert.Equal(t, npmPackage("@scope/web", "0.2.0"), web)
+
+		deps, err = g.FetchPackageDeps(ctx, web)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("@scope/core", "0.1.0"), Constraint: "*", Kind: "prod"},
+			{Package: npmPackage("react", "18.2.0"), Constraint: "^18.0.0", Kind: "prod"},
+		}, deps)
+	})
+
+	t.Run("test parses lockfileVersion 1", func(t *testing.T) {
+		data := []byte(`
+{
+	"name": "app",
+	"version": "1.0.0",
+	"lockfileVersion": 1,
+	"dependencies": {
+		"a": {"version": "1.2.0", "requires": {"lodash": "^4.17.0"}},
+		"b": {
+			"version": "1.0.1",
+			"requires": {"lodash": "^3.10.0"},
+			"dependencies": {
+				"lodash": {"version": "3.10.1"}
+			}
+		},
+		"lodash": {"version": "4.17.21"},
+		"tester": {"version": "2.0.0", "dev": true}
+	}
+}
+`)
+		g, err := ParsePackageLock(data)
+		assert.NoError(t, err)
+
+		deps, err := g.FetchPackageDeps(ctx, g.Root)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("a", "1.2.0"), Kind: "prod"},
+			{Package: npmPackage("b", "1.0.1"), Kind: "prod"},
+			{Package: npmPackage("tester", "2.0.0"), Kind: "dev"},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, npmPackage("b", "1.0.1"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("lodash", "3.10.1"), Constraint: "^3.10.0", Kind: "prod"},
+		}, deps)
+	})
+
+	t.Run("test reads dependencies of the root of lockfileVersion 1 from package.json", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"package.json": {Data: []byte(`{
+	"name": "app",
+	"version": "1.0.0",
+	"dependencies": {"a": "^1.2.0", "lodash": "^4.17.0"},
+	"optionalDependencies": {"fsevents": "^2.3.0"},
+	"devDependencies": {"tester": "^2.0.0"}
+}`)},
+			"package-lock.json": {Data: []byte(`{
+	"name": "app",
+	"version": "1.0.0",
+	"lockfileVersion": 1,
+	"dependencies": {
+		"a": {"version": "1.2.0", "requires": {"lodash": "^4.17.0"}},
+		"lodash": {"version": "4.17.21"},
+		"tester": {"version": "2.0.0", "dev": true}
+	}
+}`)},
+		}
+		g, err := parse(fsys, "package-lock.json", "project")
+		assert.NoError(t, err)
+
+		// lodash is hoisted and required by a, it still is a direct dependency
+		deps, err := g.FetchPackageDeps(ctx, g.Root)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("a", "1.2.0"), Constraint: "^1.2.0", Kind: "prod"},
+			{Package: npmPackage("lodash", "4.17.21"), Constraint: "^4.17.0", Kind: "prod"},
+			{Package: npmPackage("tester", "2.0.0"), Constraint: "^2.0.0", Kind: "dev"},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, npmPackage("a", "1.2.0"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("lodash", "4.17.21"), Constraint: "^4.17.0", Kind: "prod"},
+		}, deps)
+	})
+
+	t.Run("test returns error for invalid lockfile", func(t *testing.T) {
+		_, err := ParsePackageLock([]byte(`{"packages": `))
+		assert.ErrorIs(t, err, dep_errors.ErrInvalidLockfile)
+
+		_, err = ParsePackageLock([]byte(`{"name": "app"}`))
+		assert.ErrorIs(t, err, dep_errors.ErrInvalidLockfile)
+	})
+}
+
+func TestGraph_Resolve(t *testing.T) {
+	ctx := context.Background()
+	g := NewGraph(npmPackage("app", "1.0.0"))
+	g.AddDependency(g.Root, models.Dependency{Package: npmPackage("lodash", "4.17.21")})
+	g.AddDependency(g.Root, models.Dependency{Package: npmPackage("lodash", "3.10.1")})
+	g.AddDependency(g.Root, models.Dependency{Package: npmPackage("@babel/core", "7.23.0")})
+
+	t.Run("test resolves package by name and version", func(t *testing.T) {
+		pkg, err := g.Resolve(ctx, "@babel/core")
+		assert.NoError(t, err)
+		assert.Equal(t, npmPackage("@babel/core", "7.23.0"), pkg)
+
+		pkg, err = g.Resolve(ctx, "lodash@3.10.1")
+		assert.NoError(t, err)
+		assert.Equal(t, npmPackage("lodash", "3.10.1"), pkg)
+	})
+
+	t.Run("test returns error for ambiguous or missing package", func(t *testing.T) {
+		_, err := g.Resolve(ctx, "lodash")
+		assert.ErrorIs(t, err, dep_errors.ErrInvalidPackageName)
+
+		_, err = g.Resolve(ctx, "react")
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+
+		_, err = g.FetchPackageDeps(ctx, npmPackage("react", "18.2.0"))
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+	})
+
+	t.Run("test detects lockfile by name", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"npm-shrinkwrap.json": {Data: []byte(`{"name": "app", "lockfileVersion": 1}`)},
+		}
+		_, err := parse(fsys, "deps.lock", "project")
+		assert.ErrorIs(t, err, dep_errors.ErrInvalidLockfile)
+
+		g, err := parse(fsys, "npm-shrinkwrap.json", "project")
+		assert.NoError(t, err)
+		assert.Equal(t, npmPackage("app", ""), g.Root)
+	})
+}
@@ -0,0 +1,48 @@
+package lockfile
+
+import (
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/dependency_provider/pip"
+	"depviz/internal/models"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// parsePipfileLock reads Pipfile.lock. It records neither the project nor
+// requirements between packages, so every locked package is drawn as a
+// dependency of the project: default packages as prod, develop ones as dev.
+func parsePipfileLock(data []byte, project string) (*Graph, error) {
+	type entry struct {
+		Version string `json:"version"`
+	}
+	var schema struct {
+		Meta    *json.RawMessage `json:"_meta"`
+		Default map[string]entry `json:"default"`
+		Develop map[string]entry `json:"develop"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidLockfile, err)
+	}
+	if schema.Meta == nil {
+		return nil, fmt.Errorf("%w: _meta is missing", dep_errors.ErrInvalidLockfile)
+	}
+
+	g := NewGraph(models.Package{Ecosystem: models.PyPI, Name: pip.NormalizeName(project)})
+	add := func(entries map[string]entry, kind string) {
+		names := make([]string, 0, len(entries))
+		for name := range entries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			// packages are pinned with "=="
+			version := strings.TrimPrefix(entries[name].Version, "==")
+			g.AddDependency(g.Root, models.Dependency{Package: pypiPackage(name, version), Kind: kind})
+		}
+	}
+	add(schema.Default, KindProd)
+	add(schema.Develop, KindDev)
+	return g, nil
+}
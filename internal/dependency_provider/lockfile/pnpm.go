@@ -0,0 +1,286 @@
+package lockfile
+
+import (
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/dependency_provider/npm"
+	"depviz/internal/models"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// pnpmDependency is a dependency of an importer. Lockfile v5 records only the
+// version, specifiers are listed separately.
+type pnpmDependency struct {
+	Specifier string `yaml:"specifier"`
+	Version   string `yaml:"version"`
+}
+
+func (d *pnpmDependency) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		d.Version = node.Value
+		return nil
+	}
+	type plain pnpmDependency
+	return node.Decode((*plain)(d))
+}
+
+// pnpmImporter is a project of the workspace, keyed by its directory.
+type pnpmImporter struct {
+	Specifiers           map[string]string         `yaml:"specifiers"`
+	Dependencies         map[string]pnpmDependency `yaml:"dependencies"`
+	OptionalDependencies map[string]pnpmDependency `yaml:"optionalDependencies"`
+	DevDependencies      map[string]pnpmDependency `yaml:"devDependencies"`
+}
+
+// pnpmPackage is an entry of "packages" or, since lockfile v9, "snapshots".
+// Dependencies are recorded as versions, optionally with the peer
+// dependencies they were resolved with.
+type pnpmPackage struct {
+	Name                 string                    `yaml:"name"`
+	Version              string                    `yaml:"version"`
+	Dependencies         map[string]string         `yaml:"dependencies"`
+	OptionalDependencies map[string]string         `yaml:"optionalDependencies"`
+	PeerDependencies     map[string]string         `yaml:"peerDependencies"`
+	PeerDependenciesMeta map[string]dependencyMeta `yaml:"peerDependenciesMeta"`
+}
+
+type pnpmLock struct {
+	LockfileVersion string                   `yaml:"lockfileVersion"`
+	Importers       map[string]*pnpmImporter `yaml:"importers"`
+	// lockfiles of a single project keep the importer at the top level
+	pnpmImporter `yaml:",inline"`
+	Packages     map[string]*pnpmPackage `yaml:"packages"`
+	Snapshots    map[string]*pnpmPackage `yaml:"snapshots"`
+
+	major    int
+	g        *Graph
+	projects map[string]models.Package
+}
+
+// stripPeers removes peer dependencies from a version, e.g. "1.0.0(react@18.2.0)"
+// or "1.0.0_react@18.2.0" in lockfile v5.
+func (l *pnpmLock) stripPeers(version string) string {
+	if i := strings.IndexByte(version, '('); i >= 0 {
+		version = version[:i]
+	}
+	if l.major < 6 {
+		if i := strings.IndexByte(version, '_'); i >= 0 {
+			version = version[:i]
+		}
+	}
+	return version
+}
+
+// key returns the key of the package locked for a dependency. Aliased
+// dependencies reference the key of the real package.
+func (l *pnpmLock) key(name, version string) string {
+	switch {
+	case l.major < 6 && strings.HasPrefix(version, "/"):
+		return version
+	case l.major < 6:
+		return "/" + name + "/" + version
+	case l.major < 9 && strings.HasPrefix(version, "/"):
+		return version
+	case l.major < 9:
+		return "/" + name + "@" + version
+	}
+	if i := strings.IndexByte(l.stripPeers(version), '@'); i > 0 {
+		return version
+	}
+	return name + "@" + version
+}
+
+// parseKey returns the package locked under a key.
+func (l *pnpmLock) parseKey(key string) models.Package {
+	key = strings.TrimPrefix(key, "/")
+	var name, version string
+	if l.major < 6 {
+		parts := strings.Split(key, "/")
+		name, version = parts[0], ""
+		if n := len(parts); n >= 2 {
+			name, version = parts[n-2], parts[n-1]
+			if n >= 3 && strings.HasPrefix(parts[n-3], "@") {
+				name = parts[n-3] + "/" + name
+			}
+		}
+	} else {
+		name, version = splitDescriptor(l.stripPeers(key))
+	}
+	return models.Package{Ecosystem: models.Npm, Name: name, Version: l.stripPeers(version)}
+}
+
+func (l *pnpmLock) packageAt(key string) models.Package {
+	pkg := l.parseKey(key)
+	if p := l.metadata(key); p != nil && p.Name != "" {
+		// packages from tarballs and git repositories record their name
+		pkg.Name, pkg.Version = p.Name, p.Version
+	}
+	return pkg
+}
+
+// metadata returns the entry with peer dependencies of the package locked under key.
+func (l *pnpmLock) metadata(key string) *pnpmPackage {
+	if l.major >= 9 {
+		pkg := l.parseKey(key)
+		return l.Packages[pkg.Name+"@"+pkg.Version]
+	}
+	return l.Packages[key]
+}
+
+// snapshot returns the entry with dependencies of the package locked under key.
+func (l *pnpmLock) snapshot(key string) *pnpmPackage {
+	if l.major >= 9 {
+		return l.Snapshots[key]
+	}
+	return l.Packages[key]
+}
+
+// addDependency adds an edge from a package of the importer at dir.
+func (l *pnpmLock) addDependency(from models.Package, dir, name, version string, dep models.Dependency) {
+	if strings.HasPrefix(version, "link:") {
+		// links point to other importers relative to the importer
+		project, ok := l.projects[path.Join(dir, strings.TrimPrefix(version, "link:"))]
+		if !ok {
+			project = models.Package{Ecosystem: models.Npm, Name: name}
+		}
+		dep.Package = project
+	} else {
+		dep.Package = l.packageAt(l.key(name, version))
+	}
+	l.g.AddDependency(from, dep)
+}
+
+func (l *pnpmLock) addImporter(dir string, importer *pnpmImporter) {
+	from := l.projects[dir]
+	l.g.AddPackage(from)
+	kinds := []struct {
+		deps map[string]pnpmDependency
+		kind string
+	}{
+		{importer.Dependencies, npm.KindProd},
+		{importer.OptionalDependencies, npm.KindOptional},
+		{importer.DevDependencies, npm.KindDev},
+	}
+	for _, k := range kinds {
+		names := make([]string, 0, len(k.deps))
+		for name := range k.deps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			dep := k.deps[name]
+			constraint := dep.Specifier
+			if constraint == "" {
+				constraint = importer.Specifiers[name]
+			}
+			l.addDependency(from, dir, name, dep.Version, models.Dependency{
+				Constraint: constraint,
+				Kind:       k.kind,
+				Optional:   k.kind == npm.KindOptional,
+			})
+		}
+	}
+}
+
+func (l *pnpmLock) addPackage(key string) {
+	from := l.packageAt(key)
+	l.g.AddPackage(from)
+	snapshot := l.snapshot(key)
+	if snapshot == nil {
+		return
+	}
+	meta := l.metadata(key)
+	if meta == nil {
+		meta = &pnpmPackage{}
+	}
+
+	for _, name := range sortedKeys(snapshot.Dependencies) {
+		dep := models.Dependency{Kind: npm.KindProd}
+		// resolved peer dependencies are listed with dependencies
+		if constraint, ok := meta.PeerDependencies[name]; ok {
+			dep = models.Dependency{
+				Constraint: constraint,
+				Kind:       npm.KindPeer,
+				Optional:   meta.PeerDependenciesMeta[name].Optional,
+			}
+		}
+		l.addDependency(from, "", name, snapshot.Dependencies[name], dep)
+	}
+	for _, name := range sortedKeys(snapshot.OptionalDependencies) {
+		l.addDependency(from, "", name, snapshot.OptionalDependencies[name], models.Dependency{
+			Kind:     npm.KindOptional,
+			Optional: true,
+		})
+	}
+}
+
+// parsePnpmLock reads pnpm-lock.yaml of lockfile versions 5 to 9. The lockfile
+// names neither the project nor its workspaces, so names are read from their
+// package.json files if they exist, otherwise importers are named by directory.
+func parsePnpmLock(fsys fs.FS, data []byte, project string) (*Graph, error) {
+	var l pnpmLock
+	if err := yaml.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidLockfile, err)
+	}
+	major, err := strconv.ParseFloat(l.LockfileVersion, 64)
+	if err != nil || major < 5 {
+		return nil, fmt.Errorf("%w: unsupported lockfileVersion %q", dep_errors.ErrInvalidLockfile, l.LockfileVersion)
+	}
+	l.major = int(major)
+	if l.Importers == nil {
+		l.Importers = map[string]*pnpmImporter{".": &l.pnpmImporter}
+	}
+
+	dirs := make([]string, 0, len(l.Importers))
+	l.projects = make(map[string]models.Package, len(l.Importers))
+	for dir := range l.Importers {
+		m, err := readManifest(fsys, dir)
+		if err != nil {
+			return nil, err
+		}
+		pkg := models.Package{Ecosystem: models.Npm, Name: dir}
+		if dir == "." {
+			pkg.Name = project
+		}
+		if m != nil && m.Name != "" {
+			pkg.Name, pkg.Version = m.Name, m.Version
+		}
+		l.projects[dir] = pkg
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	root, ok := l.projects["."]
+	if !ok {
+		return nil, fmt.Errorf("%w: root importer is missing", dep_errors.ErrInvalidLockfile)
+	}
+	l.g = NewGraph(root)
+	for _, dir := range dirs {
+		if dir != "." {
+			l.g.AddDependency(root, models.Dependency{Package: l.projects[dir], Kind: KindWorkspace})
+		}
+	}
+	for _, dir := range dirs {
+		l.addImporter(dir, l.Importers[dir])
+	}
+
+	entries := l.Packages
+	if l.major >= 9 {
+		entries = l.Snapshots
+	}
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		l.addPackage(key)
+	}
+	return l.g, nil
+}
@@ -0,0 +1,226 @@
+package lockfile
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParsePnpmLock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("test parses lockfile v9 with workspaces", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"package.json":              {Data: []byte(`{"name": "monorepo", "version": "1.0.0"}`)},
+			"packages/web/package.json": {Data: []byte(`{"name": "@scope/web", "version": "0.2.0"}`)},
+			"pnpm-lock.yaml": {Data: []byte(`lockfileVersion: '9.0'
+
+settings:
+  autoInstallPeers: true
+
+importers:
+
+  .:
+    devDependencies:
+      typescript:
+        specifier: ^5.0.0
+        version: 5.2.2
+
+  packages/core:
+    dependencies:
+      react:
+        specifier: ^18.0.0
+        version: 18.2.0
+
+  packages/web:
+    dependencies:
+      '@scope/core':
+        specifier: workspace:*
+        version: link:../core
+      react-dom:
+        specifier: ^18.0.0
+        version: 18.2.0(react@18.2.0)
+      string-width-cjs:
+        specifier: npm:string-width@^4.2.0
+        version: string-width@4.2.3
+
+packages:
+
+  react-dom@18.2.0:
+    resolution: {integrity: sha512-6IMTriUmvsjHUjNtEDudZfuDQUoWXVxKHhlEGSk81n4YFS+r/Kl99wXiwlVXtPBtJenozv2P+hxDsw9eA7Xo6g==}
+    peerDependencies:
+      react: ^18.2.0
+
+  react@18.2.0:
+    resolution: {integrity: sha512-/3IjMdb2L9QbBdWiW5e3P2/npwMBaU9mHCSCUzNln0ZCYbcfTsGbTJrU/kGemdH2IWmB2ioZ+zkxtmq6g09fGQ==}
+
+  string-width@4.2.3:
+    resolution: {integrity: sha512-wKyQRQpjJ0sIp62ErSZdGsjMJWsap5oRNihHhu6G7JVO/9jIB6UyevL+tXuOqrng8j/cxKTWyWUwvSTriiZz/g==}
+
+  typescript@5.2.2:
+    resolution: {integrity: sha512-mI4WrpHsbCIcwT9cF4FZvr80QUeKvsUsUvKDoR+X/7XHQH98xYD8YHZg7ANtz2GtZt/CBq2QJ0thkGJMHfqc1w==}
+
+snapshots:
+
+  react-dom@18.2.0(react@18.2.0):
+    dependencies:
+      react: 18.2.0
+
+  react@18.2.0: {}
+
+  string-width@4.2.3: {}
+
+  typescript@5.2.2: {}
+`)},
+		}
+		g, err := parse(fsys, "pnpm-lock.yaml", "project")
+		assert.NoError(t, err)
+		assert.Equal(t, npmPackage("monorepo", "1.0.0"), g.Root)
+
+		deps, err := g.FetchPackageDeps(ctx, g.Root)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("packages/core", ""), Kind: KindWorkspace},
+			{Package: npmPackage("@scope/web", "0.2.0"), Kind: KindWorkspace},
+			{Package: npmPackage("typescript", "5.2.2"), Constraint: "^5.0.0", Kind: "dev"},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, npmPackage("@scope/web", "0.2.0"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("packages/core", ""), Constraint: "workspace:*", Kind: "prod"},
+			{Package: npmPackage("react-dom", "18.2.0"), Constraint: "^18.0.0", Kind: "prod"},
+			{Package: npmPackage("string-width", "4.2.3"), Constraint: "npm:string-width@^4.2.0", Kind: "prod"},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, npmPackage("react-dom", "18.2.0"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("react", "18.2.0"), Constraint: "^18.2.0", Kind: "peer"},
+		}, deps)
+	})
+
+	t.Run("test parses lockfile v6 of single project", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"pnpm-lock.yaml": {Data: []byte(`lockfileVersion: '6.0'
+
+dependencies:
+  '@babel/core':
+    specifier: ^7.23.0
+    version: 7.23.0
+
+optionalDependencies:
+  fsevents:
+    specifier: ^2.3.0
+    version: 2.3.3
+
+packages:
+
+  /@babel/core@7.23.0:
+    resolution: {integrity: sha512-97z/ju/Jy1rZmDxybphrBuI+jtJjFVoz7Mr9yUQVVVi+DNZE333uFQeMOqcCIy1x3WYBIbWftUSLmbNXNT7qFQ==}
+    dependencies:
+      debug: 4.3.4(supports-color@5.5.0)
+    dev: false
+
+  /debug@4.3.4(supports-color@5.5.0):
+    resolution: {integrity: sha512-PRWFHuSU3eDtQJPvnNY7Jcket1j0t5OuOsFzPPzsekD52Zl8qUfFIPEiswXqIvHWGVHOgX+7G/vCNNhehwxfkQ==}
+    peerDependenciesMeta:
+      supports-color:
+        optional: true
+    dependencies:
+      ms: 2.1.2
+
+  /fsevents@2.3.3:
+    resolution: {integrity: sha512-5xoDfX+fL7faATnagmWPpbFtwh/R77WmMMqqHGS65C3vvB0YHrgF+B1YmZ3441tMj5n63k0212XNoJwzlhffQw==}
+    optional: true
+
+  /ms@2.1.2:
+    resolution: {integrity: sha512-sGkPx+VjMtmA6MX27oA4FBFELFCZZ4S4XqeGOXCv68tT+jb3vk/RyaKWP0PTKyWtmLSM0b+adUTEvbs1PEaH2w==}
+`)},
+		}
+		g, err := parse(fsys, "pnpm-lock.yaml", "project")
+		assert.NoError(t, err)
+		assert.Equal(t, npmPackage("project", ""), g.Root)
+
+		deps, err := g.FetchPackageDeps(ctx, g.Root)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("@babel/core", "7.23.0"), Constraint: "^7.23.0", Kind: "prod"},
+			{Package: npmPackage("fsevents", "2.3.3"), Constraint: "^2.3.0", Kind: "optional", Optional: true},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, npmPackage("@babel/core", "7.23.0"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("debug", "4.3.4"), Kind: "prod"},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, npmPackage("debug", "4.3.4"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("ms", "2.1.2"), Kind: "prod"},
+		}, deps)
+	})
+
+	t.Run("test parses lockfile v5", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"pnpm-lock.yaml": {Data: []byte(`lockfileVersion: 5.4
+
+importers:
+
+  .:
+    specifiers:
+      '@scope/web': workspace:*
+    dependencies:
+      '@scope/web': link:packages/web
+
+  packages/web:
+    specifiers:
+      react-dom: ^18.0.0
+    dependencies:
+      react-dom: 18.2.0_react@18.2.0
+
+packages:
+
+  /react-dom/18.2.0_react@18.2.0:
+    resolution: {integrity: sha512-6IMTriUmvsjHUjNtEDudZfuDQUoWXVxKHhlEGSk81n4YFS+r/Kl99wXiwlVXtPBtJenozv2P+hxDsw9eA7Xo6g==}
+    peerDependencies:
+      react: ^18.2.0
+    dependencies:
+      react: 18.2.0
+
+  /react/18.2.0:
+    resolution: {integrity: sha512-/3IjMdb2L9QbBdWiW5e3P2/npwMBaU9mHCSCUzNln0ZCYbcfTsGbTJrU/kGemdH2IWmB2ioZ+zkxtmq6g09fGQ==}
+`)},
+		}
+		g, err := parse(fsys, "pnpm-lock.yaml", "project")
+		assert.NoError(t, err)
+
+		deps, err := g.FetchPackageDeps(ctx, g.Root)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("packages/web", ""), Constraint: "workspace:*", Kind: "prod"},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, npmPackage("packages/web", ""))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("react-dom", "18.2.0"), Constraint: "^18.0.0", Kind: "prod"},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, npmPackage("react-dom", "18.2.0"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("react", "18.2.0"), Constraint: "^18.2.0", Kind: "peer"},
+		}, deps)
+	})
+
+	t.Run("test returns error for unsupported lockfile version", func(t *testing.T) {
+		fsys := fstest.MapFS{"pnpm-lock.yaml": {Data: []byte("lockfileVersion: 4\n")}}
+		_, err := parse(fsys, "pnpm-lock.yaml", "project")
+		assert.ErrorIs(t, err, dep_errors.ErrInvalidLockfile)
+	})
+}
@@ -0,0 +1,285 @@
+package lockfile
+
+import (
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/dependency_provider/pip"
+	"depviz/internal/models"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+)
+
+func pypiPackage(name, version string) models.Package {
+	return models.Package{Ecosystem: models.PyPI, Name: pip.NormalizeName(name), Version: version}
+}
+
+// pythonDependency is a requirement of a locked Python package.
+type pythonDependency struct {
+	name       string
+	constraint string
+	extras     []string
+	optional   bool
+	kind       string
+}
+
+// pythonPackages indexes locked Python packages by normalized name.
+type pythonPackages map[string][]models.Package
+
+func (p pythonPackages) add(pkg models.Package) {
+	p[pkg.Name] = append(p[pkg.Name], pkg)
+}
+
+// lookup returns the locked package for a requirement. If a package is locked
+// at several versions, e.g. for different platforms, the best matching one is picked.
+func (p pythonPackages) lookup(name, constraint string) (models.Package, bool) {
+	candidates := p[pip.NormalizeName(name)]
+	if len(candidates) == 0 {
+		return models.Package{}, false
+	}
+	if len(candidates) > 1 {
+		if specifier, err := pip.ParseSpecifierSet(constraint); err == nil {
+			versions := make([]string, 0, len(candidates))
+			for _, pkg := range candidates {
+				versions = append(versions, pkg.Version)
+			}
+			if best := specifier.Best(versions); best != "" {
+				return pypiPackage(name, best), true
+			}
+		}
+	}
+	return candidates[0], true
+}
+
+// addPythonDependencies adds edges to locked packages. Requirements that are
+// not locked are added without a version unless they are optional.
+func addPythonDependencies(g *Graph, packages pythonPackages, from models.Package, deps []pythonDependency) {
+	for _, dep := range deps {
+		pkg, ok := packages.lookup(dep.name, dep.constraint)
+		if !ok {
+			if dep.optional {
+				continue
+			}
+			pkg = pypiPackage(dep.name, "")
+		}
+		kind := dep.kind
+		if kind == "" {
+			kind = KindProd
+		}
+		g.AddDependency(from, models.Dependency{Package: pkg, Constraint: dep.constraint, Kind: kind, Optional: dep.optional})
+	}
+}
+
+// poetryDependency is a value of [package.dependencies]: a constraint, a table
+// or a list of tables with constraints for different environments.
+type poetryDependency struct {
+	Version  string   `toml:"version"`
+	Optional bool     `toml:"optional"`
+	Extras   []string `toml:"extras"`
+}
+
+func (d *poetryDependency) UnmarshalTOML(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		d.Version = v
+	case map[string]interface{}:
+		d.Version, _ = v["version"].(string)
+		d.Optional, _ = v["optional"].(bool)
+		extras, _ := v["extras"].([]interface{})
+		for _, extra := range extras {
+			if s, ok := extra.(string); ok {
+				d.Extras = append(d.Extras, s)
+			}
+		}
+	case []interface{}:
+		// constraints for different environments, the first one stands for all of them
+		for i := len(v) - 1; i >= 0; i-- {
+			if err := d.UnmarshalTOML(v[i]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("invalid dependency %v", value)
+	}
+	return nil
+}
+
+func poetryDependencies(deps map[string]poetryDependency, kind string) []pythonDependency {
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		// the python version is not a package
+		if name != "python" {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return pip.NormalizeName(names[i]) < pip.NormalizeName(names[j])
+	})
+	result := make([]pythonDependency, 0, len(names))
+	for _, name := range names {
+		dep := deps[name]
+		result = append(result, pythonDependency{
+			name:       name,
+			constraint: dep.Version,
+			extras:     dep.Extras,
+			optional:   dep.Optional,
+			kind:       kind,
+		})
+	}
+	return result
+}
+
+// poetryProject reads the root package and its dependencies from pyproject.toml.
+// It returns nil if there is no pyproject.toml.
+func poetryProject(fsys fs.FS) (*models.Package, []pythonDependency, error) {
+	data, err := fs.ReadFile(fsys, "pyproject.toml")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+	var schema struct {
+		Project struct {
+			Name         string   `toml:"name"`
+			Version      string   `toml:"version"`
+			Dependencies []string `toml:"dependencies"`
+		} `toml:"project"`
+		Tool struct {
+			Poetry struct {
+				Name            string                      `toml:"name"`
+				Version         string                      `toml:"version"`
+				Dependencies    map[string]poetryDependency `toml:"dependencies"`
+				DevDependencies map[string]poetryDependency `toml:"dev-dependencies"`
+				Group           map[string]struct {
+					Dependencies map[string]poetryDependency `toml:"dependencies"`
+				} `toml:"group"`
+			} `toml:"poetry"`
+		} `toml:"tool"`
+	}
+	if err := toml.Unmarshal(data, &schema); err != nil {
+		return nil, nil, fmt.Errorf("can't parse pyproject.toml: %w", err)
+	}
+
+	poetry := schema.Tool.Poetry
+	root := pypiPackage(poetry.Name, poetry.Version)
+	if schema.Project.Name != "" {
+		root = pypiPackage(schema.Project.Name, schema.Project.Version)
+	}
+	var deps []pythonDependency
+	for _, dep := range schema.Project.Dependencies {
+		req, err := pip.ParseRequirement(dep)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidPackageName, err)
+		}
+		deps = append(deps, pythonDependency{name: req.Name, constraint: req.Specifier.String(), extras: req.Extras})
+	}
+	deps = append(deps, poetryDependencies(poetry.Dependencies, KindProd)...)
+	// all groups but the main one are for development
+	deps = append(deps, poetryDependencies(poetry.DevDependencies, KindDev)...)
+	groups := make([]string, 0, len(poetry.Group))
+	for group := range poetry.Group {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		kind := KindDev
+		if group == "main" {
+			kind = KindProd
+		}
+		deps = append(deps, poetryDependencies(poetry.Group[group].Dependencies, kind)...)
+	}
+	return &root, deps, nil
+}
+
+// parsePoetryLock reads poetry.lock. The dependencies of the project are read
+// from pyproject.toml next to it, without it packages that no other package
+// requires are treated as dependencies of the project.
+func parsePoetryLock(fsys fs.FS, data []byte, project string) (*Graph, error) {
+	var schema struct {
+		Package []struct {
+			Name         string                      `toml:"name"`
+			Version      string                      `toml:"version"`
+			Category     string                      `toml:"category"`
+			Dependencies map[string]poetryDependency `toml:"dependencies"`
+			Extras       map[string][]string         `toml:"extras"`
+		} `toml:"package"`
+	}
+	if err := toml.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidLockfile, err)
+	}
+
+	root, rootDeps, err := poetryProject(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if root == nil || root.Name == "" {
+		root = &models.Package{Ecosystem: models.PyPI, Name: pip.NormalizeName(project)}
+	}
+	g := NewGraph(*root)
+
+	packages := make(pythonPackages)
+	// requested are extras requested by any requirement of a package
+	requested := make(map[string]map[string]struct{})
+	request := func(name string, extras []string) {
+		name = pip.NormalizeName(name)
+		for _, extra := range extras {
+			if requested[name] == nil {
+				requested[name] = make(map[string]struct{})
+			}
+			requested[name][pip.NormalizeName(extra)] = struct{}{}
+		}
+	}
+	required := make(map[string]struct{})
+	for _, p := range schema.Package {
+		packages.add(pypiPackage(p.Name, p.Version))
+		for name, dep := range p.Dependencies {
+			request(name, dep.Extras)
+			required[pip.NormalizeName(name)] = struct{}{}
+		}
+	}
+	for _, dep := range rootDeps {
+		request(dep.name, dep.extras)
+	}
+
+	if rootDeps == nil {
+		// packages only the project requires
+		for _, p := range schema.Package {
+			if _, ok := required[pip.NormalizeName(p.Name)]; ok {
+				continue
+			}
+			kind := KindProd
+			if p.Category == "dev" {
+				kind = KindDev
+			}
+			rootDeps = append(rootDeps, pythonDependency{name: p.Name, kind: kind})
+		}
+		sort.Slice(rootDeps, func(i, j int) bool {
+			return pip.NormalizeName(rootDeps[i].name) < pip.NormalizeName(rootDeps[j].name)
+		})
+	}
+	addPythonDependencies(g, packages, *root, rootDeps)
+
+	for _, p := range schema.Package {
+		from := pypiPackage(p.Name, p.Version)
+		// optional dependencies are installed if an extra listing them is requested
+		activated := make(map[string]struct{})
+		for extra := range requested[from.Name] {
+			for _, dep := range p.Extras[extra] {
+				if req, err := pip.ParseRequirement(dep); err == nil {
+					activated[pip.NormalizeName(req.Name)] = struct{}{}
+				}
+			}
+		}
+		var deps []pythonDependency
+		for _, dep := range poetryDependencies(p.Dependencies, KindProd) {
+			if _, ok := activated[pip.NormalizeName(dep.name)]; dep.optional && !ok {
+				continue
+			}
+			deps = append(deps, dep)
+		}
+		addPythonDependencies(g, packages, from, deps)
+	}
+	return g, nil
+}
@@ -0,0 +1,176 @@
+package lockfile
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/fstest"
+)
+
+func pypiPackageAt(name, version string) models.Package {
+	return models.Package{Ecosystem: models.PyPI, Name: name, Version: version}
+}
+
+const poetryLock = `# This file is automatically @generated by Poetry 1.7.1 and should not be changed by hand.
+
+[[package]]
+name = "certifi"
+version = "2023.11.17"
+description = "Python package for providing Mozilla's CA Bundle."
+optional = false
+python-versions = ">=3.6"
+files = []
+
+[[package]]
+name = "pysocks"
+version = "1.7.1"
+description = "A Python SOCKS client module."
+optional = false
+python-versions = ">=2.7"
+files = []
+
+[[package]]
+name = "pytest"
+version = "7.4.3"
+description = "pytest: simple powerful testing with Python"
+optional = false
+python-versions = ">=3.7"
+files = []
+
+[package.dependencies]
+colorama = {version = "*", markers = "sys_platform == \"win32\""}
+
+[[package]]
+name = "requests"
+version = "2.31.0"
+description = "Python HTTP for Humans."
+optional = false
+python-versions = ">=3.7"
+files = []
+
+[package.dependencies]
+certifi = ">=2017.4.17"
+PySocks = {version = ">=1.5.6, !=1.5.7", optional = true}
+urllib3 = [
+    {version = ">=1.21.1,<2", markers = "python_version < \"3.10\""},
+    {version = ">=1.21.1,<3", markers = "python_version >= \"3.10\""},
+]
+
+[package.extras]
+socks = ["PySocks (>=1.5.6,!=1.5.7)"]
+
+[[package]]
+name = "urllib3"
+version = "2.1.0"
+description = "HTTP library with thread-safe connection pooling, file post, and more."
+optional = false
+python-versions = ">=3.8"
+files = []
+
+[metadata]
+lock-version = "2.0"
+python-versions = "^3.9"
+content-hash = "0000"
+`
+
+func TestParsePoetryLock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("test reads dependencies of the project from pyproject.toml", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"poetry.lock": {Data: []byte(poetryLock)},
+			"pyproject.toml": {Data: []byte(`
+[tool.poetry]
+name = "My_App"
+version = "0.1.0"
+
+[tool.poetry.dependencies]
+python = "^3.9"
+requests = {version = "^2.31", extras = ["socks"]}
+
+[tool.poetry.group.test.dependencies]
+pytest = "^7.4"
+`)},
+		}
+		g, err := parse(fsys, "poetry.lock", "project")
+		assert.NoError(t, err)
+		assert.Equal(t, pypiPackageAt("my-app", "0.1.0"), g.Root)
+
+		deps, err := g.FetchPackageDeps(ctx, g.Root)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: pypiPackageAt("requests", "2.31.0"), Constraint: "^2.31", Kind: "prod"},
+			{Package: pypiPackageAt("pytest", "7.4.3"), Constraint: "^7.4", Kind: "dev"},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, pypiPackageAt("requests", "2.31.0"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: pypiPackageAt("certifi", "2023.11.17"), Constraint: ">=2017.4.17", Kind: "prod"},
+			{Package: pypiPackageAt("pysocks", "1.7.1"), Constraint: ">=1.5.6, !=1.5.7", Kind: "prod", Optional: true},
+			{Package: pypiPackageAt("urllib3", "2.1.0"), Constraint: ">=1.21.1,<2", Kind: "prod"},
+		}, deps)
+
+		// colorama is not locked for this platform
+		deps, err = g.FetchPackageDeps(ctx, pypiPackageAt("pytest", "7.4.3"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: pypiPackageAt("colorama", ""), Constraint: "*", Kind: "prod"},
+		}, deps)
+	})
+
+	t.Run("test guesses dependencies of the project without pyproject.toml", func(t *testing.T) {
+		g, err := parse(fstest.MapFS{"poetry.lock": {Data: []byte(poetryLock)}}, "poetry.lock", "My_App")
+		assert.NoError(t, err)
+		assert.Equal(t, pypiPackageAt("my-app", ""), g.Root)
+
+		deps, err := g.FetchPackageDeps(ctx, g.Root)
+		assert.NoError(t, err)
+		// pysocks is required by requests
+		assert.Equal(t, []models.Dependency{
+			{Package: pypiPackageAt("pytest", "7.4.3"), Kind: "prod"},
+			{Package: pypiPackageAt("requests", "2.31.0"), Kind: "prod"},
+		}, deps)
+
+		// no extra of requests is requested
+		deps, err = g.FetchPackageDeps(ctx, pypiPackageAt("requests", "2.31.0"))
+		assert.NoError(t, err)
+		assert.Len(t, deps, 2)
+	})
+
+	t.Run("test returns error for invalid lockfile", func(t *testing.T) {
+		_, err := parse(fstest.MapFS{"poetry.lock": {Data: []byte("[[package]\n")}}, "poetry.lock", "project")
+		assert.ErrorIs(t, err, dep_errors.ErrInvalidLockfile)
+	})
+}
+
+func TestParsePipfileLock(t *testing.T) {
+	ctx := context.Background()
+	fsys := fstest.MapFS{"Pipfile.lock": {Data: []byte(`
+{
+	"_meta": {"hash": {"sha256": "0000"}, "pipfile-spec": 6},
+	"default": {
+		"requests": {"hashes": [], "index": "pypi", "version": "==2.31.0"},
+		"Django": {"hashes": [], "index": "pypi", "version": "==4.2.7"}
+	},
+	"develop": {
+		"pytest": {"hashes": [], "index": "pypi", "version": "==7.4.3"}
+	}
+}
+`)}}
+	g, err := parse(fsys, "Pipfile.lock", "project")
+	assert.NoError(t, err)
+
+	deps, err := g.FetchPackageDeps(ctx, g.Root)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Dependency{
+		{Package: pypiPackageAt("django", "4.2.7"), Kind: "prod"},
+		{Package: pypiPackageAt("requests", "2.31.0"), Kind: "prod"},
+		{Package: pypiPackageAt("pytest", "7.4.3"), Kind: "dev"},
+	}, deps)
+
+	_, err = parse(fstest.MapFS{"Pipfile.lock": {Data: []byte(`{"default": {}}`)}}, "Pipfile.lock", "project")
+	assert.ErrorIs(t, err, dep_errors.ErrInvalidLockfile)
+}
@@ -0,0 +1,162 @@
+package lockfile
+
+import (
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/dependency_provider/pip"
+	"depviz/internal/models"
+	"fmt"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+)
+
+// uvDependency references a locked package. The version is only given if the
+// package is locked at several versions.
+type uvDependency struct {
+	Name    string   `toml:"name"`
+	Version string   `toml:"version"`
+	Extra   []string `toml:"extra"`
+}
+
+type uvRequirement struct {
+	Name      string `toml:"name"`
+	Specifier string `toml:"specifier"`
+}
+
+type uvPackage struct {
+	Name    string `toml:"name"`
+	Version string `toml:"version"`
+	Source  struct {
+		Editable string `toml:"editable"`
+		Virtual  string `toml:"virtual"`
+	} `toml:"source"`
+	Dependencies         []uvDependency            `toml:"dependencies"`
+	OptionalDependencies map[string][]uvDependency `toml:"optional-dependencies"`
+	DevDependencies      map[string][]uvDependency `toml:"dev-dependencies"`
+	Metadata             struct {
+		RequiresDist []uvRequirement            `toml:"requires-dist"`
+		RequiresDev  map[string][]uvRequirement `toml:"requires-dev"`
+	} `toml:"metadata"`
+}
+
+// local returns the path of a package of the workspace.
+func (p *uvPackage) local() string {
+	if p.Source.Editable != "" {
+		return p.Source.Editable
+	}
+	return p.Source.Virtual
+}
+
+// parseUvLock reads uv.lock. The project is the package at ".", members of
+// the workspace are drawn as workspaces. Extras of packages are installed if
+// any requirement requests them, extras of workspace packages are drawn as
+// optional dependencies.
+func parseUvLock(data []byte, project string) (*Graph, error) {
+	var schema struct {
+		Version  *int        `toml:"version"`
+		Package  []uvPackage `toml:"package"`
+		Manifest struct {
+			Members []string `toml:"members"`
+		} `toml:"manifest"`
+	}
+	if err := toml.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidLockfile, err)
+	}
+	if schema.Version == nil {
+		return nil, fmt.Errorf("%w: version is missing", dep_errors.ErrInvalidLockfile)
+	}
+
+	packages := make(pythonPackages)
+	requested := make(map[string]map[string]struct{})
+	var root *models.Package
+	for _, p := range schema.Package {
+		pkg := pypiPackage(p.Name, p.Version)
+		packages.add(pkg)
+		if p.local() == "." {
+			root = &pkg
+		}
+		deps := append([]uvDependency(nil), p.Dependencies...)
+		for _, group := range p.OptionalDependencies {
+			deps = append(deps, group...)
+		}
+		for _, group := range p.DevDependencies {
+			deps = append(deps, group...)
+		}
+		for _, dep := range deps {
+			name := pip.NormalizeName(dep.Name)
+			for _, extra := range dep.Extra {
+				if requested[name] == nil {
+					requested[name] = make(map[string]struct{})
+				}
+				requested[name][pip.NormalizeName(extra)] = struct{}{}
+			}
+		}
+	}
+	if root == nil {
+		// a virtual workspace root is not a package
+		root = &models.Package{Ecosystem: models.PyPI, Name: pip.NormalizeName(project)}
+	}
+	g := NewGraph(*root)
+
+	members := make(map[string]struct{}, len(schema.Manifest.Members))
+	for _, member := range schema.Manifest.Members {
+		members[pip.NormalizeName(member)] = struct{}{}
+	}
+
+	lookup := func(dep uvDependency) string {
+		if dep.Version != "" {
+			return dep.Version
+		}
+		pkg, _ := packages.lookup(dep.Name, "")
+		return pkg.Version
+	}
+	for _, p := range schema.Package {
+		from := pypiPackage(p.Name, p.Version)
+		_, member := members[from.Name]
+		local := member || from == *root
+		if member && from != *root {
+			g.AddDependency(*root, models.Dependency{Package: from, Kind: KindWorkspace})
+		}
+
+		constraints := make(map[string]string)
+		for _, req := range p.Metadata.RequiresDist {
+			constraints[pip.NormalizeName(req.Name)] = req.Specifier
+		}
+		add := func(deps []uvDependency, kind string, optional bool) {
+			for _, dep := range deps {
+				g.AddDependency(from, models.Dependency{
+					Package:    pypiPackage(dep.Name, lookup(dep)),
+					Constraint: constraints[pip.NormalizeName(dep.Name)],
+					Kind:       kind,
+					Optional:   optional,
+				})
+			}
+		}
+		add(p.Dependencies, KindProd, false)
+		for _, extra := range sortedGroups(p.OptionalDependencies) {
+			if _, ok := requested[from.Name][pip.NormalizeName(extra)]; ok || local {
+				add(p.OptionalDependencies[extra], KindProd, true)
+			}
+		}
+		if local {
+			for _, group := range p.Metadata.RequiresDev {
+				for _, req := range group {
+					constraints[pip.NormalizeName(req.Name)] = req.Specifier
+				}
+			}
+			for _, group := range sortedGroups(p.DevDependencies) {
+				add(p.DevDependencies[group], KindDev, false)
+			}
+		}
+	}
+	return g, nil
+}
+
+func sortedGroups(groups map[string][]uvDependency) []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,102 @@
+package lockfile
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseUvLock(t *testing.T) {
+	ctx := context.Background()
+	fsys := fstest.MapFS{"uv.lock": {Data: []byte(`version = 1
+requires-python = ">=3.12"
+
+[manifest]
+members = ["app", "lib"]
+
+[[package]]
+name = "app"
+version = "0.1.0"
+source = { editable = "." }
+dependencies = [
+    { name = "lib" },
+    { name = "rich", extra = ["jupyter"] },
+]
+
+[package.optional-dependencies]
+cli = [{ name = "click" }]
+
+[package.dev-dependencies]
+dev = [{ name = "pytest" }]
+
+[package.metadata]
+requires-dist = [
+    { name = "click", marker = "extra == 'cli'", specifier = ">=8" },
+    { name = "lib", editable = "lib" },
+    { name = "rich", extras = ["jupyter"], specifier = ">=13" },
+]
+
+[package.metadata.requires-dev]
+dev = [{ name = "pytest", specifier = ">=8" }]
+
+[[package]]
+name = "click"
+version = "8.1.7"
+source = { registry = "https://pypi.org/simple" }
+
+[[package]]
+name = "ipywidgets"
+version = "8.1.1"
+source = { registry = "https://pypi.org/simple" }
+
+[[package]]
+name = "lib"
+version = "0.2.0"
+source = { editable = "lib" }
+
+[[package]]
+name = "pytest"
+version = "8.0.0"
+source = { registry = "https://pypi.org/simple" }
+
+[[package]]
+name = "rich"
+version = "13.7.0"
+source = { registry = "https://pypi.org/simple" }
+dependencies = [{ name = "pygments" }]
+
+[package.optional-dependencies]
+jupyter = [{ name = "ipywidgets" }]
+markdown = [{ name = "markdown-it-py" }]
+
+[[package]]
+name = "pygments"
+version = "2.17.2"
+source = { registry = "https://pypi.org/simple" }
+`)}}
+	g, err := parse(fsys, "uv.lock", "project")
+	assert.NoError(t, err)
+	assert.Equal(t, pypiPackageAt("app", "0.1.0"), g.Root)
+
+	deps, err := g.FetchPackageDeps(ctx, g.Root)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Dependency{
+		{Package: pypiPackageAt("lib", "0.2.0"), Kind: "prod"},
+		{Package: pypiPackageAt("rich", "13.7.0"), Constraint: ">=13", Kind: "prod"},
+		{Package: pypiPackageAt("click", "8.1.7"), Constraint: ">=8", Kind: "prod", Optional: true},
+		{Package: pypiPackageAt("pytest", "8.0.0"), Constraint: ">=8", Kind: "dev"},
+	}, deps)
+
+	deps, err = g.FetchPackageDeps(ctx, pypiPackageAt("rich", "13.7.0"))
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Dependency{
+		{Package: pypiPackageAt("pygments", "2.17.2"), Kind: "prod"},
+		{Package: pypiPackageAt("ipywidgets", "8.1.1"), Kind: "prod", Optional: true},
+	}, deps)
+
+	_, err = parse(fstest.MapFS{"uv.lock": {Data: []byte("[[package]]\nname = \"app\"\n")}}, "uv.lock", "project")
+	assert.ErrorIs(t, err, dep_errors.ErrInvalidLockfile)
+}
@@ -0,0 +1,353 @@
+package lockfile
+
+import (
+	"bytes"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/dependency_provider/npm"
+	"depviz/internal/models"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type dependencyMeta struct {
+	Optional bool `yaml:"optional"`
+}
+
+// yarnEntry is a locked package of yarn.lock. Entries are keyed by
+// descriptors such as "lodash@^4.17.0" in the classic format and
+// "lodash@npm:^4.17.0" in the Berry format.
+type yarnEntry struct {
+	Version              string                    `yaml:"version"`
+	Resolution           string                    `yaml:"resolution"`
+	Dependencies         map[string]string         `yaml:"dependencies"`
+	OptionalDependencies map[string]string         `yaml:"optionalDependencies"`
+	DependenciesMeta     map[string]dependencyMeta `yaml:"dependenciesMeta"`
+
+	pkg models.Package
+}
+
+// splitDescriptor splits "name@range" into the package name and the range.
+func splitDescriptor(descriptor string) (string, string) {
+	// the name of a scoped package starts with "@"
+	start := 0
+	if strings.HasPrefix(descriptor, "@") {
+		start = 1
+	}
+	if i := strings.IndexByte(descriptor[start:], '@'); i >= 0 {
+		return descriptor[:start+i], descriptor[start+i+1:]
+	}
+	return descriptor, ""
+}
+
+func unquoteYarn(s string) string {
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return strings.Trim(s, `"`)
+}
+
+// splitYarnLine splits a line of the classic format into a possibly quoted key and the value.
+func splitYarnLine(line string) (string, string) {
+	end := strings.IndexByte(line, ' ')
+	if strings.HasPrefix(line, `"`) {
+		end = strings.IndexByte(line[1:], '"') + 2
+	}
+	if end <= 0 || end >= len(line) {
+		return unquoteYarn(strings.TrimSuffix(line, ":")), ""
+	}
+	return unquoteYarn(line[:end]), unquoteYarn(strings.TrimSpace(line[end:]))
+}
+
+// parseYarnClassic parses the custom format of yarn.lock v1.
+func parseYarnClassic(data []byte) (map[string]*yarnEntry, error) {
+	entries := make(map[string]*yarnEntry)
+	var entry *yarnEntry
+	var section map[string]string
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, " \r")
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		switch indent := len(line) - len(trimmed); {
+		case indent == 0:
+			if !strings.HasSuffix(line, ":") {
+				return nil, fmt.Errorf("%w: line %d: expected package descriptors", dep_errors.ErrInvalidLockfile, i+1)
+			}
+			entry, section = &yarnEntry{}, nil
+			for _, descriptor := range strings.Split(strings.TrimSuffix(line, ":"), ",") {
+				entries[unquoteYarn(strings.TrimSpace(descriptor))] = entry
+			}
+		case entry == nil:
+			return nil, fmt.Errorf("%w: line %d: unexpected indentation", dep_errors.ErrInvalidLockfile, i+1)
+		case indent == 2:
+			key, value := splitYarnLine(trimmed)
+			section = nil
+			switch key {
+			case "version":
+				entry.Version = value
+			case "dependencies":
+				entry.Dependencies = make(map[string]string)
+				section = entry.Dependencies
+			case "optionalDependencies":
+				entry.OptionalDependencies = make(map[string]string)
+				section = entry.OptionalDependencies
+			}
+		default:
+			// fields of sections other than dependencies are ignored
+			if section != nil {
+				key, value := splitYarnLine(trimmed)
+				section[key] = value
+			}
+		}
+	}
+	descriptors := make([]string, 0, len(entries))
+	for descriptor := range entries {
+		descriptors = append(descriptors, descriptor)
+	}
+	sort.Strings(descriptors)
+	for _, descriptor := range descriptors {
+		if entry := entries[descriptor]; entry.pkg.Name == "" {
+			entry.pkg = models.Package{Ecosystem: models.Npm, Name: yarnPackageName(descriptor), Version: entry.Version}
+		}
+	}
+	return entries, nil
+}
+
+// yarnPackageName returns the name of the package a descriptor of the classic
+// format locks. Aliases such as "string-width-cjs@npm:string-width@^4.2.0"
+// lock the package they point to.
+func yarnPackageName(descriptor string) string {
+	name, constraint := splitDescriptor(descriptor)
+	if strings.HasPrefix(constraint, "npm:") && strings.Contains(constraint[len("npm:"):], "@") {
+		name, _ = splitDescriptor(constraint[len("npm:"):])
+	}
+	return name
+}
+
+// parseYarnBerry parses yarn.lock of yarn 2 and later, which is a YAML document.
+func parseYarnBerry(data []byte) (map[string]*yarnEntry, error) {
+	var document map[string]*yarnEntry
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidLockfile, err)
+	}
+	delete(document, "__metadata")
+
+	entries := make(map[string]*yarnEntry)
+	for key, entry := range document {
+		if entry == nil {
+			continue
+		}
+		name, _ := splitDescriptor(entry.Resolution)
+		version := entry.Version
+		if version == "0.0.0-use.local" {
+			// workspaces don't record their version
+			version = ""
+		}
+		entry.pkg = models.Package{Ecosystem: models.Npm, Name: name, Version: version}
+		for _, descriptor := range strings.Split(key, ",") {
+			entries[strings.TrimSpace(descriptor)] = entry
+		}
+	}
+	return entries, nil
+}
+
+type yarnLock struct {
+	g       *Graph
+	entries map[string]*yarnEntry
+	// workspaces are workspace packages by name
+	workspaces map[string]models.Package
+}
+
+func (l *yarnLock) lookup(name, constraint string) (models.Package, bool) {
+	if entry, ok := l.entries[name+"@"+constraint]; ok {
+		return entry.pkg, true
+	}
+	// Berry stores ranges of the npm protocol with the protocol
+	if entry, ok := l.entries[name+"@npm:"+constraint]; ok {
+		return entry.pkg, true
+	}
+	pkg, ok := l.workspaces[name]
+	return pkg, ok
+}
+
+func (l *yarnLock) addDependency(from models.Package, name, constraint, kind string, optional bool) {
+	pkg, ok := l.lookup(name, constraint)
+	if !ok {
+		// optional dependencies may be missing on this platform
+		if optional {
+			return
+		}
+		pkg = models.Package{Ecosystem: models.Npm, Name: name}
+	}
+	l.g.AddDependency(from, models.Dependency{Package: pkg, Constraint: constraint, Kind: kind, Optional: optional})
+}
+
+// addEntries adds edges of locked packages.
+func (l *yarnLock) addEntries() {
+	descriptors := make([]string, 0, len(l.entries))
+	for descriptor := range l.entries {
+		descriptors = append(descriptors, descriptor)
+	}
+	sort.Strings(descriptors)
+
+	done := make(map[*yarnEntry]struct{})
+	for _, descriptor := range descriptors {
+		entry := l.entries[descriptor]
+		if _, ok := done[entry]; ok || strings.Contains(entry.Resolution, "@workspace:") {
+			continue
+		}
+		done[entry] = struct{}{}
+		l.g.AddPackage(entry.pkg)
+
+		for _, name := range sortedKeys(entry.Dependencies) {
+			optional := entry.DependenciesMeta[name].Optional
+			kind := npm.KindProd
+			if optional {
+				kind = npm.KindOptional
+			}
+			l.addDependency(entry.pkg, name, entry.Dependencies[name], kind, optional)
+		}
+		for _, name := range sortedKeys(entry.OptionalDependencies) {
+			l.addDependency(entry.pkg, name, entry.OptionalDependencies[name], npm.KindOptional, true)
+		}
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parseYarnLock reads yarn.lock. The classic format doesn't record the
+// project and its workspaces, so they are read from package.json files.
+// Peer dependencies are not drawn as yarn doesn't lock them.
+func parseYarnLock(fsys fs.FS, data []byte, project string) (*Graph, error) {
+	if bytes.Contains(data, []byte("\n__metadata:")) || bytes.HasPrefix(data, []byte("__metadata:")) {
+		return parseYarnBerryLock(fsys, data, project)
+	}
+
+	entries, err := parseYarnClassic(data)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := readManifest(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("%w: yarn.lock v1 requires package.json next to it", dep_errors.ErrInvalidLockfile)
+	}
+
+	root := models.Package{Ecosystem: models.Npm, Name: manifest.Name, Version: manifest.Version}
+	if root.Name == "" {
+		root.Name = project
+	}
+	l := &yarnLock{g: NewGraph(root), entries: entries, workspaces: make(map[string]models.Package)}
+
+	dirs, err := workspaceDirs(fsys, manifest.Workspaces, "package.json")
+	if err != nil {
+		return nil, err
+	}
+	manifests := map[models.Package]*packageEntry{root: manifest}
+	for _, dir := range dirs {
+		m, err := readManifest(fsys, dir)
+		if err != nil {
+			return nil, err
+		}
+		pkg := models.Package{Ecosystem: models.Npm, Name: m.Name, Version: m.Version}
+		if pkg.Name == "" {
+			pkg.Name = dir
+		}
+		l.workspaces[pkg.Name] = pkg
+		manifests[pkg] = m
+		l.g.AddDependency(root, models.Dependency{Package: pkg, Kind: KindWorkspace})
+	}
+
+	for pkg, m := range manifests {
+		for _, declared := range declaredDependencies(m) {
+			l.addDependency(pkg, declared.name, declared.constraint, declared.kind, declared.optional)
+		}
+	}
+	l.addEntries()
+	return l.g, nil
+}
+
+func parseYarnBerryLock(fsys fs.FS, data []byte, project string) (*Graph, error) {
+	entries, err := parseYarnBerry(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// workspaces are locked with the workspace protocol and their path
+	workspaceEntries := make(map[string]*yarnEntry)
+	for _, entry := range entries {
+		if _, dir, ok := strings.Cut(entry.Resolution, "@workspace:"); ok {
+			workspaceEntries[dir] = entry
+		}
+	}
+	rootEntry, ok := workspaceEntries["."]
+	if !ok {
+		return nil, fmt.Errorf("%w: root workspace is missing", dep_errors.ErrInvalidLockfile)
+	}
+
+	dirs := make([]string, 0, len(workspaceEntries))
+	for dir, entry := range workspaceEntries {
+		// the version of a workspace is only known from its manifest
+		m, err := readManifest(fsys, dir)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil && m.Version != "" {
+			entry.pkg.Version = m.Version
+		}
+		if entry.pkg.Name == "" {
+			entry.pkg.Name = project
+		}
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	l := &yarnLock{g: NewGraph(rootEntry.pkg), entries: entries, workspaces: make(map[string]models.Package)}
+	for _, dir := range dirs {
+		entry := workspaceEntries[dir]
+		l.workspaces[entry.pkg.Name] = entry.pkg
+		if dir != "." {
+			l.g.AddDependency(rootEntry.pkg, models.Dependency{Package: entry.pkg, Kind: KindWorkspace})
+		}
+	}
+
+	for _, dir := range dirs {
+		entry := workspaceEntries[dir]
+		// the lockfile merges dev dependencies of workspaces into dependencies
+		m, err := readManifest(fsys, dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range sortedKeys(entry.Dependencies) {
+			kind, optional := npm.KindProd, entry.DependenciesMeta[name].Optional
+			if m != nil {
+				_, prod := m.Dependencies[name]
+				if _, dev := m.DevDependencies[name]; dev && !prod {
+					kind = npm.KindDev
+				}
+			}
+			if optional {
+				kind = npm.KindOptional
+			}
+			l.addDependency(entry.pkg, name, entry.Dependencies[name], kind, optional)
+		}
+	}
+	l.addEntries()
+	return l.g, nil
+}
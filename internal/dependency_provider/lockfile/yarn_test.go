@@ -0,0 +1,223 @@
+package lockfile
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseYarnLock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("test parses classic lockfile with workspaces", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"package.json": {Data: []byte(`
+{
+	"name": "monorepo",
+	"private": true,
+	"workspaces": {"packages": ["packages/*"]},
+	"devDependencies": {"tester": "^2.0.0"}
+}
+`)},
+			"packages/core/package.json": {Data: []byte(`{"name": "@scope/core", "version": "0.1.0"}`)},
+			"packages/web/package.json": {Data: []byte(`
+{
+	"name": "@scope/web",
+	"version": "0.2.0",
+	"dependencies": {"@scope/core": "0.1.0", "@babel/code-frame": "^7.10.4"}
+}
+`)},
+			"yarn.lock": {Data: []byte(`# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
+# yarn lockfile v1
+
+
+"@babel/code-frame@^7.0.0", "@babel/code-frame@^7.10.4":
+  version "7.12.13"
+  resolved "https://registry.yarnpkg.com/@babel/code-frame/-/code-frame-7.12.13.tgz"
+  integrity sha512-HV1Cm0Q3ZrpCR93tkWOYiuYIgLxZXZFVG2VgK+MBWjUqZTundupbfx2aXarXuw5Ko5aMcjtJgbSs4vUGBS5v6g==
+  dependencies:
+    "@babel/highlight" "^7.12.13"
+
+"@babel/highlight@^7.12.13":
+  version "7.13.10"
+  optionalDependencies:
+    fsevents "~2.3.1"
+
+tester@^2.0.0:
+  version "2.1.0"
+`)},
+		}
+		g, err := parse(fsys, "yarn.lock", "project")
+		assert.NoError(t, err)
+		assert.Equal(t, npmPackage("monorepo", ""), g.Root)
+
+		deps, err := g.FetchPackageDeps(ctx, g.Root)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("@scope/core", "0.1.0"), Kind: KindWorkspace},
+			{Package: npmPackage("@scope/web", "0.2.0"), Kind: KindWorkspace},
+			{Package: npmPackage("tester", "2.1.0"), Constraint: "^2.0.0", Kind: "dev"},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, npmPackage("@scope/web", "0.2.0"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("@babel/code-frame", "7.12.13"), Constraint: "^7.10.4", Kind: "prod"},
+			{Package: npmPackage("@scope/core", "0.1.0"), Constraint: "0.1.0", Kind: "prod"},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, npmPackage("@babel/code-frame", "7.12.13"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("@babel/highlight", "7.13.10"), Constraint: "^7.12.13", Kind: "prod"},
+		}, deps)
+
+		// fsevents is not installed on this platform
+		deps, err = g.FetchPackageDeps(ctx, npmPackage("@babel/highlight", "7.13.10"))
+		assert.NoError(t, err)
+		assert.Empty(t, deps)
+	})
+
+	t.Run("test names aliased packages of classic lockfile after their target", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"package.json": {Data: []byte(`{"name": "app", "dependencies": {"cliui": "^8.0.1"}}`)},
+			"yarn.lock": {Data: []byte(`# yarn lockfile v1
+
+
+cliui@^8.0.1:
+  version "8.0.1"
+  dependencies:
+    string-width "^4.2.0"
+    string-width-cjs "npm:string-width@^4.2.0"
+
+"string-width-cjs@npm:string-width@^4.2.0", string-width@^4.2.0:
+  version "4.2.3"
+`)},
+		}
+		// entries are kept in a map, the name must not depend on its order
+		for i := 0; i < 20; i++ {
+			g, err := parse(fsys, "yarn.lock", "project")
+			assert.NoError(t, err)
+
+			deps, err := g.FetchPackageDeps(ctx, npmPackage("cliui", "8.0.1"))
+			assert.NoError(t, err)
+			// both descriptors lock the same package, so there's a single edge
+			assert.Equal(t, []models.Dependency{
+				{Package: npmPackage("string-width", "4.2.3"), Constraint: "^4.2.0", Kind: "prod"},
+			}, deps)
+			_, err = g.Resolve(ctx, "string-width-cjs")
+			assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+		}
+	})
+
+	t.Run("test requires package.json for classic lockfile", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"yarn.lock": {Data: []byte("# yarn lockfile v1\n\ntester@^2.0.0:\n  version \"2.1.0\"\n")},
+		}
+		_, err := parse(fsys, "yarn.lock", "project")
+		assert.ErrorIs(t, err, dep_errors.ErrInvalidLockfile)
+	})
+
+	t.Run("test parses berry lockfile with workspaces", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"packages/web/package.json": {Data: []byte(`
+{
+	"name": "@scope/web",
+	"version": "0.2.0",
+	"dependencies": {"@scope/core": "workspace:^"},
+	"devDependencies": {"typescript": "^5.0.0"}
+}
+`)},
+			"yarn.lock": {Data: []byte(`# This file is generated by running "yarn install" inside your project.
+
+__metadata:
+  version: 6
+  cacheKey: 8
+
+"@scope/core@workspace:^, @scope/core@workspace:packages/core":
+  version: 0.0.0-use.local
+  resolution: "@scope/core@workspace:packages/core"
+  dependencies:
+    chokidar: ^3.5.0
+  languageName: unknown
+  linkType: soft
+
+"@scope/web@workspace:packages/web":
+  version: 0.0.0-use.local
+  resolution: "@scope/web@workspace:packages/web"
+  dependencies:
+    "@scope/core": "workspace:^"
+    typescript: ^5.0.0
+  languageName: unknown
+  linkType: soft
+
+"chokidar@npm:^3.5.0":
+  version: 3.5.3
+  resolution: "chokidar@npm:3.5.3"
+  dependencies:
+    fsevents: ~2.3.2
+  dependenciesMeta:
+    fsevents:
+      optional: true
+  checksum: b49fcde401
+  languageName: node
+  linkType: hard
+
+"fsevents@npm:~2.3.2":
+  version: 2.3.3
+  resolution: "fsevents@npm:2.3.3"
+  conditions: os=darwin
+  languageName: node
+  linkType: hard
+
+"monorepo@workspace:.":
+  version: 0.0.0-use.local
+  resolution: "monorepo@workspace:."
+  languageName: unknown
+  linkType: soft
+
+"typescript@npm:^5.0.0":
+  version: 5.2.2
+  resolution: "typescript@npm:5.2.2"
+  languageName: node
+  linkType: hard
+`)},
+		}
+		g, err := parse(fsys, "yarn.lock", "project")
+		assert.NoError(t, err)
+		assert.Equal(t, npmPackage("monorepo", ""), g.Root)
+
+		deps, err := g.FetchPackageDeps(ctx, g.Root)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("@scope/core", ""), Kind: KindWorkspace},
+			{Package: npmPackage("@scope/web", "0.2.0"), Kind: KindWorkspace},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, npmPackage("@scope/web", "0.2.0"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("@scope/core", ""), Constraint: "workspace:^", Kind: "prod"},
+			{Package: npmPackage("typescript", "5.2.2"), Constraint: "^5.0.0", Kind: "dev"},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, npmPackage("chokidar", "3.5.3"))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: npmPackage("fsevents", "2.3.3"), Constraint: "~2.3.2", Kind: "optional", Optional: true},
+		}, deps)
+	})
+}
+
+func Test_splitDescriptor(t *testing.T) {
+	name, constraint := splitDescriptor("@babel/core@npm:^7.0.0")
+	assert.Equal(t, "@babel/core", name)
+	assert.Equal(t, "npm:^7.0.0", constraint)
+
+	name, constraint = splitDescriptor("lodash@^4.17.0")
+	assert.Equal(t, "lodash", name)
+	assert.Equal(t, "^4.17.0", constraint)
+}
@@ -0,0 +1,371 @@
+package maven
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Scopes of dependencies that are part of the runtime classpath of dependents.
+const (
+	ScopeCompile  = "compile"
+	ScopeRuntime  = "runtime"
+	ScopeProvided = "provided"
+	ScopeTest     = "test"
+	ScopeSystem   = "system"
+)
+
+// DefaultScopes are the scopes maven resolves transitively.
+var DefaultScopes = []string{ScopeCompile, ScopeRuntime}
+
+const _maxModelDepth = 32
+
+type DependencyProvider struct {
+	// BaseURL is the root of a repository with the maven2 layout.
+	BaseURL string
+	Client  *http.Client
+	// Scopes lists dependency scopes that are traversed. DefaultScopes is used if it is empty.
+	Scopes []string
+	// IncludeOptional makes dependencies marked <optional>true</optional> part of the graph.
+	IncludeOptional bool
+
+	mu    sync.Mutex
+	cache map[string]*pom
+	// managed is the dependencyManagement of the resolved package. Like maven,
+	// it overrides versions of transitive dependencies in the whole graph.
+	managed map[string]dependency
+}
+
+func Default() *DependencyProvider {
+	return &DependencyProvider{
+		BaseURL: "https://repo.maven.apache.org/maven2",
+		Client:  &http.Client{},
+	}
+}
+
+type coordinates struct {
+	GroupID    string
+	ArtifactID string
+	Version    string
+}
+
+func (c coordinates) String() string {
+	if c.Version == "" {
+		return c.GroupID + ":" + c.ArtifactID
+	}
+	return c.GroupID + ":" + c.ArtifactID + ":" + c.Version
+}
+
+func (c coordinates) Package() models.Package {
+	return models.Package{Ecosystem: models.Maven, Name: c.GroupID + ":" + c.ArtifactID, Version: c.Version}
+}
+
+func parseCoordinates(packageName string) (coordinates, error) {
+	parts := strings.Split(packageName, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return coordinates{}, fmt.Errorf("%w: %q is not groupId:artifactId[:version]", dep_errors.ErrInvalidPackageName, packageName)
+	}
+	c := coordinates{GroupID: parts[0], ArtifactID: parts[1]}
+	if len(parts) == 3 {
+		c.Version = parts[2]
+	}
+	return c, nil
+}
+
+func (d *DependencyProvider) fetch(ctx context.Context, name string, elem ...string) (io.ReadCloser, error) {
+	uri, err := url.JoinPath(d.BaseURL, elem...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	} else if resp.StatusCode == http.StatusNotFound {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrPackageNotFound, name)
+	} else if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%w: unexpected status %d for %s", dep_errors.ErrFetch, resp.StatusCode, name)
+	}
+	return resp.Body, nil
+}
+
+func groupPath(groupID string) []string {
+	return strings.Split(groupID, ".")
+}
+
+func (d *DependencyProvider) fetchMetadata(ctx context.Context, c coordinates) (*metadata, error) {
+	elem := append(groupPath(c.GroupID), c.ArtifactID, "maven-metadata.xml")
+	body, err := d.fetch(ctx, c.String(), elem...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+	return parseMetadata(body)
+}
+
+// resolveVersion turns an empty version or a version range into a concrete version.
+func (d *DependencyProvider) resolveVersion(ctx context.Context, c coordinates) (string, error) {
+	if c.Version != "" && !isVersionRange(c.Version) && c.Version != "LATEST" && c.Version != "RELEASE" {
+		return c.Version, nil
+	}
+	m, err := d.fetchMetadata(ctx, c)
+	if err != nil {
+		return "", err
+	}
+	if isVersionRange(c.Version) {
+		if version := highestInRange(c.Version, m.Versioning.Versions); version != "" {
+			return version, nil
+		}
+		return "", fmt.Errorf("%w: no version of %s matches %s", dep_errors.ErrPackageNotFound, c, c.Version)
+	}
+	if c.Version == "LATEST" && m.Versioning.Latest != "" {
+		return m.Versioning.Latest, nil
+	}
+	if m.Versioning.Release != "" {
+		return m.Versioning.Release, nil
+	}
+	best := ""
+	for _, version := range m.Versioning.Versions {
+		if best == "" || compareVersions(version, best) > 0 {
+			best = version
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("%w: %s has no versions", dep_errors.ErrPackageNotFound, c)
+	}
+	return best, nil
+}
+
+// loadPom downloads a pom and caches it. The returned model must not be modified.
+func (d *DependencyProvider) loadPom(ctx context.Context, c coordinates) (*pom, error) {
+	key := c.String()
+	d.mu.Lock()
+	cached, ok := d.cache[key]
+	d.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	elem := append(groupPath(c.GroupID), c.ArtifactID, c.Version, c.ArtifactID+"-"+c.Version+".pom")
+	body, err := d.fetch(ctx, key, elem...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+	result, err := parsePom(body)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	if d.cache == nil {
+		d.cache = make(map[string]*pom)
+	}
+	d.cache[key] = result
+	d.mu.Unlock()
+	return result, nil
+}
+
+// inheritedPom returns the pom merged with all of its parents, but not interpolated yet.
+func (d *DependencyProvider) inheritedPom(ctx context.Context, c coordinates, depth int) (*pom, error) {
+	if depth > _maxModelDepth {
+		return nil, fmt.Errorf("%w: parent chain of %s is too deep", dep_errors.ErrFetch, c)
+	}
+	raw, err := d.loadPom(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	result := raw.clone()
+	if result.ArtifactID == "" {
+		result.ArtifactID = c.ArtifactID
+	}
+	if result.Parent == nil {
+		return result, nil
+	}
+
+	parentCoordinates := coordinates{
+		GroupID:    result.Parent.GroupID,
+		ArtifactID: result.Parent.ArtifactID,
+		Version:    result.Parent.Version,
+	}
+	parentPom, err := d.inheritedPom(ctx, parentCoordinates, depth+1)
+	if err != nil {
+		return nil, fmt.Errorf("can't load parent of %s: %w", c, err)
+	}
+	result.inherit(parentPom)
+	return result, nil
+}
+
+// effectivePom builds the model maven would use: inheritance, interpolation,
+// BOM imports and dependency management are applied.
+func (d *DependencyProvider) effectivePom(ctx context.Context, c coordinates, depth int) (*pom, error) {
+	result, err := d.inheritedPom(ctx, c, depth)
+	if err != nil {
+		return nil, err
+	}
+	result.interpolate()
+
+	managed := make([]dependency, 0, len(result.DependencyManagement.Dependencies))
+	var imported []dependency
+	for _, dep := range result.DependencyManagement.Dependencies {
+		if !dep.isImport() {
+			managed = append(managed, dep)
+			continue
+		}
+		if dep.isUnresolved() {
+			continue
+		}
+		bomCoordinates := coordinates{GroupID: dep.GroupID, ArtifactID: dep.ArtifactID, Version: dep.Version}
+		if bomCoordinates.Version, err = d.resolveVersion(ctx, bomCoordinates); err != nil {
+			return nil, err
+		}
+		if depth > _maxModelDepth {
+			return nil, fmt.Errorf("%w: BOM imports of %s are too deep", dep_errors.ErrFetch, c)
+		}
+		bom, err := d.effectivePom(ctx, bomCoordinates, depth+1)
+		if err != nil {
+			return nil, fmt.Errorf("can't import BOM %s: %w", bomCoordinates, err)
+		}
+		// the first import wins, and declared entries win over imported ones
+		imported = mergeDependencies(imported, bom.DependencyManagement.Dependencies)
+	}
+	result.DependencyManagement.Dependencies = mergeDependencies(managed, imported)
+	result.applyManagement()
+	return result, nil
+}
+
+func (d *DependencyProvider) includes(dep *dependency) bool {
+	scopes := d.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
+	scope := dep.Scope
+	if scope == "" {
+		scope = ScopeCompile
+	}
+	if !contains(scopes, scope) {
+		return false
+	}
+	return d.IncludeOptional || strings.TrimSpace(dep.Optional) != "true"
+}
+
+// Resolve accepts "groupId:artifactId[:version]" where version may be a range,
+// LATEST or RELEASE. Package names are "groupId:artifactId".
+func (d *DependencyProvider) Resolve(ctx context.Context, spec string) (models.Package, error) {
+	c, err := parseCoordinates(spec)
+	if err != nil {
+		return models.Package{}, err
+	}
+	if c.Version, err = d.resolveVersion(ctx, c); err != nil {
+		return models.Package{}, err
+	}
+
+	model, err := d.effectivePom(ctx, c, 0)
+	if err != nil {
+		return models.Package{}, err
+	}
+	d.managed = make(map[string]dependency, len(model.DependencyManagement.Dependencies))
+	for _, dep := range model.DependencyManagement.Dependencies {
+		if _, ok := d.managed[dep.managementKey()]; !ok && !dep.isUnresolved() {
+			d.managed[dep.managementKey()] = dep
+		}
+	}
+	return c.Package(), nil
+}
+
+// FetchRootDeps returns dependencies of the effective pom of the root. Its
+// own versions win over its dependencyManagement.
+func (d *DependencyProvider) FetchRootDeps(ctx context.Context, root models.Package) ([]models.Dependency, error) {
+	return d.fetchDeps(ctx, root, nil)
+}
+
+// FetchPackageDeps returns dependencies of the effective pom. The release
+// version is used if the package has no version. Versions managed by the
+// resolved package replace the ones of the pom.
+//
+// Dependencies with ${...} expressions which can't be interpolated are left
+// out instead of failing the whole graph.
+func (d *DependencyProvider) FetchPackageDeps(ctx context.Context, pkg models.Package) ([]models.Dependency, error) {
+	return d.fetchDeps(ctx, pkg, d.managed)
+}
+
+func (d *DependencyProvider) fetchDeps(ctx context.Context, pkg models.Package, managed map[string]dependency) ([]models.Dependency, error) {
+	c, err := parseCoordinates(pkg.Name)
+	if err != nil {
+		return nil, err
+	}
+	c.Version = pkg.Version
+	if c.Version, err = d.resolveVersion(ctx, c); err != nil {
+		return nil, err
+	}
+
+	model, err := d.effectivePom(ctx, c, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(model.Dependencies))
+	result := make([]models.Dependency, 0, len(model.Dependencies))
+	for i := range model.Dependencies {
+		dep := &model.Dependencies[i]
+		if !d.includes(dep) {
+			continue
+		}
+		version := dep.Version
+		if m, ok := managed[dep.managementKey()]; ok && m.Version != "" {
+			version = m.Version
+		}
+		depCoordinates := coordinates{GroupID: dep.GroupID, ArtifactID: dep.ArtifactID, Version: version}
+		if strings.Contains(depCoordinates.String(), "${") {
+			continue
+		}
+		if isVersionRange(version) {
+			if depCoordinates.Version, err = d.resolveVersion(ctx, depCoordinates); err != nil {
+				return nil, err
+			}
+		}
+		name := depCoordinates.String()
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+
+		scope := dep.Scope
+		if scope == "" {
+			scope = ScopeCompile
+		}
+		result = append(result, models.Dependency{
+			Package:    depCoordinates.Package(),
+			Constraint: dep.Version,
+			Kind:       scope,
+			Optional:   strings.TrimSpace(dep.Optional) == "true",
+		})
+	}
+	return result, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
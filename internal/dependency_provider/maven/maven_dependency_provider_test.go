@@ -0,0 +1,285 @@
+package maven
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+)
+
+func newRepository() *http.ServeMux {
+	files := map[string]string{
+		"/org/example/app/maven-metadata.xml": `<metadata>
+			<versioning><latest>2.0-SNAPSHOT</latest><release>1.0</release>
+			<versions><version>1.0</version><version>2.0-SNAPSHOT</version></versions></versioning>
+		</metadata>`,
+		"/org/example/app/1.0/app-1.0.pom": `<?xml version="1.0" encoding="UTF-8"?>
+		<project xmlns="http://maven.apache.org/POM/4.0.0">
+			<parent>
+				<groupId>org.example</groupId>
+				<artifactId>parent</artifactId>
+				<version>3</version>
+			</parent>
+			<artifactId>app</artifactId>
+			<version>1.0</version>
+			<properties>
+				<guava.version>32.0.0-jre</guava.version>
+			</properties>
+			<dependencies>
+				<dependency>
+					<groupId>com.google.guava</groupId>
+					<artifactId>guava</artifactId>
+				</dependency>
+				<dependency>
+					<groupId>org.example</groupId>
+					<artifactId>core</artifactId>
+					<version>${project.version}</version>
+				</dependency>
+				<dependency>
+					<groupId>com.fasterxml.jackson.core</groupId>
+					<artifactId>jackson-databind</artifactId>
+				</dependency>
+				<dependency>
+					<groupId>org.slf4j</groupId>
+					<artifactId>slf4j-api</artifactId>
+					<version>[1.7,2.0)</version>
+				</dependency>
+				<dependency>
+					<groupId>javax.servlet</groupId>
+					<artifactId>servlet-api</artifactId>
+					<version>2.5</version>
+					<scope>provided</scope>
+				</dependency>
+				<dependency>
+					<groupId>org.example</groupId>
+					<artifactId>extras</artifactId>
+					<version>1.0</version>
+					<optional>true</optional>
+				</dependency>
+			</dependencies>
+		</project>`,
+		"/org/example/parent/3/parent-3.pom": `<project>
+			<groupId>org.example</groupId>
+			<artifactId>parent</artifactId>
+			<version>3</version>
+			<packaging>pom</packaging>
+			<properties>
+				<guava.version>31.0-jre</guava.version>
+				<jackson.bom.version>2.15.0</jackson.bom.version>
+			</properties>
+			<dependencyManagement>
+				<dependencies>
+					<dependency>
+						<groupId>com.google.guava</groupId>
+						<artifactId>guava</artifactId>
+						<version>${guava.version}</version>
+					</dependency>
+					<dependency>
+						<groupId>com.fasterxml.jackson</groupId>
+						<artifactId>jackson-bom</artifactId>
+						<version>${jackson.bom.version}</version>
+						<type>pom</type>
+						<scope>import</scope>
+					</dependency>
+				</dependencies>
+			</dependencyManagement>
+			<dependencies>
+				<dependency>
+					<groupId>junit</groupId>
+					<artifactId>junit</artifactId>
+					<version>4.13</version>
+					<scope>test</scope>
+				</dependency>
+			</dependencies>
+		</project>`,
+		"/com/fasterxml/jackson/jackson-bom/2.15.0/jackson-bom-2.15.0.pom": `<project>
+			<groupId>com.fasterxml.jackson</groupId>
+			<artifactId>jackson-bom</artifactId>
+			<version>2.15.0</version>
+			<dependencyManagement>
+				<dependencies>
+					<dependency>
+						<groupId>com.fasterxml.jackson.core</groupId>
+						<artifactId>jackson-databind</artifactId>
+						<version>${project.version}</version>
+					</dependency>
+				</dependencies>
+			</dependencyManagement>
+		</project>`,
+		"/org/example/core/1.0/core-1.0.pom": `<project>
+			<groupId>org.example</groupId>
+			<artifactId>core</artifactId>
+			<version>1.0</version>
+			<dependencies>
+				<dependency>
+					<groupId>com.google.guava</groupId>
+					<artifactId>guava</artifactId>
+					<version>30.0-jre</version>
+				</dependency>
+				<dependency>
+					<groupId>org.example</groupId>
+					<artifactId>plugin</artifactId>
+					<version>${plugin.version}</version>
+				</dependency>
+				<dependency>
+					<groupId>commons-io</groupId>
+					<artifactId>commons-io</artifactId>
+					<version>2.11.0</version>
+				</dependency>
+			</dependencies>
+		</project>`,
+		"/org/slf4j/slf4j-api/maven-metadata.xml": `<metadata><versioning>
+			<versions><version>1.7.30</version><version>1.7.36</version><version>2.0.9</version></versions>
+		</versioning></metadata>`,
+		"/org/example/broken/1.0/broken-1.0.pom": `<project>`,
+		"/org/example/orphan/1.0/orphan-1.0.pom": `<project>
+			<parent><groupId>org.example</groupId><artifactId>missing</artifactId><version>1</version></parent>
+			<artifactId>orphan</artifactId>
+		</project>`,
+	}
+
+	mux := http.NewServeMux()
+	for path, content := range files {
+		content := content
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(content))
+		})
+	}
+	return mux
+}
+
+func TestDependencyProvider_FetchPackageDeps(t *testing.T) {
+	srv := httptest.NewServer(newRepository())
+	defer srv.Close()
+
+	t.Run("test resolving parents, boms and properties", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}}
+		pkg, err := d.Resolve(ctx, "org.example:app")
+		assert.NoError(t, err)
+		assert.Equal(t, models.Package{Ecosystem: models.Maven, Name: "org.example:app", Version: "1.0"}, pkg)
+
+		deps, err := d.FetchRootDeps(ctx, pkg)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{
+				Package:    models.Package{Ecosystem: models.Maven, Name: "com.google.guava:guava", Version: "32.0.0-jre"},
+				Constraint: "32.0.0-jre",
+				Kind:       ScopeCompile,
+			},
+			{
+				Package:    models.Package{Ecosystem: models.Maven, Name: "org.example:core", Version: "1.0"},
+				Constraint: "1.0",
+				Kind:       ScopeCompile,
+			},
+			{
+				Package:    models.Package{Ecosystem: models.Maven, Name: "com.fasterxml.jackson.core:jackson-databind", Version: "2.15.0"},
+				Constraint: "2.15.0",
+				Kind:       ScopeCompile,
+			},
+			{
+				Package:    models.Package{Ecosystem: models.Maven, Name: "org.slf4j:slf4j-api", Version: "1.7.36"},
+				Constraint: "[1.7,2.0)",
+				Kind:       ScopeCompile,
+			},
+		}, deps)
+	})
+
+	t.Run("test dependency management of the root applies to transitive dependencies", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}}
+		_, err := d.Resolve(ctx, "org.example:app:1.0")
+		assert.NoError(t, err)
+
+		deps, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Maven, Name: "org.example:core", Version: "1.0"})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{
+				Package:    models.Package{Ecosystem: models.Maven, Name: "com.google.guava:guava", Version: "32.0.0-jre"},
+				Constraint: "30.0-jre",
+				Kind:       ScopeCompile,
+			},
+			{
+				Package:    models.Package{Ecosystem: models.Maven, Name: "commons-io:commons-io", Version: "2.11.0"},
+				Constraint: "2.11.0",
+				Kind:       ScopeCompile,
+			},
+		}, deps)
+
+		d = DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}}
+		deps, err = d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Maven, Name: "org.example:core", Version: "1.0"})
+		assert.NoError(t, err)
+		assert.Equal(t, "com.google.guava:guava@30.0-jre", deps[0].Package.String())
+	})
+
+	t.Run("test selecting scopes and optional dependencies", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{
+			BaseURL:         srv.URL,
+			Client:          &http.Client{},
+			Scopes:          []string{ScopeProvided, ScopeTest},
+			IncludeOptional: true,
+		}
+		pkg, err := d.Resolve(ctx, "org.example:app:1.0")
+		assert.NoError(t, err)
+		deps, err := d.FetchPackageDeps(ctx, pkg)
+		assert.NoError(t, err)
+		names := make([]string, 0, len(deps))
+		for _, dep := range deps {
+			names = append(names, dep.Package.String())
+		}
+		sort.Strings(names)
+		assert.Equal(t, []string{"javax.servlet:servlet-api@2.5", "junit:junit@4.13"}, names)
+	})
+
+	t.Run("test fetching if package not found", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}}
+		_, err := d.Resolve(ctx, "org.example:missing")
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+
+		_, err = d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Maven, Name: "org.example:orphan", Version: "1.0"})
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+	})
+
+	t.Run("test fetching if pom is invalid", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}}
+		_, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Maven, Name: "org.example:broken", Version: "1.0"})
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+	})
+
+	t.Run("test invalid coordinates", func(t *testing.T) {
+		d := DependencyProvider{BaseURL: srv.URL, Client: &http.Client{}}
+		_, err := d.Resolve(context.Background(), "guava")
+		assert.ErrorIs(t, err, dep_errors.ErrInvalidPackageName)
+	})
+
+	t.Run("test if fetching url is invalid", func(t *testing.T) {
+		d := DependencyProvider{BaseURL: ":::", Client: &http.Client{}}
+		_, err := d.FetchPackageDeps(context.Background(), models.Package{Ecosystem: models.Maven, Name: "org.example:app", Version: "1.0"})
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+	})
+}
+
+func TestDefault(t *testing.T) {
+	d := Default()
+	assert.NotNil(t, d)
+	assert.NotNil(t, d.Client)
+	assert.Equal(t, "https://repo.maven.apache.org/maven2", d.BaseURL)
+}
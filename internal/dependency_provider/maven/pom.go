@@ -0,0 +1,252 @@
+package maven
+
+import (
+	"depviz/internal/dependency_provider/dep_errors"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type properties map[string]string
+
+func (p *properties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*p = make(properties)
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			var value string
+			if err := d.DecodeElement(&value, &t); err != nil {
+				return err
+			}
+			(*p)[t.Name.Local] = strings.TrimSpace(value)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+type parent struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+type dependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Type       string `xml:"type"`
+	Classifier string `xml:"classifier"`
+	Scope      string `xml:"scope"`
+	Optional   string `xml:"optional"`
+}
+
+// managementKey identifies a dependency in dependencyManagement sections.
+func (d *dependency) managementKey() string {
+	typ := d.Type
+	if typ == "" {
+		typ = "jar"
+	}
+	return d.GroupID + ":" + d.ArtifactID + ":" + typ + ":" + d.Classifier
+}
+
+// isUnresolved reports whether coordinates still contain ${...} expressions
+// after interpolation, e.g. properties defined nowhere in the model.
+func (d *dependency) isUnresolved() bool {
+	return strings.Contains(d.GroupID+d.ArtifactID+d.Version, "${")
+}
+
+func (d *dependency) isImport() bool {
+	return d.Scope == "import" && d.Type == "pom"
+}
+
+type pom struct {
+	GroupID              string     `xml:"groupId"`
+	ArtifactID           string     `xml:"artifactId"`
+	Version              string     `xml:"version"`
+	Parent               *parent    `xml:"parent"`
+	Properties           properties `xml:"properties"`
+	DependencyManagement struct {
+		Dependencies []dependency `xml:"dependencies>dependency"`
+	} `xml:"dependencyManagement"`
+	Dependencies []dependency `xml:"dependencies>dependency"`
+}
+
+func parsePom(reader io.Reader) (*pom, error) {
+	result := &pom{}
+	decoder := xml.NewDecoder(reader)
+	// poms in the wild sometimes declare encodings other than utf-8
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	if err := decoder.Decode(result); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	return result, nil
+}
+
+// clone returns a deep copy so that cached poms are never modified.
+func (p *pom) clone() *pom {
+	result := *p
+	if p.Parent != nil {
+		par := *p.Parent
+		result.Parent = &par
+	}
+	result.Properties = make(properties, len(p.Properties))
+	for k, v := range p.Properties {
+		result.Properties[k] = v
+	}
+	result.DependencyManagement.Dependencies = append([]dependency(nil), p.DependencyManagement.Dependencies...)
+	result.Dependencies = append([]dependency(nil), p.Dependencies...)
+	return &result
+}
+
+// inherit merges parent model into p. Values declared in p take precedence.
+func (p *pom) inherit(parent *pom) {
+	if p.GroupID == "" {
+		p.GroupID = parent.GroupID
+	}
+	if p.Version == "" {
+		p.Version = parent.Version
+	}
+	for k, v := range parent.Properties {
+		if _, ok := p.Properties[k]; !ok {
+			p.Properties[k] = v
+		}
+	}
+	p.DependencyManagement.Dependencies = mergeDependencies(p.DependencyManagement.Dependencies, parent.DependencyManagement.Dependencies)
+	p.Dependencies = mergeDependencies(p.Dependencies, parent.Dependencies)
+}
+
+func mergeDependencies(own []dependency, inherited []dependency) []dependency {
+	declared := make(map[string]struct{}, len(own))
+	for i := range own {
+		declared[own[i].managementKey()] = struct{}{}
+	}
+	for _, dep := range inherited {
+		if _, ok := declared[dep.managementKey()]; !ok {
+			own = append(own, dep)
+		}
+	}
+	return own
+}
+
+const _maxInterpolationDepth = 16
+
+// interpolate expands ${...} expressions using project properties and coordinates.
+func (p *pom) interpolate() {
+	values := make(map[string]string, len(p.Properties)+9)
+	for k, v := range p.Properties {
+		values[k] = v
+	}
+	for _, prefix := range []string{"project.", "pom.", ""} {
+		values[prefix+"groupId"] = p.GroupID
+		values[prefix+"artifactId"] = p.ArtifactID
+		values[prefix+"version"] = p.Version
+	}
+	if p.Parent != nil {
+		values["project.parent.groupId"] = p.Parent.GroupID
+		values["project.parent.artifactId"] = p.Parent.ArtifactID
+		values["project.parent.version"] = p.Parent.Version
+	}
+
+	expand := func(s string) string {
+		for i := 0; i < _maxInterpolationDepth && strings.Contains(s, "${"); i++ {
+			expanded := expandOnce(s, values)
+			if expanded == s {
+				break
+			}
+			s = expanded
+		}
+		return s
+	}
+
+	p.GroupID = expand(p.GroupID)
+	p.Version = expand(p.Version)
+	for _, deps := range [][]dependency{p.DependencyManagement.Dependencies, p.Dependencies} {
+		for i := range deps {
+			d := &deps[i]
+			d.GroupID = expand(d.GroupID)
+			d.ArtifactID = expand(d.ArtifactID)
+			d.Version = expand(d.Version)
+			d.Type = expand(d.Type)
+			d.Classifier = expand(d.Classifier)
+			d.Scope = expand(d.Scope)
+			d.Optional = expand(d.Optional)
+		}
+	}
+}
+
+func expandOnce(s string, values map[string]string) string {
+	b := &strings.Builder{}
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		end += start
+		b.WriteString(s[:start])
+		if value, ok := values[s[start+2:end]]; ok {
+			b.WriteString(value)
+		} else {
+			// unknown expressions are left untouched, as maven does
+			b.WriteString(s[start : end+1])
+		}
+		s = s[end+1:]
+	}
+}
+
+// applyManagement fills missing versions, scopes and optional flags of
+// dependencies from dependencyManagement.
+func (p *pom) applyManagement() {
+	managed := make(map[string]*dependency, len(p.DependencyManagement.Dependencies))
+	for i := range p.DependencyManagement.Dependencies {
+		d := &p.DependencyManagement.Dependencies[i]
+		if _, ok := managed[d.managementKey()]; !ok {
+			managed[d.managementKey()] = d
+		}
+	}
+	for i := range p.Dependencies {
+		d := &p.Dependencies[i]
+		m, ok := managed[d.managementKey()]
+		if !ok {
+			continue
+		}
+		if d.Version == "" {
+			d.Version = m.Version
+		}
+		if d.Scope == "" {
+			d.Scope = m.Scope
+		}
+		if d.Optional == "" {
+			d.Optional = m.Optional
+		}
+	}
+}
+
+type metadata struct {
+	Versioning struct {
+		Latest   string   `xml:"latest"`
+		Release  string   `xml:"release"`
+		Versions []string `xml:"versions>version"`
+	} `xml:"versioning"`
+}
+
+func parseMetadata(reader io.Reader) (*metadata, error) {
+	result := &metadata{}
+	if err := xml.NewDecoder(reader).Decode(result); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	return result, nil
+}
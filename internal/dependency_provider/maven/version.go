@@ -0,0 +1,221 @@
+package maven
+
+import (
+	"math/big"
+	"strings"
+)
+
+// Maven versions are compared item by item the same way ComparableVersion does:
+// numbers are compared numerically, well-known qualifiers by their order, and
+// any other qualifiers lexically after the well-known ones.
+var qualifierOrder = map[string]int{
+	"alpha":     0,
+	"a":         0,
+	"beta":      1,
+	"b":         1,
+	"milestone": 2,
+	"m":         2,
+	"rc":        3,
+	"cr":        3,
+	"snapshot":  4,
+	"":          5,
+	"ga":        5,
+	"final":     5,
+	"release":   5,
+	"sp":        6,
+}
+
+type versionItem struct {
+	number    *big.Int
+	qualifier string
+}
+
+func (i versionItem) isNull() bool {
+	if i.number != nil {
+		return i.number.Sign() == 0
+	}
+	return qualifierOrder[i.qualifier] == qualifierOrder[""] && isKnownQualifier(i.qualifier)
+}
+
+func isKnownQualifier(q string) bool {
+	_, ok := qualifierOrder[q]
+	return ok
+}
+
+func parseVersion(version string) []versionItem {
+	version = strings.ToLower(version)
+	var items []versionItem
+	start := 0
+	flush := func(end int) {
+		token := version[start:end]
+		if token == "" {
+			token = "0"
+		}
+		if n, ok := new(big.Int).SetString(token, 10); ok {
+			items = append(items, versionItem{number: n})
+		} else {
+			items = append(items, versionItem{qualifier: token})
+		}
+	}
+	for i := 0; i < len(version); i++ {
+		c := version[i]
+		if c == '.' || c == '-' || c == '_' {
+			flush(i)
+			start = i + 1
+			continue
+		}
+		if i > start && isDigit(version[i-1]) != isDigit(c) {
+			flush(i)
+			start = i
+		}
+	}
+	flush(len(version))
+
+	// trailing zeros and release qualifiers don't change the version
+	for len(items) > 1 && items[len(items)-1].isNull() {
+		items = items[:len(items)-1]
+	}
+	return items
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func compareItems(a, b *versionItem) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -compareItems(b, nil)
+	}
+	if a.number != nil {
+		if b == nil {
+			return a.number.Sign()
+		}
+		if b.number == nil {
+			return 1
+		}
+		return a.number.Cmp(b.number)
+	}
+	if b == nil {
+		b = &versionItem{}
+	} else if b.number != nil {
+		return -1
+	}
+	ao, aKnown := qualifierOrder[a.qualifier]
+	bo, bKnown := qualifierOrder[b.qualifier]
+	switch {
+	case aKnown && bKnown:
+		return compareInts(ao, bo)
+	case aKnown:
+		return -1
+	case bKnown:
+		return 1
+	}
+	return strings.Compare(a.qualifier, b.qualifier)
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareVersions compares two maven versions.
+func compareVersions(left, right string) int {
+	a, b := parseVersion(left), parseVersion(right)
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var ai, bi *versionItem
+		if i < len(a) {
+			ai = &a[i]
+		}
+		if i < len(b) {
+			bi = &b[i]
+		}
+		if c := compareItems(ai, bi); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+type versionInterval struct {
+	lower, upper                   string
+	lowerInclusive, upperInclusive bool
+}
+
+func (r versionInterval) contains(version string) bool {
+	if r.lower != "" {
+		c := compareVersions(version, r.lower)
+		if c < 0 || (c == 0 && !r.lowerInclusive) {
+			return false
+		}
+	}
+	if r.upper != "" {
+		c := compareVersions(version, r.upper)
+		if c > 0 || (c == 0 && !r.upperInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+func isVersionRange(version string) bool {
+	return strings.HasPrefix(version, "[") || strings.HasPrefix(version, "(")
+}
+
+// parseVersionRange parses specs like "[1.0,2.0)", "(,1.0]" or "[1.2],[1.5,)".
+func parseVersionRange(spec string) ([]versionInterval, bool) {
+	var result []versionInterval
+	spec = strings.TrimSpace(spec)
+	for spec != "" {
+		if spec[0] != '[' && spec[0] != '(' {
+			return nil, false
+		}
+		end := strings.IndexAny(spec, "])")
+		if end < 0 {
+			return nil, false
+		}
+		interval := versionInterval{lowerInclusive: spec[0] == '[', upperInclusive: spec[end] == ']'}
+		body := spec[1:end]
+		if lower, upper, ok := strings.Cut(body, ","); ok {
+			interval.lower, interval.upper = strings.TrimSpace(lower), strings.TrimSpace(upper)
+		} else {
+			if !interval.lowerInclusive || !interval.upperInclusive {
+				return nil, false
+			}
+			interval.lower, interval.upper = strings.TrimSpace(body), strings.TrimSpace(body)
+		}
+		result = append(result, interval)
+
+		spec = strings.TrimSpace(spec[end+1:])
+		spec = strings.TrimPrefix(spec, ",")
+		spec = strings.TrimSpace(spec)
+	}
+	return result, len(result) != 0
+}
+
+// highestInRange returns the highest of versions satisfying a range spec.
+func highestInRange(spec string, versions []string) string {
+	intervals, ok := parseVersionRange(spec)
+	if !ok {
+		return ""
+	}
+	best := ""
+	for _, version := range versions {
+		for _, interval := range intervals {
+			if interval.contains(version) {
+				if best == "" || compareVersions(version, best) > 0 {
+					best = version
+				}
+				break
+			}
+		}
+	}
+	return best
+}
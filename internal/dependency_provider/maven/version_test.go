@@ -0,0 +1,31 @@
+package maven
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_compareVersions(t *testing.T) {
+	ordered := []string{
+		"1.0-alpha-1", "1.0-beta", "1.0-M1", "1.0-RC1", "1.0-SNAPSHOT",
+		"1.0", "1.0-sp", "1.0-foo", "1.0.1", "1.1", "1.10", "2.0.0-rc",
+	}
+	for i := 0; i+1 < len(ordered); i++ {
+		assert.Equal(t, -1, compareVersions(ordered[i], ordered[i+1]), "%s < %s", ordered[i], ordered[i+1])
+		assert.Equal(t, 1, compareVersions(ordered[i+1], ordered[i]), "%s > %s", ordered[i+1], ordered[i])
+	}
+	assert.Equal(t, 0, compareVersions("1", "1.0.0"))
+	assert.Equal(t, 0, compareVersions("1.0-final", "1.0"))
+}
+
+func Test_highestInRange(t *testing.T) {
+	versions := []string{"1.0", "1.5", "2.0", "2.1", "3.0-beta"}
+	assert.Equal(t, "1.5", highestInRange("[1.0,2.0)", versions))
+	assert.Equal(t, "2.0", highestInRange("[1.0,2.0]", versions))
+	assert.Equal(t, "3.0-beta", highestInRange("[1.0,)", versions))
+	assert.Equal(t, "1.0", highestInRange("(,1.0]", versions))
+	assert.Equal(t, "1.5", highestInRange("[1.5]", versions))
+	assert.Equal(t, "2.1", highestInRange("(,1.0],[2.1,2.5)", versions))
+	assert.Equal(t, "", highestInRange("[5.0,)", versions))
+	assert.Equal(t, "", highestInRange("1.0", versions))
+}
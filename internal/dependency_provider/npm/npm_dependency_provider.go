@@ -4,27 +4,71 @@ import (
 	"bytes"
 	"context"
 	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
+	"strings"
+	"sync"
 )
 
+// abbreviated metadata only holds fields needed for installation
+const _abbreviatedMetadata = "application/vnd.npm.install-v1+json; q=1.0, application/json; q=0.8"
+
+// Dependency kinds, named the way npm ls --omit names them.
+const (
+	KindProd     = "prod"
+	KindDev      = "dev"
+	KindOptional = "optional"
+	KindPeer     = "peer"
+)
+
+// DefaultKinds are the dependency kinds npm installs for a dependency. Dev
+// dependencies are left out, when asked for they are only read by FetchRootDeps.
+var DefaultKinds = []string{KindProd, KindOptional, KindPeer}
+
 type DependencyProvider struct {
 	BaseURL string
 	Client  *http.Client
+	// Kinds lists dependency kinds that are traversed. DefaultKinds is used if it is empty.
+	Kinds []string
+
+	mu         sync.Mutex
+	packuments map[string]*packument
 }
 
 func Default() *DependencyProvider {
 	return &DependencyProvider{
 		BaseURL: "https://registry.npmjs.com/",
 		Client:  &http.Client{},
+		Kinds:   DefaultKinds,
 	}
 }
 
-func (s *DependencyProvider) fetch(ctx context.Context, packageName string) ([]byte, error) {
-	uri, err := url.JoinPath(s.BaseURL, url.PathEscape(packageName), "latest")
+type peerMeta struct {
+	Optional bool `json:"optional"`
+}
+
+type manifest struct {
+	Dependencies         map[string]string   `json:"dependencies"`
+	OptionalDependencies map[string]string   `json:"optionalDependencies"`
+	PeerDependencies     map[string]string   `json:"peerDependencies"`
+	PeerDependenciesMeta map[string]peerMeta `json:"peerDependenciesMeta"`
+	// DevDependencies are missing from abbreviated metadata
+	DevDependencies map[string]string `json:"devDependencies"`
+}
+
+// packument is the registry document describing all versions of a package.
+type packument struct {
+	DistTags map[string]string   `json:"dist-tags"`
+	Versions map[string]manifest `json:"versions"`
+}
+
+func (s *DependencyProvider) fetch(ctx context.Context, packageName string, elem ...string) ([]byte, error) {
+	uri, err := url.JoinPath(s.BaseURL, append([]string{url.PathEscape(packageName)}, elem...)...)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err.Error())
 	}
@@ -32,11 +76,13 @@ func (s *DependencyProvider) fetch(ctx context.Context, packageName string) ([]b
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err.Error())
 	}
+	req.Header.Set("Accept", _abbreviatedMetadata)
 
 	response, err := s.Client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err.Error())
 	} else if response.StatusCode == http.StatusNotFound {
+		_ = response.Body.Close()
 		return nil, fmt.Errorf("%w: package %s does not exist", dep_errors.ErrPackageNotFound, packageName)
 	}
 	defer func() {
@@ -49,34 +95,241 @@ func (s *DependencyProvider) fetch(ctx context.Context, packageName string) ([]b
 	return result.Bytes(), nil
 }
 
-func parsePackageDeps(data []byte) ([]string, error) {
-	var schema struct {
-		Dependencies map[string]string `json:"dependencies"`
-	}
-
-	if err := json.Unmarshal(data, &schema); err != nil {
+func parsePackument(data []byte) (*packument, error) {
+	schema := &packument{}
+	if err := json.Unmarshal(data, schema); err != nil {
 		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
 	}
-	if schema.Dependencies == nil {
+	if schema.Versions == nil {
 		return nil, fmt.Errorf("%w: invalid json schema", dep_errors.ErrFetch)
 	}
-	dependencies := schema.Dependencies
+	return schema, nil
+}
 
-	names := make([]string, 0, len(dependencies))
-	for name := range dependencies {
-		names = append(names, name)
+func (s *DependencyProvider) packument(ctx context.Context, packageName string) (*packument, error) {
+	s.mu.Lock()
+	cached, ok := s.packuments[packageName]
+	s.mu.Unlock()
+	if ok {
+		return cached, nil
 	}
-	return names, nil
-}
 
-func (s *DependencyProvider) FetchPackageDeps(ctx context.Context, packageName string) ([]string, error) {
 	data, err := s.fetch(ctx, packageName)
 	if err != nil {
 		return nil, err
 	}
-	deps, err := parsePackageDeps(data)
+	result, err := parsePackument(data)
 	if err != nil {
 		return nil, err
 	}
-	return deps, nil
+
+	s.mu.Lock()
+	if s.packuments == nil {
+		s.packuments = make(map[string]*packument)
+	}
+	s.packuments[packageName] = result
+	s.mu.Unlock()
+	return result, nil
+}
+
+// resolve turns a dist-tag or a semver range into a published version. Like npm,
+// the version tagged latest is preferred if it satisfies the range.
+func (p *packument) resolve(selector string) (string, bool) {
+	selector = strings.TrimSpace(selector)
+	if selector == "" {
+		selector = "latest"
+	}
+	if tagged, ok := p.DistTags[selector]; ok {
+		_, exists := p.Versions[tagged]
+		return tagged, exists
+	}
+
+	r, ok := parseRange(selector)
+	if !ok {
+		return "", false
+	}
+	if latest, ok := p.DistTags["latest"]; ok {
+		if v, ok := parseVersion(latest); ok && r.test(v) {
+			if _, exists := p.Versions[latest]; exists {
+				return latest, true
+			}
+		}
+	}
+
+	versions := make([]string, 0, len(p.Versions))
+	for v := range p.Versions {
+		versions = append(versions, v)
+	}
+	best := maxSatisfying(r, versions)
+	return best, best != ""
+}
+
+// splitSpec splits "name@selector" into its parts. Scoped names start with "@".
+func splitSpec(spec string) (string, string) {
+	if i := strings.LastIndex(spec, "@"); i > 0 {
+		return spec[:i], spec[i+1:]
+	}
+	return spec, ""
+}
+
+// resolveDependency resolves a dependency declared as name: selector. Selectors
+// that are not ranges or tags (git urls, tarballs) leave the version empty.
+func (s *DependencyProvider) resolveDependency(ctx context.Context, name string, selector string) (models.Package, error) {
+	if strings.HasPrefix(selector, "npm:") {
+		name, selector = splitSpec(strings.TrimPrefix(selector, "npm:"))
+	}
+	p, err := s.packument(ctx, name)
+	if err != nil {
+		return models.Package{}, err
+	}
+	result := models.Package{Ecosystem: models.Npm, Name: name}
+	if v, ok := p.resolve(selector); ok {
+		result.Version = v
+	}
+	return result, nil
+}
+
+// Resolve accepts "name", "name@version", "name@range" or "name@tag".
+func (s *DependencyProvider) Resolve(ctx context.Context, spec string) (models.Package, error) {
+	name, selector := splitSpec(spec)
+	p, err := s.packument(ctx, name)
+	if err != nil {
+		return models.Package{}, err
+	}
+	v, ok := p.resolve(selector)
+	if !ok {
+		return models.Package{}, fmt.Errorf("%w: no version of %s matches %q", dep_errors.ErrPackageNotFound, name, selector)
+	}
+	return models.Package{Ecosystem: models.Npm, Name: name, Version: v}, nil
+}
+
+// fullManifest fetches the complete manifest of a version, including dev dependencies.
+func (s *DependencyProvider) fullManifest(ctx context.Context, name string, version string) (*manifest, error) {
+	data, err := s.fetch(ctx, name, url.PathEscape(version))
+	if err != nil {
+		return nil, err
+	}
+	result := &manifest{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	return result, nil
+}
+
+// declaredDependencies returns selectors of dependencies of the manifest by kind.
+// A package listed under several kinds only keeps the first of prod, optional, peer and dev.
+func declaredDependencies(m *manifest, kinds []string) map[string]map[string]string {
+	peers := make(map[string]string, len(m.PeerDependencies))
+	for name, selector := range m.PeerDependencies {
+		peers[name] = selector
+	}
+	// optional peers may be declared in peerDependenciesMeta only
+	for name, meta := range m.PeerDependenciesMeta {
+		if _, ok := peers[name]; !ok && meta.Optional {
+			peers[name] = "*"
+		}
+	}
+	// optionalDependencies are also copied to dependencies when a package is published
+	byKind := []struct {
+		kind string
+		deps map[string]string
+	}{
+		{KindOptional, m.OptionalDependencies},
+		{KindProd, m.Dependencies},
+		{KindPeer, peers},
+		{KindDev, m.DevDependencies},
+	}
+
+	seen := make(map[string]struct{})
+	result := make(map[string]map[string]string)
+	for _, group := range byKind {
+		for name, selector := range group.deps {
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+			if !contains(kinds, group.kind) {
+				continue
+			}
+			if result[group.kind] == nil {
+				result[group.kind] = make(map[string]string)
+			}
+			result[group.kind][name] = selector
+		}
+	}
+	return result
+}
+
+// FetchPackageDeps returns dependencies of the traversed kinds, grouped by kind
+// and sorted by name. Dev dependencies are left out, npm doesn't install them
+// for dependencies. The latest version is used if the package has no version.
+func (s *DependencyProvider) FetchPackageDeps(ctx context.Context, pkg models.Package) ([]models.Dependency, error) {
+	return s.fetchDeps(ctx, pkg, false)
+}
+
+// FetchRootDeps returns dependencies of the root package like FetchPackageDeps,
+// including dev dependencies if they are traversed.
+func (s *DependencyProvider) FetchRootDeps(ctx context.Context, root models.Package) ([]models.Dependency, error) {
+	return s.fetchDeps(ctx, root, true)
+}
+
+func (s *DependencyProvider) fetchDeps(ctx context.Context, pkg models.Package, isRoot bool) ([]models.Dependency, error) {
+	p, err := s.packument(ctx, pkg.Name)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := p.resolve(pkg.Version)
+	if !ok {
+		return nil, fmt.Errorf("%w: no version of %s matches %q", dep_errors.ErrPackageNotFound, pkg.Name, pkg.Version)
+	}
+
+	kinds := s.Kinds
+	if len(kinds) == 0 {
+		kinds = DefaultKinds
+	}
+
+	m := p.Versions[v]
+	if !isRoot {
+		m.DevDependencies = nil
+	} else if contains(kinds, KindDev) && m.DevDependencies == nil {
+		full, err := s.fullManifest(ctx, pkg.Name, v)
+		if err != nil {
+			return nil, err
+		}
+		m.DevDependencies = full.DevDependencies
+	}
+
+	declared := declaredDependencies(&m, kinds)
+	var result []models.Dependency
+	for _, kind := range []string{KindProd, KindOptional, KindPeer, KindDev} {
+		dependencies := declared[kind]
+		names := make([]string, 0, len(dependencies))
+		for dep := range dependencies {
+			names = append(names, dep)
+		}
+		sort.Strings(names)
+
+		for _, dep := range names {
+			resolved, err := s.resolveDependency(ctx, dep, dependencies[dep])
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, models.Dependency{
+				Package:    resolved,
+				Constraint: dependencies[dep],
+				Kind:       kind,
+				Optional:   kind == KindOptional || kind == KindPeer && m.PeerDependenciesMeta[dep].Optional,
+			})
+		}
+	}
+	return result, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
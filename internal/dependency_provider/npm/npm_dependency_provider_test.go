@@ -3,82 +3,166 @@ package npm
 import (
 	"context"
 	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
-	"sort"
 	"testing"
 	"time"
 )
 
-func TestParsePackageDeps(t *testing.T) {
+func TestParsePackument(t *testing.T) {
 	t.Run("test can parse valid json", func(t *testing.T) {
 		data := []byte(`
 {
 	"name": "@vue/compiler-dom",
-	"dependencies": {
-		"@vue/shared": "3.2.45",
-		"@vue/compiler-core": "3.2.45"
+	"dist-tags": {"latest": "3.2.45"},
+	"versions": {
+		"3.2.45": {
+			"dependencies": {
+				"@vue/shared": "3.2.45",
+				"@vue/compiler-core": "3.2.45"
+			}
+		}
 	}
 }
 `)
-		deps, err := parsePackageDeps(data)
+		p, err := parsePackument(data)
 		assert.NoError(t, err)
-
-		sort.Strings(deps)
-		expected := []string{"@vue/shared", "@vue/compiler-core"}
-		sort.Strings(expected)
-		assert.Equal(t, expected, deps)
+		assert.Equal(t, map[string]string{"latest": "3.2.45"}, p.DistTags)
+		assert.Equal(t, map[string]string{
+			"@vue/shared":        "3.2.45",
+			"@vue/compiler-core": "3.2.45",
+		}, p.Versions["3.2.45"].Dependencies)
 	})
 
 	t.Run("test correctly parses packages with no dependencies", func(t *testing.T) {
 		data := []byte(`
 {
 	"name": "@vue/compiler-dom",
-	"dependencies": {}
+	"versions": {"1.0.0": {}}
 }
 `)
-		deps, err := parsePackageDeps(data)
+		p, err := parsePackument(data)
 		assert.NoError(t, err)
-		assert.Equal(t, 0, len(deps))
+		assert.Equal(t, 0, len(p.Versions["1.0.0"].Dependencies))
 	})
 
 	t.Run("test parsing if json is invalid", func(t *testing.T) {
-		_, err := parsePackageDeps([]byte(`{`))
+		_, err := parsePackument([]byte(`{`))
 		assert.ErrorIs(t, err, dep_errors.ErrFetch)
 	})
 
 	t.Run("test parsing if json schema is invalid", func(t *testing.T) {
-		_, err := parsePackageDeps([]byte(`{"dependencies": 1}`))
+		_, err := parsePackument([]byte(`{"versions": 1}`))
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+
+		_, err = parsePackument([]byte(`{}`))
 		assert.ErrorIs(t, err, dep_errors.ErrFetch)
 	})
 }
 
+func TestPackument_resolve(t *testing.T) {
+	p := &packument{
+		DistTags: map[string]string{"latest": "1.2.0", "next": "2.0.0-rc.1"},
+		Versions: map[string]manifest{
+			"1.0.0": {}, "1.2.0": {}, "1.3.0": {}, "2.0.0-rc.1": {},
+		},
+	}
+	cases := map[string]string{
+		"":            "1.2.0",
+		"latest":      "1.2.0",
+		"next":        "2.0.0-rc.1",
+		"^1.0.0":      "1.2.0",
+		">=1.2":       "1.2.0",
+		"~1.3":        "1.3.0",
+		"1.0.0":       "1.0.0",
+		"<1.2.0":      "1.0.0",
+		"^2.0.0-rc.0": "2.0.0-rc.1",
+	}
+	for selector, expected := range cases {
+		v, ok := p.resolve(selector)
+		assert.True(t, ok, selector)
+		assert.Equal(t, expected, v, selector)
+	}
+
+	_, ok := p.resolve("^3.0.0")
+	assert.False(t, ok)
+	_, ok = p.resolve("git+https://github.com/a/b.git")
+	assert.False(t, ok)
+}
+
 func TestDownloader_FetchPackageDeps(t *testing.T) {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/valid-dependencies/latest",
+	mux.HandleFunc("/valid-dependencies",
 		func(w http.ResponseWriter, r *http.Request) {
-			_, _ = w.Write([]byte(`{"dependencies": {"router": "1.0.0", "shared": "^2.0.0"}}`))
-			w.WriteHeader(200)
+			_, _ = w.Write([]byte(`{
+				"dist-tags": {"latest": "1.0.0"},
+				"versions": {
+					"0.9.0": {"dependencies": {"router": "^0.1.0"}},
+					"1.0.0": {"dependencies": {"router": "1.0.0", "shared": "^2.0.0", "alias": "npm:shared@~2.0.0"}}
+				}
+			}`))
 		})
-	mux.HandleFunc("/empty-dependencies/latest",
+	mux.HandleFunc("/valid-dependencies/1.0.0",
 		func(w http.ResponseWriter, r *http.Request) {
-			_, _ = w.Write([]byte(`{"dependencies": {}}`))
-			w.WriteHeader(200)
+			_, _ = w.Write([]byte(`{"dependencies": {"router": "1.0.0"}, "devDependencies": {"router": "^1.0.0", "tester": "*"}}`))
+		})
+	mux.HandleFunc("/valid-dependencies/0.9.0",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"dependencies": {"router": "^0.1.0"}}`))
+		})
+	mux.HandleFunc("/plugin",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{
+				"dist-tags": {"latest": "1.0.0"},
+				"versions": {
+					"1.0.0": {
+						"dependencies": {"router": "^1.0.0", "fsevents": "^2.0.0"},
+						"optionalDependencies": {"fsevents": "^2.0.0"},
+						"peerDependencies": {"shared": "^2.0.0", "router": "^1.0.0"},
+						"peerDependenciesMeta": {"shared": {"optional": true}, "types": {"optional": true}}
+					}
+				}
+			}`))
+		})
+	mux.HandleFunc("/fsevents",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"dist-tags": {"latest": "2.3.3"}, "versions": {"2.3.3": {}}}`))
+		})
+	mux.HandleFunc("/types",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"dist-tags": {"latest": "1.0.0"}, "versions": {"1.0.0": {}}}`))
+		})
+	mux.HandleFunc("/tester",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"dist-tags": {"latest": "5.0.0"}, "versions": {"5.0.0": {}}}`))
 		})
-	mux.HandleFunc("/invalid-json/latest",
+	mux.HandleFunc("/router",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"dist-tags": {"latest": "1.0.0"}, "versions": {"0.1.0": {}, "0.1.5": {}, "1.0.0": {}}}`))
+		})
+	mux.HandleFunc("/shared",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"dist-tags": {"latest": "3.0.0"}, "versions": {"2.0.1": {}, "2.1.0": {}, "3.0.0": {}}}`))
+		})
+	mux.HandleFunc("/empty-dependencies",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"dist-tags": {"latest": "1.0.0"}, "versions": {"1.0.0": {"dependencies": {}}}}`))
+		})
+	mux.HandleFunc("/invalid-json",
 		func(w http.ResponseWriter, r *http.Request) {
 			_, _ = w.Write([]byte(`{`))
 			w.WriteHeader(200)
 		})
 
-	mux.HandleFunc("/invalid-schema/latest",
+	mux.HandleFunc("/invalid-schema",
 		func(w http.ResponseWriter, r *http.Request) {
 			_, _ = w.Write([]byte(`{}`))
 			w.WriteHeader(200)
 		})
 
-	mux.HandleFunc("/not-found/latest",
+	mux.HandleFunc("/not-found",
 		func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(404)
 		})
@@ -94,7 +178,7 @@ func TestDownloader_FetchPackageDeps(t *testing.T) {
 			BaseURL: srv.URL,
 			Client:  &http.Client{},
 		}
-		deps, err := d.FetchPackageDeps(ctx, "not-found")
+		deps, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Npm, Name: "not-found"})
 		assert.Empty(t, deps)
 		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
 	})
@@ -110,7 +194,7 @@ func TestDownloader_FetchPackageDeps(t *testing.T) {
 			BaseURL: srv.URL,
 			Client:  &http.Client{},
 		}
-		deps, err := d.FetchPackageDeps(ctx, "invalid-json")
+		deps, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Npm, Name: "invalid-json"})
 		assert.Empty(t, deps)
 		assert.ErrorIs(t, err, dep_errors.ErrFetch)
 	})
@@ -126,7 +210,7 @@ func TestDownloader_FetchPackageDeps(t *testing.T) {
 			BaseURL: srv.URL,
 			Client:  &http.Client{},
 		}
-		deps, err := d.FetchPackageDeps(ctx, "invalid-schema")
+		deps, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Npm, Name: "invalid-schema"})
 		assert.Empty(t, deps)
 		assert.ErrorIs(t, err, dep_errors.ErrFetch)
 	})
@@ -142,7 +226,7 @@ func TestDownloader_FetchPackageDeps(t *testing.T) {
 			BaseURL: srv.URL,
 			Client:  &http.Client{},
 		}
-		deps, err := d.FetchPackageDeps(ctx, "empty-dependencies")
+		deps, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Npm, Name: "empty-dependencies"})
 		assert.Empty(t, deps)
 		assert.NoError(t, err)
 	})
@@ -158,10 +242,148 @@ func TestDownloader_FetchPackageDeps(t *testing.T) {
 			BaseURL: srv.URL,
 			Client:  &http.Client{},
 		}
-		deps, err := d.FetchPackageDeps(ctx, "valid-dependencies")
-		sort.Strings(deps)
-		assert.Equal(t, []string{"router", "shared"}, deps)
+		pkg, err := d.Resolve(ctx, "valid-dependencies")
+		assert.NoError(t, err)
+		assert.Equal(t, models.Package{Ecosystem: models.Npm, Name: "valid-dependencies", Version: "1.0.0"}, pkg)
+
+		// dev dependencies aren't traversed by default, not even for the root package
+		deps, err := d.FetchRootDeps(ctx, pkg)
+		assert.Equal(t, []models.Dependency{
+			{
+				Package:    models.Package{Ecosystem: models.Npm, Name: "shared", Version: "2.0.1"},
+				Constraint: "npm:shared@~2.0.0",
+				Kind:       KindProd,
+			},
+			{
+				Package:    models.Package{Ecosystem: models.Npm, Name: "router", Version: "1.0.0"},
+				Constraint: "1.0.0",
+				Kind:       KindProd,
+			},
+			{
+				Package:    models.Package{Ecosystem: models.Npm, Name: "shared", Version: "2.1.0"},
+				Constraint: "^2.0.0",
+				Kind:       KindProd,
+			},
+		}, deps)
+		assert.NoError(t, err)
+	})
+
+	t.Run("test fetching dev dependencies of the root package", func(t *testing.T) {
+		srv := httptest.NewServer(mux)
+		defer srv.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{
+			BaseURL: srv.URL,
+			Client:  &http.Client{},
+			Kinds:   []string{KindProd, KindDev},
+		}
+		pkg, err := d.Resolve(ctx, "valid-dependencies")
 		assert.NoError(t, err)
+		deps, err := d.FetchRootDeps(ctx, pkg)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, len(deps))
+		assert.Equal(t, models.Dependency{
+			Package:    models.Package{Ecosystem: models.Npm, Name: "tester", Version: "5.0.0"},
+			Constraint: "*",
+			Kind:       KindDev,
+		}, deps[3])
+
+		// dependencies don't bring their dev dependencies, whatever was resolved before
+		deps, err = d.FetchPackageDeps(ctx, pkg)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(deps))
+		deps, err = d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Npm, Name: "valid-dependencies", Version: "0.9.0"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(deps))
+	})
+
+	t.Run("test fetching dependency kinds", func(t *testing.T) {
+		srv := httptest.NewServer(mux)
+		defer srv.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{
+			BaseURL: srv.URL,
+			Client:  &http.Client{},
+		}
+		deps, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Npm, Name: "plugin", Version: "1.0.0"})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{
+				Package:    models.Package{Ecosystem: models.Npm, Name: "router", Version: "1.0.0"},
+				Constraint: "^1.0.0",
+				Kind:       KindProd,
+			},
+			{
+				Package:    models.Package{Ecosystem: models.Npm, Name: "fsevents", Version: "2.3.3"},
+				Constraint: "^2.0.0",
+				Kind:       KindOptional,
+				Optional:   true,
+			},
+			{
+				Package:    models.Package{Ecosystem: models.Npm, Name: "shared", Version: "2.1.0"},
+				Constraint: "^2.0.0",
+				Kind:       KindPeer,
+				Optional:   true,
+			},
+			{
+				Package:    models.Package{Ecosystem: models.Npm, Name: "types", Version: "1.0.0"},
+				Constraint: "*",
+				Kind:       KindPeer,
+				Optional:   true,
+			},
+		}, deps)
+
+		d.Kinds = []string{KindPeer}
+		deps, err = d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Npm, Name: "plugin", Version: "1.0.0"})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(deps))
+		assert.Equal(t, "shared@2.1.0", deps[0].Package.String())
+
+		d.Kinds = []string{KindProd}
+		pkg, err := d.Resolve(ctx, "valid-dependencies")
+		assert.NoError(t, err)
+		deps, err = d.FetchPackageDeps(ctx, pkg)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(deps))
+	})
+
+	t.Run("test fetching specific version", func(t *testing.T) {
+		srv := httptest.NewServer(mux)
+		defer srv.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{
+			BaseURL: srv.URL,
+			Client:  &http.Client{},
+		}
+		pkg, err := d.Resolve(ctx, "valid-dependencies@0.9.0")
+		assert.NoError(t, err)
+		deps, err := d.FetchPackageDeps(ctx, pkg)
+		assert.Equal(t, []models.Dependency{
+			{Package: models.Package{Ecosystem: models.Npm, Name: "router", Version: "0.1.5"}, Constraint: "^0.1.0", Kind: KindProd},
+		}, deps)
+		assert.NoError(t, err)
+
+		pkg, err = d.Resolve(ctx, "shared@latest")
+		assert.NoError(t, err)
+		assert.Equal(t, "shared@3.0.0", pkg.String())
+
+		pkg, err = d.Resolve(ctx, "shared@~2.0")
+		assert.NoError(t, err)
+		assert.Equal(t, "shared@2.0.1", pkg.String())
+
+		_, err = d.Resolve(ctx, "valid-dependencies@5.0.0")
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+		_, err = d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Npm, Name: "valid-dependencies", Version: "5.0.0"})
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
 	})
 
 	t.Run("test fetching if server is unreachable", func(t *testing.T) {
@@ -173,7 +395,7 @@ func TestDownloader_FetchPackageDeps(t *testing.T) {
 			Client:  &http.Client{},
 		}
 
-		_, err := d.FetchPackageDeps(ctx, "valid-dependencies")
+		_, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Npm, Name: "valid-dependencies"})
 		assert.ErrorIs(t, err, dep_errors.ErrFetch)
 	})
 	t.Run("test if fetching url is invalid", func(t *testing.T) {
@@ -185,7 +407,7 @@ func TestDownloader_FetchPackageDeps(t *testing.T) {
 			Client:  &http.Client{},
 		}
 
-		_, err := d.FetchPackageDeps(ctx, "valid-dependencies")
+		_, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.Npm, Name: "valid-dependencies"})
 		assert.ErrorIs(t, err, dep_errors.ErrFetch)
 	})
 }
@@ -195,4 +417,5 @@ func TestDefault(t *testing.T) {
 	assert.NotNil(t, d)
 	assert.NotNil(t, d.Client)
 	assert.Equal(t, "https://registry.npmjs.com/", d.BaseURL)
+	assert.Equal(t, DefaultKinds, d.Kinds)
 }
@@ -0,0 +1,337 @@
+package npm
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type version struct {
+	major, minor, patch int
+	prerelease          []string
+}
+
+var versionPattern = regexp.MustCompile(`^\s*[v=]*\s*(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?\s*$`)
+
+func parseVersion(s string) (version, bool) {
+	m := versionPattern.FindStringSubmatch(s)
+	if m == nil {
+		return version{}, false
+	}
+	v := version{}
+	v.major, _ = strconv.Atoi(m[1])
+	v.minor, _ = strconv.Atoi(m[2])
+	v.patch, _ = strconv.Atoi(m[3])
+	if m[4] != "" {
+		v.prerelease = strings.Split(m[4], ".")
+	}
+	return v, true
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (v version) compareMain(other version) int {
+	if c := compareInts(v.major, other.major); c != 0 {
+		return c
+	}
+	if c := compareInts(v.minor, other.minor); c != 0 {
+		return c
+	}
+	return compareInts(v.patch, other.patch)
+}
+
+func (v version) compare(other version) int {
+	if c := v.compareMain(other); c != 0 {
+		return c
+	}
+	switch {
+	case len(v.prerelease) == 0 && len(other.prerelease) == 0:
+		return 0
+	case len(v.prerelease) == 0:
+		return 1
+	case len(other.prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.prerelease) && i < len(other.prerelease); i++ {
+		a, b := v.prerelease[i], other.prerelease[i]
+		an, aErr := strconv.Atoi(a)
+		bn, bErr := strconv.Atoi(b)
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareInts(an, bn); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(a, b); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInts(len(v.prerelease), len(other.prerelease))
+}
+
+type comparator struct {
+	op      string
+	version version
+}
+
+func (c comparator) test(v version) bool {
+	cmp := v.compare(c.version)
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// comparatorSet is a list of comparators that must all hold.
+type comparatorSet []comparator
+
+func (s comparatorSet) test(v version) bool {
+	for _, c := range s {
+		if !c.test(v) {
+			return false
+		}
+	}
+	if len(v.prerelease) == 0 {
+		return true
+	}
+	// Pre-releases only match if some comparator has a pre-release on the same
+	// [major, minor, patch] tuple, so ^1.2.3 never picks 1.3.0-beta.
+	for _, c := range s {
+		if len(c.version.prerelease) != 0 && c.version.compareMain(v) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// semverRange is a node-semver range: comparator sets joined with "||".
+type semverRange []comparatorSet
+
+func (r semverRange) test(v version) bool {
+	for _, s := range r {
+		if s.test(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// partial is a possibly incomplete version such as "1", "1.2.x" or "*".
+type partial struct {
+	major, minor, patch int
+	// parts is the number of specified components, x and * are unspecified
+	parts      int
+	prerelease []string
+}
+
+var partialPattern = regexp.MustCompile(`^[v=]*(\d+|[xX*])?(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+func parsePartial(s string) (partial, bool) {
+	m := partialPattern.FindStringSubmatch(s)
+	if m == nil {
+		return partial{}, false
+	}
+	p := partial{}
+	for i, part := range m[1:4] {
+		if part == "" || part == "x" || part == "X" || part == "*" {
+			break
+		}
+		n, _ := strconv.Atoi(part)
+		switch i {
+		case 0:
+			p.major = n
+		case 1:
+			p.minor = n
+		case 2:
+			p.patch = n
+		}
+		p.parts++
+	}
+	if m[4] != "" {
+		if p.parts != 3 {
+			return partial{}, false
+		}
+		p.prerelease = strings.Split(m[4], ".")
+	}
+	return p, true
+}
+
+func (p partial) lower() version {
+	return version{major: p.major, minor: p.minor, patch: p.patch, prerelease: p.prerelease}
+}
+
+// next returns the first version above the partial's wildcard range, e.g. 1.3.0-0 for 1.2.x.
+func (p partial) next() version {
+	switch p.parts {
+	case 1:
+		return version{major: p.major + 1, prerelease: []string{"0"}}
+	case 2:
+		return version{major: p.major, minor: p.minor + 1, prerelease: []string{"0"}}
+	}
+	return version{major: p.major, minor: p.minor, patch: p.patch + 1, prerelease: []string{"0"}}
+}
+
+func xRange(p partial) comparatorSet {
+	switch p.parts {
+	case 0:
+		return comparatorSet{{op: ">=", version: version{}}}
+	case 3:
+		return comparatorSet{{op: "=", version: p.lower()}}
+	}
+	return comparatorSet{{op: ">=", version: p.lower()}, {op: "<", version: p.next()}}
+}
+
+func tildeRange(p partial) comparatorSet {
+	if p.parts == 0 {
+		return xRange(p)
+	}
+	upper := p
+	if upper.parts == 3 {
+		upper.parts = 2
+	}
+	return comparatorSet{{op: ">=", version: p.lower()}, {op: "<", version: upper.next()}}
+}
+
+func caretRange(p partial) comparatorSet {
+	if p.parts == 0 {
+		return xRange(p)
+	}
+	upper := p
+	switch {
+	case p.major != 0 || p.parts == 1:
+		upper.parts = 1
+	case p.minor != 0 || p.parts == 2:
+		upper.parts = 2
+	default:
+		upper.parts = 3
+	}
+	return comparatorSet{{op: ">=", version: p.lower()}, {op: "<", version: upper.next()}}
+}
+
+// primitive desugars comparators with partial versions, e.g. ">1.2" means ">=1.3.0".
+func primitive(op string, p partial) comparatorSet {
+	if p.parts == 0 {
+		if op == ">=" || op == "<=" {
+			return xRange(p)
+		}
+		// "<*" and ">*" can't be satisfied
+		return comparatorSet{{op: "<", version: version{prerelease: []string{"0"}}}}
+	}
+	if p.parts == 3 {
+		return comparatorSet{{op: op, version: p.lower()}}
+	}
+	switch op {
+	case ">":
+		return comparatorSet{{op: ">=", version: p.next()}}
+	case "<=":
+		return comparatorSet{{op: "<", version: p.next()}}
+	case "<":
+		lower := p.lower()
+		lower.prerelease = []string{"0"}
+		return comparatorSet{{op: "<", version: lower}}
+	}
+	return comparatorSet{{op: ">=", version: p.lower()}}
+}
+
+var (
+	hyphenPattern   = regexp.MustCompile(`^\s*(\S+)\s+-\s+(\S+)\s*$`)
+	operatorSpacing = regexp.MustCompile(`(<=|>=|<|>|=|~>|~|\^)\s+`)
+)
+
+func parseComparatorSet(s string) (comparatorSet, bool) {
+	if m := hyphenPattern.FindStringSubmatch(s); m != nil {
+		from, okFrom := parsePartial(m[1])
+		to, okTo := parsePartial(m[2])
+		if !okFrom || !okTo {
+			return nil, false
+		}
+		result := comparatorSet{{op: ">=", version: from.lower()}}
+		if to.parts == 3 {
+			result = append(result, comparator{op: "<=", version: to.lower()})
+		} else if to.parts != 0 {
+			result = append(result, comparator{op: "<", version: to.next()})
+		}
+		return result, true
+	}
+
+	s = operatorSpacing.ReplaceAllString(s, "$1")
+	var result comparatorSet
+	for _, token := range strings.Fields(s) {
+		var set comparatorSet
+		var op string
+		for _, candidate := range []string{"<=", ">=", "~>", "<", ">", "=", "~", "^"} {
+			if strings.HasPrefix(token, candidate) {
+				op = candidate
+				break
+			}
+		}
+		p, ok := parsePartial(strings.TrimPrefix(token, op))
+		if !ok {
+			return nil, false
+		}
+		switch op {
+		case "~", "~>":
+			set = tildeRange(p)
+		case "^":
+			set = caretRange(p)
+		case "", "=":
+			set = xRange(p)
+		default:
+			set = primitive(op, p)
+		}
+		result = append(result, set...)
+	}
+	if len(result) == 0 {
+		result = xRange(partial{})
+	}
+	return result, true
+}
+
+func parseRange(s string) (semverRange, bool) {
+	var result semverRange
+	for _, part := range strings.Split(s, "||") {
+		set, ok := parseComparatorSet(part)
+		if !ok {
+			return nil, false
+		}
+		result = append(result, set)
+	}
+	return result, true
+}
+
+// maxSatisfying returns the highest of versions matching the range.
+func maxSatisfying(r semverRange, versions []string) string {
+	best := ""
+	var bestVersion version
+	for _, s := range versions {
+		v, ok := parseVersion(s)
+		if !ok || !r.test(v) {
+			continue
+		}
+		if best == "" || v.compare(bestVersion) > 0 {
+			best, bestVersion = s, v
+		}
+	}
+	return best
+}
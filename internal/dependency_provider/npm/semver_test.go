@@ -0,0 +1,71 @@
+package npm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_parseRange(t *testing.T) {
+	cases := []struct {
+		rng       string
+		version   string
+		satisfied bool
+	}{
+		{"^1.2.3", "1.9.0", true},
+		{"^1.2.3", "2.0.0", false},
+		{"^1.2.3", "1.2.2", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.4", false},
+		{"^0.x", "0.9.0", true},
+		{"^1.2.3-beta.2", "1.2.3-beta.4", true},
+		{"^1.2.3-beta.2", "1.2.4-beta.4", false},
+		{"^1.2.3", "1.3.0-beta", false},
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"~1", "1.9.9", true},
+		{"~ 1.2", "1.2.5", true},
+		{"1.x", "1.5.0", true},
+		{"1.2.*", "1.3.0", false},
+		{"*", "0.0.1", true},
+		{"", "3.0.0", true},
+		{"x", "3.0.0-alpha", false},
+		{"1.2.3 - 2.3.4", "2.3.4", true},
+		{"1.2.3 - 2.3", "2.3.9", true},
+		{"1.2.3 - 2.3", "2.4.0", false},
+		{"1.2 - 2", "1.2.0", true},
+		{">=1.0.0 <2.0.0", "1.5.0", true},
+		{">= 1.0.0 < 2.0.0", "2.0.0", false},
+		{">1.2", "1.2.9", false},
+		{">1.2", "1.3.0", true},
+		{"<=1.2", "1.2.9", true},
+		{"<1.2", "1.1.9", true},
+		{"<1.2", "1.2.0", false},
+		{"^1.0.0 || ^3.0.0", "3.1.0", true},
+		{"^1.0.0 || ^3.0.0", "2.1.0", false},
+		{"=1.2.3", "1.2.3", true},
+		{"v1.2.3", "1.2.3", true},
+	}
+	for _, c := range cases {
+		r, ok := parseRange(c.rng)
+		assert.True(t, ok, c.rng)
+		v, ok := parseVersion(c.version)
+		assert.True(t, ok, c.version)
+		assert.Equal(t, c.satisfied, r.test(v), "%s satisfies %q", c.version, c.rng)
+	}
+
+	for _, invalid := range []string{"latest", "git+ssh://host/repo.git", "file:../lib", "1.2.3-"} {
+		_, ok := parseRange(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
+
+func Test_maxSatisfying(t *testing.T) {
+	versions := []string{"1.0.0", "1.10.0", "1.9.0", "2.0.0-rc.1", "not-a-version"}
+	r, _ := parseRange("^1")
+	assert.Equal(t, "1.10.0", maxSatisfying(r, versions))
+	r, _ = parseRange(">=2.0.0-rc.0")
+	assert.Equal(t, "2.0.0-rc.1", maxSatisfying(r, versions))
+	r, _ = parseRange("^3")
+	assert.Equal(t, "", maxSatisfying(r, versions))
+}
@@ -0,0 +1,153 @@
+package nuget
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Framework families known to the compatibility rules below.
+const (
+	familyAny          = ""
+	familyNet          = "net"
+	familyNetCoreApp   = "netcoreapp"
+	familyNetStandard  = "netstandard"
+	familyNetFramework = "netframework"
+	familyUnknown      = "unknown"
+)
+
+type framework struct {
+	family  string
+	version [3]int
+	// name is the original moniker, used for families the rules don't know
+	name string
+}
+
+func parseVersionParts(s string) ([3]int, bool) {
+	var result [3]int
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return result, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return result, false
+		}
+		result[i] = n
+	}
+	return result, true
+}
+
+// parseFramework understands both short (net472, netstandard2.0, net6.0-windows)
+// and long (.NETFramework4.7.2, .NETStandard2.0) target framework monikers.
+func parseFramework(moniker string) framework {
+	name := strings.ToLower(strings.TrimSpace(moniker))
+	if name == "" || name == "any" || name == "agnostic" {
+		return framework{family: familyAny}
+	}
+	// platform suffixes like "-windows" or "-android" are not taken into account
+	name, _, _ = strings.Cut(name, "-")
+
+	prefixes := []struct {
+		prefix string
+		family string
+	}{
+		{".netstandard", familyNetStandard},
+		{"netstandard", familyNetStandard},
+		{".netcoreapp", familyNetCoreApp},
+		{"netcoreapp", familyNetCoreApp},
+		{".netframework", familyNetFramework},
+		{"net", familyNet},
+	}
+	for _, p := range prefixes {
+		if !strings.HasPrefix(name, p.prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(name, p.prefix)
+		if p.family == familyNet && !strings.Contains(rest, ".") {
+			// net472 is .NET Framework 4.7.2 written without dots
+			rest = strings.Join(strings.Split(rest, ""), ".")
+		}
+		v, ok := parseVersionParts(rest)
+		if !ok {
+			break
+		}
+		f := framework{family: p.family, version: v, name: name}
+		switch {
+		case f.family == familyNet && v[0] < 5:
+			f.family = familyNetFramework
+		case f.family == familyNetCoreApp && v[0] >= 5:
+			f.family = familyNet
+		}
+		return f
+	}
+	return framework{family: familyUnknown, name: name}
+}
+
+func compareFrameworkVersions(a, b [3]int) int {
+	for i := range a {
+		if c := compareInts(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// netStandardSupport returns the highest .NET Standard version implemented by f.
+func netStandardSupport(f framework) ([3]int, bool) {
+	switch f.family {
+	case familyNetStandard:
+		return f.version, true
+	case familyNet:
+		return [3]int{2, 1}, true
+	case familyNetCoreApp:
+		if f.version[0] >= 3 {
+			return [3]int{2, 1}, true
+		}
+		return [3]int{2, 0}, true
+	case familyNetFramework:
+		switch {
+		case compareFrameworkVersions(f.version, [3]int{4, 6, 1}) >= 0:
+			return [3]int{2, 0}, true
+		case compareFrameworkVersions(f.version, [3]int{4, 6}) >= 0:
+			return [3]int{1, 3}, true
+		case compareFrameworkVersions(f.version, [3]int{4, 5, 1}) >= 0:
+			return [3]int{1, 2}, true
+		case compareFrameworkVersions(f.version, [3]int{4, 5}) >= 0:
+			return [3]int{1, 1}, true
+		}
+	}
+	return [3]int{}, false
+}
+
+// compatibility scores how well a package built for candidate fits the target.
+// Zero means incompatible; higher scores are nearer frameworks. This is a
+// simplified version of NuGet's nearest framework rules.
+func compatibility(target, candidate framework) int {
+	if candidate.family == familyAny {
+		return 1
+	}
+	if target.family == familyUnknown || candidate.family == familyUnknown {
+		if target.name == candidate.name {
+			return 1000
+		}
+		return 0
+	}
+	if candidate.family == target.family {
+		if compareFrameworkVersions(candidate.version, target.version) > 0 {
+			return 0
+		}
+		return 500 + candidate.version[0]*10 + candidate.version[1]
+	}
+	if target.family == familyNet && candidate.family == familyNetCoreApp {
+		return 400 + candidate.version[0]*10 + candidate.version[1]
+	}
+	if candidate.family == familyNetStandard {
+		supported, ok := netStandardSupport(target)
+		if !ok || compareFrameworkVersions(candidate.version, supported) > 0 {
+			return 0
+		}
+		return 100 + candidate.version[0]*10 + candidate.version[1]
+	}
+	return 0
+}
@@ -0,0 +1,318 @@
+package nuget
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// registrationTypes are resource types of the registration API in order of preference.
+var registrationTypes = []string{
+	"RegistrationsBaseUrl/3.6.0",
+	"RegistrationsBaseUrl/3.4.0",
+	"RegistrationsBaseUrl/Versioned",
+	"RegistrationsBaseUrl/3.0.0-rc",
+	"RegistrationsBaseUrl/3.0.0-beta",
+	"RegistrationsBaseUrl",
+}
+
+type DependencyProvider struct {
+	// BaseURL is the address of a v3 service index.
+	BaseURL string
+	Client  *http.Client
+	// TargetFramework is a moniker such as net8.0 or netstandard2.0. Dependencies of
+	// the nearest compatible group are used. If it is empty, all groups are merged.
+	TargetFramework string
+
+	mu            sync.Mutex
+	registrations string
+	leaves        map[string][]catalogEntry
+}
+
+func Default() *DependencyProvider {
+	return &DependencyProvider{
+		BaseURL: "https://api.nuget.org/v3/index.json",
+		Client:  &http.Client{},
+	}
+}
+
+type packageDependency struct {
+	ID    string `json:"id"`
+	Range string `json:"range"`
+}
+
+type dependencyGroup struct {
+	TargetFramework string              `json:"targetFramework"`
+	Dependencies    []packageDependency `json:"dependencies"`
+}
+
+type catalogEntry struct {
+	ID               string            `json:"id"`
+	Version          string            `json:"version"`
+	Listed           *bool             `json:"listed"`
+	DependencyGroups []dependencyGroup `json:"dependencyGroups"`
+}
+
+func (e *catalogEntry) isListed() bool {
+	return e.Listed == nil || *e.Listed
+}
+
+// canonicalID returns the id of a package as it is registered. Ids are
+// case-insensitive, so dependents and users may spell them differently.
+func canonicalID(id string, leaves ...catalogEntry) string {
+	for _, leaf := range leaves {
+		if leaf.ID != "" {
+			return leaf.ID
+		}
+	}
+	return strings.ToLower(id)
+}
+
+type registrationPage struct {
+	ID    string `json:"@id"`
+	Items []struct {
+		CatalogEntry catalogEntry `json:"catalogEntry"`
+	} `json:"items"`
+}
+
+func (d *DependencyProvider) fetchJSON(ctx context.Context, name string, uri string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", dep_errors.ErrPackageNotFound, name)
+	} else if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: unexpected status %d for %s", dep_errors.ErrFetch, resp.StatusCode, name)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	return nil
+}
+
+// registrationsBaseURL looks up the registration resource in the service index.
+func (d *DependencyProvider) registrationsBaseURL(ctx context.Context) (string, error) {
+	d.mu.Lock()
+	cached := d.registrations
+	d.mu.Unlock()
+	if cached != "" {
+		return cached, nil
+	}
+
+	var index struct {
+		Resources []struct {
+			ID   string          `json:"@id"`
+			Type json.RawMessage `json:"@type"`
+		} `json:"resources"`
+	}
+	if err := d.fetchJSON(ctx, "service index", d.BaseURL, &index); err != nil {
+		return "", err
+	}
+
+	found := make(map[string]string)
+	for _, resource := range index.Resources {
+		// @type is either a string or an array of strings
+		var types []string
+		if err := json.Unmarshal(resource.Type, &types); err != nil {
+			var single string
+			if err := json.Unmarshal(resource.Type, &single); err != nil {
+				continue
+			}
+			types = []string{single}
+		}
+		for _, t := range types {
+			if _, ok := found[t]; !ok {
+				found[t] = resource.ID
+			}
+		}
+	}
+	for _, t := range registrationTypes {
+		if base, ok := found[t]; ok {
+			d.mu.Lock()
+			d.registrations = base
+			d.mu.Unlock()
+			return base, nil
+		}
+	}
+	return "", fmt.Errorf("%w: service index has no registration resource", dep_errors.ErrFetch)
+}
+
+// fetchLeaves returns catalog entries of all versions of a package.
+func (d *DependencyProvider) fetchLeaves(ctx context.Context, id string) ([]catalogEntry, error) {
+	lowerID := strings.ToLower(id)
+	d.mu.Lock()
+	cached, ok := d.leaves[lowerID]
+	d.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	base, err := d.registrationsBaseURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uri, err := url.JoinPath(base, url.PathEscape(lowerID), "index.json")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+
+	var index struct {
+		Items []registrationPage `json:"items"`
+	}
+	if err := d.fetchJSON(ctx, id, uri, &index); err != nil {
+		return nil, err
+	}
+
+	var result []catalogEntry
+	for _, page := range index.Items {
+		// large packages don't inline leaves into the index
+		if page.Items == nil {
+			if err := d.fetchJSON(ctx, id, page.ID, &page); err != nil {
+				return nil, err
+			}
+		}
+		for _, leaf := range page.Items {
+			result = append(result, leaf.CatalogEntry)
+		}
+	}
+
+	d.mu.Lock()
+	if d.leaves == nil {
+		d.leaves = make(map[string][]catalogEntry)
+	}
+	d.leaves[lowerID] = result
+	d.mu.Unlock()
+	return result, nil
+}
+
+func listedVersions(leaves []catalogEntry) []string {
+	result := make([]string, 0, len(leaves))
+	for i := range leaves {
+		if leaves[i].isListed() {
+			result = append(result, leaves[i].Version)
+		}
+	}
+	return result
+}
+
+func findLeaf(leaves []catalogEntry, ver string) *catalogEntry {
+	wanted, ok := parseVersion(ver)
+	if !ok {
+		return nil
+	}
+	for i := range leaves {
+		if v, ok := parseVersion(leaves[i].Version); ok && v.compare(wanted) == 0 {
+			return &leaves[i]
+		}
+	}
+	return nil
+}
+
+// selectGroups returns dependency groups that apply to the target framework.
+func (d *DependencyProvider) selectGroups(groups []dependencyGroup) []dependencyGroup {
+	if d.TargetFramework == "" {
+		return groups
+	}
+	target := parseFramework(d.TargetFramework)
+	best, bestScore := -1, 0
+	for i := range groups {
+		if score := compatibility(target, parseFramework(groups[i].TargetFramework)); score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	return groups[best : best+1]
+}
+
+// leaf returns the catalog entry of the package version, or of the highest
+// listed version if ver is empty.
+func (d *DependencyProvider) leaf(ctx context.Context, id string, ver string) (*catalogEntry, error) {
+	leaves, err := d.fetchLeaves(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ver == "" {
+		ver = highest(listedVersions(leaves))
+	}
+	leaf := findLeaf(leaves, ver)
+	if leaf == nil {
+		return nil, fmt.Errorf("%w: %s %s", dep_errors.ErrPackageNotFound, id, ver)
+	}
+	return leaf, nil
+}
+
+// Resolve accepts "Id" or "Id@version".
+func (d *DependencyProvider) Resolve(ctx context.Context, spec string) (models.Package, error) {
+	id, ver, _ := strings.Cut(spec, "@")
+	leaf, err := d.leaf(ctx, id, ver)
+	if err != nil {
+		return models.Package{}, err
+	}
+	return models.Package{Ecosystem: models.NuGet, Name: canonicalID(id, *leaf), Version: leaf.Version}, nil
+}
+
+// FetchPackageDeps returns dependencies resolved to the lowest version satisfying
+// their ranges. The highest listed version is used if the package has no version.
+func (d *DependencyProvider) FetchPackageDeps(ctx context.Context, pkg models.Package) ([]models.Dependency, error) {
+	leaf, err := d.leaf(ctx, pkg.Name, pkg.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+	var result []models.Dependency
+	for _, group := range d.selectGroups(leaf.DependencyGroups) {
+		for _, dep := range group.Dependencies {
+			key := strings.ToLower(dep.ID)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			resolved, err := d.resolve(ctx, dep)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, models.Dependency{
+				Package:    resolved,
+				Constraint: dep.Range,
+			})
+		}
+	}
+	return result, nil
+}
+
+// resolve returns the package with its registered id at the lowest listed
+// version satisfying the dependency range, the version is empty if there is
+// no such version.
+func (d *DependencyProvider) resolve(ctx context.Context, dep packageDependency) (models.Package, error) {
+	r, ok := parseVersionRange(dep.Range)
+	if !ok {
+		return models.Package{}, fmt.Errorf("%w: invalid version range %q of %s", dep_errors.ErrFetch, dep.Range, dep.ID)
+	}
+	leaves, err := d.fetchLeaves(ctx, dep.ID)
+	if err != nil {
+		return models.Package{}, err
+	}
+	return models.Package{
+		Ecosystem: models.NuGet,
+		Name:      canonicalID(dep.ID, leaves...),
+		Version:   lowestMatching(r, listedVersions(leaves)),
+	}, nil
+}
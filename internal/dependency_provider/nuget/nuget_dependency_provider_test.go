@@ -0,0 +1,135 @@
+package nuget
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newServer() *httptest.Server {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	files := map[string]string{
+		"/v3/index.json": `{"version": "3.0.0", "resources": [
+			{"@id": "{srv}/search", "@type": "SearchQueryService"},
+			{"@id": "{srv}/registration-old/", "@type": "RegistrationsBaseUrl"},
+			{"@id": "{srv}/registration/", "@type": ["RegistrationsBaseUrl/3.6.0"]}
+		]}`,
+		"/registration/serilog/index.json": `{"items": [{"@id": "{srv}/registration/serilog/page.json"}]}`,
+		"/registration/serilog/page.json": `{"items": [
+			{"catalogEntry": {"id": "Serilog", "version": "2.12.0", "dependencyGroups": [
+				{"targetFramework": ".NETFramework4.5", "dependencies": [{"id": "System.Legacy", "range": "[1.0.0, )"}]},
+				{"targetFramework": ".NETStandard2.0", "dependencies": [{"id": "system.memory", "range": "[4.5.0, )"}]},
+				{"targetFramework": "net6.0"}
+			]}},
+			{"catalogEntry": {"id": "Serilog", "version": "3.0.0-dev-1", "dependencyGroups": []}},
+			{"catalogEntry": {"id": "Serilog", "version": "2.11.0", "listed": false, "dependencyGroups": []}}
+		]}`,
+		"/registration/system.memory/index.json": `{"items": [{"@id": "p", "items": [
+			{"catalogEntry": {"id": "System.Memory", "version": "4.5.5"}},
+			{"catalogEntry": {"id": "System.Memory", "version": "4.5.0"}},
+			{"catalogEntry": {"id": "System.Memory", "version": "4.4.0"}}
+		]}]}`,
+		"/registration/system.legacy/index.json": `{"items": [{"@id": "p", "items": [
+			{"catalogEntry": {"id": "System.Legacy", "version": "1.0.1"}}
+		]}]}`,
+		"/registration/broken/index.json": `{"items": [`,
+	}
+	for path, content := range files {
+		content := strings.ReplaceAll(content, "{srv}", srv.URL)
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(content))
+		})
+	}
+	return srv
+}
+
+func TestDependencyProvider_FetchPackageDeps(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	t.Run("test union of all frameworks", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL + "/v3/index.json", Client: &http.Client{}}
+		pkg, err := d.Resolve(ctx, "Serilog")
+		assert.NoError(t, err)
+		assert.Equal(t, models.Package{Ecosystem: models.NuGet, Name: "Serilog", Version: "2.12.0"}, pkg)
+
+		deps, err := d.FetchPackageDeps(ctx, pkg)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{
+				Package:    models.Package{Ecosystem: models.NuGet, Name: "System.Legacy", Version: "1.0.1"},
+				Constraint: "[1.0.0, )",
+			},
+			{
+				Package:    models.Package{Ecosystem: models.NuGet, Name: "System.Memory", Version: "4.5.0"},
+				Constraint: "[4.5.0, )",
+			},
+		}, deps)
+	})
+
+	t.Run("test selecting target framework", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL + "/v3/index.json", Client: &http.Client{}, TargetFramework: "netcoreapp3.1"}
+		pkg, err := d.Resolve(ctx, "serilog@2.12.0")
+		assert.NoError(t, err)
+		assert.Equal(t, models.Package{Ecosystem: models.NuGet, Name: "Serilog", Version: "2.12.0"}, pkg)
+		deps, err := d.FetchPackageDeps(ctx, pkg)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(deps))
+		assert.Equal(t, "System.Memory@4.5.0", deps[0].Package.String())
+
+		d.TargetFramework = "net8.0"
+		deps, err = d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.NuGet, Name: "Serilog"})
+		assert.NoError(t, err)
+		assert.Empty(t, deps)
+	})
+
+	t.Run("test fetching if package or version not found", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL + "/v3/index.json", Client: &http.Client{}}
+		_, err := d.Resolve(ctx, "Missing")
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+
+		_, err = d.Resolve(ctx, "Serilog@9.9.9")
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+
+		_, err = d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.NuGet, Name: "Serilog", Version: "9.9.9"})
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+	})
+
+	t.Run("test fetching if server returns invalid json", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		d := DependencyProvider{BaseURL: srv.URL + "/v3/index.json", Client: &http.Client{}}
+		_, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.NuGet, Name: "broken"})
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+	})
+
+	t.Run("test if fetching url is invalid", func(t *testing.T) {
+		d := DependencyProvider{BaseURL: ":::", Client: &http.Client{}}
+		_, err := d.FetchPackageDeps(context.Background(), models.Package{Ecosystem: models.NuGet, Name: "Serilog"})
+		assert.ErrorIs(t, err, dep_errors.ErrFetch)
+	})
+}
+
+func TestDefault(t *testing.T) {
+	d := Default()
+	assert.NotNil(t, d)
+	assert.NotNil(t, d.Client)
+	assert.Equal(t, "https://api.nuget.org/v3/index.json", d.BaseURL)
+}
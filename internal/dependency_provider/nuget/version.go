@@ -0,0 +1,195 @@
+package nuget
+
+import (
+	"strconv"
+	"strings"
+)
+
+// version is a NuGet SemVer 2.0 version with an optional fourth component.
+type version struct {
+	parts      [4]int
+	prerelease []string
+}
+
+func parseVersion(s string) (version, bool) {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "+")
+	core, pre, hasPre := strings.Cut(s, "-")
+	numbers := strings.Split(core, ".")
+	if len(numbers) == 0 || len(numbers) > 4 {
+		return version{}, false
+	}
+	var v version
+	for i, n := range numbers {
+		value, err := strconv.Atoi(n)
+		if err != nil || value < 0 {
+			return version{}, false
+		}
+		v.parts[i] = value
+	}
+	if hasPre {
+		if pre == "" {
+			return version{}, false
+		}
+		v.prerelease = strings.Split(pre, ".")
+	}
+	return v, true
+}
+
+func (v version) isPrerelease() bool {
+	return len(v.prerelease) != 0
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (v version) compare(other version) int {
+	for i := range v.parts {
+		if c := compareInts(v.parts[i], other.parts[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case !v.isPrerelease() && !other.isPrerelease():
+		return 0
+	case !v.isPrerelease():
+		return 1
+	case !other.isPrerelease():
+		return -1
+	}
+	for i := 0; i < len(v.prerelease) && i < len(other.prerelease); i++ {
+		a, b := v.prerelease[i], other.prerelease[i]
+		an, aErr := strconv.Atoi(a)
+		bn, bErr := strconv.Atoi(b)
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareInts(an, bn); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			// pre-release labels are compared case insensitively
+			if c := strings.Compare(strings.ToLower(a), strings.ToLower(b)); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInts(len(v.prerelease), len(other.prerelease))
+}
+
+// versionRange is a NuGet version range such as "[1.0, 2.0)" or "1.0" (which means ">= 1.0").
+type versionRange struct {
+	lower, upper                   *version
+	lowerInclusive, upperInclusive bool
+}
+
+func parseVersionRange(s string) (versionRange, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return versionRange{}, true
+	}
+	if s[0] != '[' && s[0] != '(' {
+		v, ok := parseVersion(s)
+		return versionRange{lower: &v, lowerInclusive: true}, ok
+	}
+	last := s[len(s)-1]
+	if len(s) < 2 || (last != ']' && last != ')') {
+		return versionRange{}, false
+	}
+	r := versionRange{lowerInclusive: s[0] == '[', upperInclusive: last == ']'}
+	body := s[1 : len(s)-1]
+	lower, upper, hasComma := strings.Cut(body, ",")
+	if !hasComma {
+		// "[1.0]" is an exact version
+		v, ok := parseVersion(body)
+		if !ok || !r.lowerInclusive || !r.upperInclusive {
+			return versionRange{}, false
+		}
+		return versionRange{lower: &v, upper: &v, lowerInclusive: true, upperInclusive: true}, true
+	}
+	if lower = strings.TrimSpace(lower); lower != "" {
+		v, ok := parseVersion(lower)
+		if !ok {
+			return versionRange{}, false
+		}
+		r.lower = &v
+	}
+	if upper = strings.TrimSpace(upper); upper != "" {
+		v, ok := parseVersion(upper)
+		if !ok {
+			return versionRange{}, false
+		}
+		r.upper = &v
+	}
+	return r, true
+}
+
+func (r versionRange) contains(v version) bool {
+	if r.lower != nil {
+		c := v.compare(*r.lower)
+		if c < 0 || (c == 0 && !r.lowerInclusive) {
+			return false
+		}
+	}
+	if r.upper != nil {
+		c := v.compare(*r.upper)
+		if c > 0 || (c == 0 && !r.upperInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// allowsPrerelease reports whether a bound of the range is a pre-release.
+func (r versionRange) allowsPrerelease() bool {
+	return (r.lower != nil && r.lower.isPrerelease()) || (r.upper != nil && r.upper.isPrerelease())
+}
+
+// lowestMatching returns the lowest version inside the range, which is
+// the version NuGet restores for a dependency.
+func lowestMatching(r versionRange, versions []string) string {
+	best := ""
+	var bestVersion version
+	for _, s := range versions {
+		v, ok := parseVersion(s)
+		if !ok || !r.contains(v) || (v.isPrerelease() && !r.allowsPrerelease()) {
+			continue
+		}
+		if best == "" || v.compare(bestVersion) < 0 {
+			best, bestVersion = s, v
+		}
+	}
+	return best
+}
+
+// highest returns the highest stable version, or the highest pre-release if
+// there are no stable versions.
+func highest(versions []string) string {
+	best, bestStable := "", ""
+	var bestVersion, bestStableVersion version
+	for _, s := range versions {
+		v, ok := parseVersion(s)
+		if !ok {
+			continue
+		}
+		if best == "" || v.compare(bestVersion) > 0 {
+			best, bestVersion = s, v
+		}
+		if !v.isPrerelease() && (bestStable == "" || v.compare(bestStableVersion) > 0) {
+			bestStable, bestStableVersion = s, v
+		}
+	}
+	if bestStable != "" {
+		return bestStable
+	}
+	return best
+}
@@ -0,0 +1,65 @@
+package nuget
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_versionRange(t *testing.T) {
+	cases := []struct {
+		spec     string
+		version  string
+		contains bool
+	}{
+		{"1.0", "1.0.0", true},
+		{"1.0", "0.9", false},
+		{"[1.0, 2.0)", "1.9.9.9", true},
+		{"[1.0, 2.0)", "2.0", false},
+		{"(1.0,)", "1.0", false},
+		{"(,1.0]", "1.0", true},
+		{"[1.2.3]", "1.2.3", true},
+		{"[1.2.3]", "1.2.4", false},
+		{"", "0.0.1", true},
+	}
+	for _, c := range cases {
+		r, ok := parseVersionRange(c.spec)
+		assert.True(t, ok, c.spec)
+		v, ok := parseVersion(c.version)
+		assert.True(t, ok, c.version)
+		assert.Equal(t, c.contains, r.contains(v), "%s in %s", c.version, c.spec)
+	}
+
+	_, ok := parseVersionRange("[1.0")
+	assert.False(t, ok)
+	_, ok = parseVersionRange("(1.0)")
+	assert.False(t, ok)
+}
+
+func Test_lowestMatching(t *testing.T) {
+	versions := []string{"13.0.3", "12.0.1", "13.0.1", "14.0.0-beta1"}
+	r, _ := parseVersionRange("12.0.2")
+	assert.Equal(t, "13.0.1", lowestMatching(r, versions))
+	r, _ = parseVersionRange("[14.0.0-alpha, )")
+	assert.Equal(t, "14.0.0-beta1", lowestMatching(r, versions))
+	assert.Equal(t, "13.0.3", highest(versions))
+}
+
+func Test_compatibility(t *testing.T) {
+	groups := []string{".NETFramework4.5", ".NETStandard2.0", "net6.0", ""}
+	nearest := func(target string) string {
+		best, bestScore := "none", 0
+		for _, g := range groups {
+			if score := compatibility(parseFramework(target), parseFramework(g)); score > bestScore {
+				best, bestScore = g, score
+			}
+		}
+		return best
+	}
+	assert.Equal(t, "net6.0", nearest("net8.0"))
+	assert.Equal(t, ".NETStandard2.0", nearest("netcoreapp3.1"))
+	assert.Equal(t, ".NETFramework4.5", nearest("net472"))
+	assert.Equal(t, ".NETStandard2.0", nearest("netstandard2.1"))
+	assert.Equal(t, ".NETFramework4.5", nearest("net45"))
+	assert.Equal(t, "", nearest("net40"))
+	assert.Equal(t, "net6.0", nearest("net6.0-windows"))
+}
@@ -0,0 +1,136 @@
+package pip
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Environment describes the Python installation dependencies are resolved for.
+// Empty fields are unknown: markers depending on them are assumed to hold.
+type Environment struct {
+	PythonVersion                string
+	PythonFullVersion            string
+	OSName                       string
+	SysPlatform                  string
+	PlatformRelease              string
+	PlatformSystem               string
+	PlatformVersion              string
+	PlatformMachine              string
+	PlatformPythonImplementation string
+	ImplementationName           string
+	ImplementationVersion        string
+}
+
+func (e *Environment) fields() map[string]*string {
+	return map[string]*string{
+		"python_version":                 &e.PythonVersion,
+		"python_full_version":            &e.PythonFullVersion,
+		"os_name":                        &e.OSName,
+		"sys_platform":                   &e.SysPlatform,
+		"platform_release":               &e.PlatformRelease,
+		"platform_system":                &e.PlatformSystem,
+		"platform_version":               &e.PlatformVersion,
+		"platform_machine":               &e.PlatformMachine,
+		"platform_python_implementation": &e.PlatformPythonImplementation,
+		"implementation_name":            &e.ImplementationName,
+		"implementation_version":         &e.ImplementationVersion,
+	}
+}
+
+// Set assigns a marker variable such as "python_version" or "sys_platform".
+func (e *Environment) Set(name string, value string) error {
+	canonical, ok := markerVariables[strings.TrimSpace(name)]
+	field := e.fields()[canonical]
+	if !ok || field == nil {
+		return fmt.Errorf("unknown environment marker %q", name)
+	}
+	*field = strings.TrimSpace(value)
+	return nil
+}
+
+// Values returns known marker variables.
+func (e *Environment) Values() map[string]string {
+	result := make(map[string]string)
+	if e == nil {
+		return result
+	}
+	for name, value := range e.fields() {
+		if *value != "" {
+			result[name] = *value
+		}
+	}
+	return result
+}
+
+var platformSystems = map[string]string{
+	"linux":  "Linux",
+	"darwin": "Darwin",
+	"win32":  "Windows",
+	"cygwin": "CYGWIN_NT",
+}
+
+// complete fills variables that follow from the ones that were given.
+func (e *Environment) complete() {
+	if e.PythonFullVersion == "" && e.PythonVersion != "" {
+		e.PythonFullVersion = e.PythonVersion + ".0"
+	}
+	if e.PythonVersion == "" && e.PythonFullVersion != "" {
+		if parts := strings.SplitN(e.PythonFullVersion, ".", 3); len(parts) >= 2 {
+			e.PythonVersion = parts[0] + "." + parts[1]
+		}
+	}
+	if e.PlatformSystem == "" {
+		e.PlatformSystem = platformSystems[e.SysPlatform]
+	}
+	if e.OSName == "" && e.SysPlatform != "" {
+		e.OSName = "posix"
+		if e.SysPlatform == "win32" {
+			e.OSName = "nt"
+		}
+	}
+	if e.ImplementationName == "" && e.PlatformPythonImplementation != "" {
+		e.ImplementationName = strings.ToLower(e.PlatformPythonImplementation)
+	}
+	if e.PlatformPythonImplementation == "" && e.ImplementationName == "cpython" {
+		e.PlatformPythonImplementation = "CPython"
+	}
+	if e.ImplementationVersion == "" && e.ImplementationName == "cpython" {
+		e.ImplementationVersion = e.PythonFullVersion
+	}
+}
+
+// ParseEnvironment parses a comma separated list of assignments like
+// "python_version=3.11,sys_platform=linux,platform_machine=x86_64".
+// Variables that can be derived from the given ones, e.g. os_name from sys_platform, are filled in.
+func ParseEnvironment(spec string) (*Environment, error) {
+	env := &Environment{}
+	for _, assignment := range strings.Split(spec, ",") {
+		if strings.TrimSpace(assignment) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(assignment, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid environment assignment %q", assignment)
+		}
+		if err := env.Set(name, value); err != nil {
+			return nil, err
+		}
+	}
+	env.complete()
+	return env, nil
+}
+
+func (e *Environment) String() string {
+	values := e.Values()
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"="+values[name])
+	}
+	return strings.Join(parts, ",")
+}
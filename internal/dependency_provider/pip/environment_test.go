@@ -0,0 +1,46 @@
+package pip
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseEnvironment(t *testing.T) {
+	t.Run("test derived variables are filled", func(t *testing.T) {
+		env, err := ParseEnvironment("python_version=3.11, sys.platform=linux, implementation_name=cpython")
+		assert.NoError(t, err)
+		assert.Equal(t, &Environment{
+			PythonVersion:                "3.11",
+			PythonFullVersion:            "3.11.0",
+			OSName:                       "posix",
+			SysPlatform:                  "linux",
+			PlatformSystem:               "Linux",
+			PlatformPythonImplementation: "CPython",
+			ImplementationName:           "cpython",
+			ImplementationVersion:        "3.11.0",
+		}, env)
+		assert.Equal(t, "3.11", env.Values()["python_version"])
+	})
+
+	t.Run("test given variables are not overridden", func(t *testing.T) {
+		env, err := ParseEnvironment("python_full_version=3.8.10,sys_platform=win32,os_name=custom")
+		assert.NoError(t, err)
+		assert.Equal(t, "3.8", env.PythonVersion)
+		assert.Equal(t, "Windows", env.PlatformSystem)
+		assert.Equal(t, "custom", env.OSName)
+	})
+
+	t.Run("test invalid environments", func(t *testing.T) {
+		_, err := ParseEnvironment("python_version")
+		assert.Error(t, err)
+		_, err = ParseEnvironment("extra=socks")
+		assert.Error(t, err)
+		_, err = ParseEnvironment("unknown=1")
+		assert.Error(t, err)
+	})
+
+	t.Run("test nil environment has no values", func(t *testing.T) {
+		var env *Environment
+		assert.Empty(t, env.Values())
+	})
+}
@@ -0,0 +1,275 @@
+package pip
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Manifest lists top level requirements of a local project.
+type Manifest struct {
+	Name    string
+	Version string
+	// Requirements are installed with the project.
+	Requirements []*Requirement
+	// Extras are optional requirements of the project by extra.
+	Extras map[string][]*Requirement
+	// Constraints restrict releases of packages keyed by normalized names.
+	Constraints map[string]SpecifierSet
+}
+
+// ReadManifest reads requirements.txt or pyproject.toml. The project is named
+// after its directory unless the manifest names it.
+func ReadManifest(filename string) (*Manifest, error) {
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+	filename = filepath.Join(dir, filepath.Base(filename))
+
+	var m *Manifest
+	if filepath.Base(filename) == "pyproject.toml" {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("can't read manifest: %w", err)
+		}
+		m, err = ParsePyproject(data)
+		if err != nil {
+			return nil, err
+		}
+	} else if m, err = ParseRequirementsFile(filename); err != nil {
+		return nil, err
+	}
+	if m.Name == "" {
+		m.Name = filepath.Base(dir)
+	}
+	return m, nil
+}
+
+// ParsePyproject reads PEP 621 metadata of pyproject.toml.
+func ParsePyproject(data []byte) (*Manifest, error) {
+	var schema struct {
+		Project *struct {
+			Name                 string              `toml:"name"`
+			Version              string              `toml:"version"`
+			Dependencies         []string            `toml:"dependencies"`
+			OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+		} `toml:"project"`
+	}
+	if err := toml.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("can't parse pyproject.toml: %w", err)
+	}
+	if schema.Project == nil {
+		return nil, fmt.Errorf("pyproject.toml has no [project] table")
+	}
+
+	m := &Manifest{Name: schema.Project.Name, Version: schema.Project.Version}
+	for _, dep := range schema.Project.Dependencies {
+		req, err := ParseRequirement(dep)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidPackageName, err)
+		}
+		m.Requirements = append(m.Requirements, req)
+	}
+	for extra, deps := range schema.Project.OptionalDependencies {
+		if m.Extras == nil {
+			m.Extras = make(map[string][]*Requirement)
+		}
+		for _, dep := range deps {
+			req, err := ParseRequirement(dep)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidPackageName, err)
+			}
+			m.Extras[NormalizeName(extra)] = append(m.Extras[NormalizeName(extra)], req)
+		}
+	}
+	return m, nil
+}
+
+// ParseRequirementsFile reads a pip requirements file including files referenced
+// with -r and constraints files referenced with -c. Hashes and other per
+// requirement options are ignored, as are editable installs, local paths and urls
+// which can't be resolved through the index. Referenced files are relative to
+// the file referencing them and may lie outside of its directory.
+func ParseRequirementsFile(filename string) (*Manifest, error) {
+	m := &Manifest{Constraints: make(map[string]SpecifierSet)}
+	if err := m.readRequirements(filename, false, make(map[string]struct{})); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// requirementLines joins continued lines and strips comments.
+func requirementLines(data string) []string {
+	var result []string
+	var current string
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		if strings.HasSuffix(line, `\`) {
+			current += strings.TrimSuffix(line, `\`) + " "
+			continue
+		}
+		line = current + line
+		current = ""
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// option splits an option line such as "-r base.txt", "-rbase.txt" or "--requirement=base.txt".
+func option(line string) (string, string) {
+	if strings.HasPrefix(line, "--") {
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			name, value, _ = strings.Cut(line, " ")
+		}
+		return strings.TrimSpace(name), strings.TrimSpace(value)
+	}
+	if len(line) > 2 && line[2] != ' ' {
+		return line[:2], strings.TrimSpace(line[2:])
+	}
+	name, value, _ := strings.Cut(line, " ")
+	return name, strings.TrimSpace(value)
+}
+
+// includedFile returns the path of a file referenced from the requirements file name.
+func includedFile(name, value string) string {
+	value = filepath.FromSlash(value)
+	if filepath.IsAbs(value) {
+		return value
+	}
+	return filepath.Join(filepath.Dir(name), value)
+}
+
+func (m *Manifest) readRequirements(name string, constraints bool, seen map[string]struct{}) error {
+	name, err := filepath.Abs(name)
+	if err != nil {
+		return err
+	}
+	// files including each other are read once
+	if _, ok := seen[name]; ok {
+		return nil
+	}
+	seen[name] = struct{}{}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("can't read requirements: %w", err)
+	}
+	for _, line := range requirementLines(string(data)) {
+		if strings.HasPrefix(line, "-") {
+			option, value := option(line)
+			switch option {
+			case "-r", "--requirement":
+				err = m.readRequirements(includedFile(name, value), constraints, seen)
+			case "-c", "--constraint":
+				err = m.readRequirements(includedFile(name, value), true, seen)
+			}
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		// options such as --hash follow the requirement
+		if i := strings.Index(line, " --"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		req, err := ParseRequirement(line)
+		if err != nil {
+			if strings.ContainsAny(line, `/\`) {
+				// local paths and urls
+				continue
+			}
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if constraints {
+			key := NormalizeName(req.Name)
+			m.Constraints[key] = append(m.Constraints[key], req.Specifier...)
+		} else {
+			m.Requirements = append(m.Requirements, req)
+		}
+	}
+	return nil
+}
+
+// ManifestProvider draws the graph of a local project. Requirements of the
+// manifest are dependencies of the root, the rest of the graph is resolved
+// by the DependencyProvider.
+type ManifestProvider struct {
+	*DependencyProvider
+	Manifest *Manifest
+}
+
+func NewManifestProvider(provider *DependencyProvider, manifest *Manifest) *ManifestProvider {
+	provider.Constraints = manifest.Constraints
+	return &ManifestProvider{DependencyProvider: provider, Manifest: manifest}
+}
+
+func (m *ManifestProvider) root() models.Package {
+	return models.Package{Ecosystem: models.PyPI, Name: NormalizeName(m.Manifest.Name), Version: m.Manifest.Version}
+}
+
+// Resolve returns the project if spec is empty, otherwise the package from the index.
+func (m *ManifestProvider) Resolve(ctx context.Context, spec string) (models.Package, error) {
+	if spec == "" {
+		return m.root(), nil
+	}
+	return m.DependencyProvider.Resolve(ctx, spec)
+}
+
+// FetchPackageDeps returns requirements of the project, requirements of its
+// extras are marked optional.
+func (m *ManifestProvider) FetchPackageDeps(ctx context.Context, pkg models.Package) ([]models.Dependency, error) {
+	if pkg != m.root() {
+		return m.DependencyProvider.FetchPackageDeps(ctx, pkg)
+	}
+
+	var result []models.Dependency
+	add := func(reqs []*Requirement, optional bool) error {
+		values := m.Environment.Values()
+		for _, req := range reqs {
+			if req.Marker != nil && !markerHolds(req.Marker, values, []string{""}) {
+				continue
+			}
+			resolved, err := m.resolve(ctx, req)
+			if err != nil {
+				return err
+			}
+			result = append(result, models.Dependency{
+				Package:    resolved,
+				Constraint: req.Specifier.String(),
+				Optional:   optional,
+			})
+		}
+		return nil
+	}
+	if err := add(m.Manifest.Requirements, false); err != nil {
+		return nil, err
+	}
+	extras := make([]string, 0, len(m.Manifest.Extras))
+	for extra := range m.Manifest.Extras {
+		extras = append(extras, extra)
+	}
+	sort.Strings(extras)
+	for _, extra := range extras {
+		if err := add(m.Manifest.Extras[extra], true); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
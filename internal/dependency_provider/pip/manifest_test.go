@@ -0,0 +1,162 @@
+package pip
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/pip/test_utils"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, data := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(name), 0o755))
+		assert.NoError(t, os.WriteFile(name, []byte(data), 0o644))
+	}
+}
+
+func TestParseRequirementsFile(t *testing.T) {
+	t.Run("test parses includes, constraints and hashes", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{
+			"requirements.txt": `# production requirements
+-r requirements/base.txt
+--constraint constraints.txt
+-i https://pypi.org/simple
+
+requests[socks]==2.31.0 \
+    --hash=sha256:58cd2187c01e70e6e26505bca751777aa9f2ee0b7f4300988b709f44e013003f \
+    --hash=sha256:942c5a758f98d790eaed1a29cb6eefc7ffb0d1cf7af05c3d2791656dbd6ad1e1
+colorama>=0.4 ; sys_platform == "win32"  # only on windows
+-e ./local-package
+./vendor/wheel-1.0-py3-none-any.whl
+`,
+			"requirements/base.txt": "Django>=3.2\n-r ../requirements.txt\n",
+			"constraints.txt":       "urllib3<2\ndjango<4\n",
+		})
+		m, err := ParseRequirementsFile(filepath.Join(dir, "requirements.txt"))
+		assert.NoError(t, err)
+
+		names := make([]string, 0, len(m.Requirements))
+		for _, req := range m.Requirements {
+			names = append(names, req.Name+req.Specifier.String())
+		}
+		assert.Equal(t, []string{"Django>=3.2", "requests==2.31.0", "colorama>=0.4"}, names)
+		assert.Equal(t, []string{"socks"}, m.Requirements[1].Extras)
+		assert.Equal(t, "<2", m.Constraints["urllib3"].String())
+		assert.Equal(t, "<4", m.Constraints["django"].String())
+	})
+
+	t.Run("test parses includes from parent directories", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{
+			"base.txt":             "requests>=2.31\n",
+			"constraints.txt":      "urllib3<2\n",
+			"app/requirements.txt": "-r ../base.txt\n-c ../constraints.txt\n-r requirements.txt\nrich\n",
+		})
+		m, err := ReadManifest(filepath.Join(dir, "app", "requirements.txt"))
+		assert.NoError(t, err)
+
+		assert.Equal(t, "app", m.Name)
+		names := make([]string, 0, len(m.Requirements))
+		for _, req := range m.Requirements {
+			names = append(names, req.Name+req.Specifier.String())
+		}
+		assert.Equal(t, []string{"requests>=2.31", "rich"}, names)
+		assert.Equal(t, "<2", m.Constraints["urllib3"].String())
+	})
+
+	t.Run("test returns error for invalid requirement", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{"requirements.txt": "requests>>2\n"})
+		_, err := ParseRequirementsFile(filepath.Join(dir, "requirements.txt"))
+		assert.Error(t, err)
+
+		_, err = ParseRequirementsFile(filepath.Join(dir, "missing.txt"))
+		assert.Error(t, err)
+	})
+}
+
+func TestParsePyproject(t *testing.T) {
+	m, err := ParsePyproject([]byte(`
+[build-system]
+requires = ["hatchling"]
+
+[project]
+name = "My_App"
+version = "0.1.0"
+dependencies = ["requests>=2.31", "rich"]
+
+[project.optional-dependencies]
+cli = ["click>=8"]
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, "My_App", m.Name)
+	assert.Equal(t, "0.1.0", m.Version)
+	assert.Len(t, m.Requirements, 2)
+	assert.Equal(t, "click", m.Extras["cli"][0].Name)
+
+	_, err = ParsePyproject([]byte("[tool.black]\nline-length = 100\n"))
+	assert.Error(t, err)
+}
+
+func TestManifestProvider(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/requests/json",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(test_utils.NewProjectResponse("2.31.0", []string{"2.30.0", "2.31.0"}, "urllib3>=1.21.1"))
+		})
+	mux.HandleFunc("/urllib3/json",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(test_utils.NewProjectResponse("2.1.0", []string{"1.26.18", "2.1.0"}))
+		})
+	mux.HandleFunc("/click/json",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(test_utils.NewProjectResponse("8.1.7", []string{"8.1.7"}))
+		})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	manifest, err := ParsePyproject([]byte(`
+[project]
+name = "My_App"
+version = "0.1.0"
+dependencies = ["requests>=2.31", "colorama; sys_platform == 'win32'"]
+optional-dependencies = {cli = ["click"]}
+`))
+	assert.NoError(t, err)
+	manifest.Constraints = make(map[string]SpecifierSet)
+	manifest.Constraints["urllib3"], err = ParseSpecifierSet("<2")
+	assert.NoError(t, err)
+	p := NewManifestProvider(&DependencyProvider{
+		BaseURL:     srv.URL,
+		Client:      &http.Client{},
+		Environment: &Environment{SysPlatform: "linux"},
+	}, manifest)
+
+	root, err := p.Resolve(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, models.Package{Ecosystem: models.PyPI, Name: "my-app", Version: "0.1.0"}, root)
+
+	deps, err := p.FetchPackageDeps(ctx, root)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Dependency{
+		{Package: models.Package{Ecosystem: models.PyPI, Name: "requests", Version: "2.31.0"}, Constraint: ">=2.31"},
+		{Package: models.Package{Ecosystem: models.PyPI, Name: "click", Version: "8.1.7"}, Optional: true},
+	}, deps)
+
+	deps, err = p.FetchPackageDeps(ctx, deps[0].Package)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Dependency{
+		{Package: models.Package{Ecosystem: models.PyPI, Name: "urllib3", Version: "1.26.18"}, Constraint: ">=1.21.1"},
+	}, deps)
+}
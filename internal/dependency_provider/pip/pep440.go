@@ -0,0 +1,373 @@
+package pip
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is a PEP 440 version.
+type Version struct {
+	Epoch   int
+	Release []int
+	// Pre is a pre-release phase ("a", "b" or "rc") and its number
+	Pre    *preRelease
+	Post   *int
+	Dev    *int
+	Local  []string
+	source string
+}
+
+type preRelease struct {
+	Phase  string
+	Number int
+}
+
+var versionPattern = regexp.MustCompile(`^\s*v?` +
+	`(?:(\d+)!)?` +
+	`(\d+(?:\.\d+)*)` +
+	`(?:[-_.]?(a|b|c|rc|alpha|beta|pre|preview)[-_.]?(\d+)?)?` +
+	`(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d+)?)?` +
+	`(?:[-_.]?(dev)[-_.]?(\d+)?)?` +
+	`(?:\+([a-z0-9]+(?:[-_.][a-z0-9]+)*))?\s*$`)
+
+// ParseVersion parses and normalizes a PEP 440 version.
+func ParseVersion(s string) (Version, error) {
+	m := versionPattern.FindStringSubmatch(strings.ToLower(s))
+	if m == nil {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+	v := Version{source: strings.TrimSpace(s)}
+	if m[1] != "" {
+		v.Epoch, _ = strconv.Atoi(m[1])
+	}
+	for _, part := range strings.Split(m[2], ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid version %q", s)
+		}
+		v.Release = append(v.Release, n)
+	}
+	if m[3] != "" {
+		phase := m[3]
+		switch phase {
+		case "alpha":
+			phase = "a"
+		case "beta":
+			phase = "b"
+		case "c", "pre", "preview":
+			phase = "rc"
+		}
+		n, _ := strconv.Atoi(m[4])
+		v.Pre = &preRelease{Phase: phase, Number: n}
+	}
+	if m[5] != "" || m[6] != "" {
+		n, _ := strconv.Atoi(m[5] + m[7])
+		v.Post = &n
+	}
+	if m[8] != "" {
+		n, _ := strconv.Atoi(m[9])
+		v.Dev = &n
+	}
+	if m[10] != "" {
+		v.Local = strings.FieldsFunc(m[10], func(r rune) bool {
+			return r == '-' || r == '_' || r == '.'
+		})
+	}
+	return v, nil
+}
+
+// IsPrerelease reports whether the version is a pre-release or a development release.
+func (v Version) IsPrerelease() bool {
+	return v.Pre != nil || v.Dev != nil
+}
+
+func (v Version) String() string {
+	return v.source
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareRelease(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var l, r int
+		if i < len(a) {
+			l = a[i]
+		}
+		if i < len(b) {
+			r = b[i]
+		}
+		if c := compareInts(l, r); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+var phaseOrder = map[string]int{"a": 0, "b": 1, "rc": 2}
+
+// sortKey mirrors the comparison key of the packaging library.
+func (v Version) sortKey() (pre [2]int, post int, dev int) {
+	switch {
+	case v.Pre == nil && v.Post == nil && v.Dev != nil:
+		// 1.0.dev0 sorts before 1.0a0
+		pre = [2]int{math.MinInt, 0}
+	case v.Pre == nil:
+		pre = [2]int{math.MaxInt, 0}
+	default:
+		pre = [2]int{phaseOrder[v.Pre.Phase], v.Pre.Number}
+	}
+	post = math.MinInt
+	if v.Post != nil {
+		post = *v.Post
+	}
+	dev = math.MaxInt
+	if v.Dev != nil {
+		dev = *v.Dev
+	}
+	return
+}
+
+func compareLocal(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		an, aErr := strconv.Atoi(a[i])
+		bn, bErr := strconv.Atoi(b[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareInts(an, bn); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			return 1
+		case bErr == nil:
+			return -1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInts(len(a), len(b))
+}
+
+// Compare returns -1, 0 or 1 if v is lower, equal or greater than other.
+func (v Version) Compare(other Version) int {
+	if c := compareInts(v.Epoch, other.Epoch); c != 0 {
+		return c
+	}
+	if c := compareRelease(v.Release, other.Release); c != 0 {
+		return c
+	}
+	vPre, vPost, vDev := v.sortKey()
+	oPre, oPost, oDev := other.sortKey()
+	if c := compareInts(vPre[0], oPre[0]); c != 0 {
+		return c
+	}
+	if c := compareInts(vPre[1], oPre[1]); c != 0 {
+		return c
+	}
+	if c := compareInts(vPost, oPost); c != 0 {
+		return c
+	}
+	if c := compareInts(vDev, oDev); c != 0 {
+		return c
+	}
+	return compareLocal(v.Local, other.Local)
+}
+
+// public returns the version without its local label.
+func (v Version) public() Version {
+	v.Local = nil
+	return v
+}
+
+// Specifier is a single version clause such as ">=1.0" or "==2.*".
+type Specifier struct {
+	Operator string
+	Version  string
+	wildcard bool
+	parsed   Version
+}
+
+var specifierPattern = regexp.MustCompile(`^\s*(~=|===|==|!=|<=|>=|<|>)\s*([^\s,;()]+)\s*$`)
+
+func parseSpecifier(s string) (Specifier, error) {
+	m := specifierPattern.FindStringSubmatch(s)
+	if m == nil {
+		return Specifier{}, fmt.Errorf("invalid specifier %q", s)
+	}
+	spec := Specifier{Operator: m[1], Version: m[2]}
+	if spec.Operator == "===" {
+		return spec, nil
+	}
+	version := spec.Version
+	if strings.HasSuffix(version, ".*") {
+		if spec.Operator != "==" && spec.Operator != "!=" {
+			return Specifier{}, fmt.Errorf("invalid specifier %q", s)
+		}
+		spec.wildcard = true
+		version = strings.TrimSuffix(version, ".*")
+	}
+	parsed, err := ParseVersion(version)
+	if err != nil {
+		return Specifier{}, err
+	}
+	if spec.Operator == "~=" && len(parsed.Release) < 2 {
+		return Specifier{}, fmt.Errorf("invalid specifier %q", s)
+	}
+	spec.parsed = parsed
+	return spec, nil
+}
+
+func (s Specifier) String() string {
+	return s.Operator + s.Version
+}
+
+// prefixMatch reports whether v starts with the release segments of prefix.
+func prefixMatch(v Version, prefix Version) bool {
+	if v.Epoch != prefix.Epoch {
+		return false
+	}
+	candidate := v.public()
+	candidateRelease := append([]int(nil), candidate.Release...)
+	for len(candidateRelease) < len(prefix.Release) {
+		candidateRelease = append(candidateRelease, 0)
+	}
+	if compareRelease(candidateRelease[:len(prefix.Release)], prefix.Release) != 0 {
+		return false
+	}
+	// "==1.0rc1.*" also compares the pre-release part
+	if prefix.Pre != nil || prefix.Post != nil || prefix.Dev != nil {
+		p, _, _ := prefix.sortKey()
+		c, _, _ := candidate.sortKey()
+		return p == c
+	}
+	return true
+}
+
+func (s Specifier) contains(v Version) bool {
+	switch s.Operator {
+	case "===":
+		return strings.EqualFold(v.String(), s.Version)
+	case "==":
+		if s.wildcard {
+			return prefixMatch(v, s.parsed)
+		}
+		if len(s.parsed.Local) == 0 {
+			v = v.public()
+		}
+		return v.Compare(s.parsed) == 0
+	case "!=":
+		return !Specifier{Operator: "==", Version: s.Version, wildcard: s.wildcard, parsed: s.parsed}.contains(v)
+	case "~=":
+		prefix := s.parsed
+		prefix.Release = prefix.Release[:len(prefix.Release)-1]
+		prefix.Pre, prefix.Post, prefix.Dev = nil, nil, nil
+		return v.public().Compare(s.parsed) >= 0 && prefixMatch(v, prefix)
+	case "<=":
+		return v.public().Compare(s.parsed) <= 0
+	case ">=":
+		return v.public().Compare(s.parsed) >= 0
+	case "<":
+		if v.Compare(s.parsed) >= 0 {
+			return false
+		}
+		// <3.0 excludes 3.0a1 unless the specifier is a pre-release itself
+		return s.parsed.IsPrerelease() || !v.IsPrerelease() ||
+			compareRelease(v.Release, s.parsed.Release) != 0 || v.Epoch != s.parsed.Epoch
+	case ">":
+		if v.public().Compare(s.parsed) <= 0 {
+			return false
+		}
+		// >1.0 excludes 1.0.post1 unless the specifier is a post-release itself
+		if s.parsed.Post == nil && v.Post != nil && v.Epoch == s.parsed.Epoch &&
+			compareRelease(v.Release, s.parsed.Release) == 0 {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// SpecifierSet is a comma separated list of specifiers that must all hold.
+type SpecifierSet []Specifier
+
+// ParseSpecifierSet parses specifiers like ">=1.0,!=1.3.*,<2".
+func ParseSpecifierSet(s string) (SpecifierSet, error) {
+	var result SpecifierSet
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		spec, err := parseSpecifier(part)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, spec)
+	}
+	return result, nil
+}
+
+func (s SpecifierSet) String() string {
+	parts := make([]string, 0, len(s))
+	for _, spec := range s {
+		parts = append(parts, spec.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// allowsPrereleases reports whether a specifier explicitly mentions a pre-release.
+func (s SpecifierSet) allowsPrereleases() bool {
+	for _, spec := range s {
+		if spec.Operator != "!=" && spec.Operator != "===" && spec.parsed.IsPrerelease() {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains reports whether the version satisfies every specifier.
+func (s SpecifierSet) Contains(v Version) bool {
+	for _, spec := range s {
+		if !spec.contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Best returns the highest of versions in the set. Pre-releases are only picked
+// if the set mentions one or if no final release matches, as pip does.
+func (s SpecifierSet) Best(versions []string) string {
+	best, bestPre := "", ""
+	var bestVersion, bestPreVersion Version
+	for _, candidate := range versions {
+		v, err := ParseVersion(candidate)
+		if err != nil || !s.Contains(v) {
+			continue
+		}
+		if v.IsPrerelease() && !s.allowsPrereleases() {
+			if bestPre == "" || v.Compare(bestPreVersion) > 0 {
+				bestPre, bestPreVersion = candidate, v
+			}
+			continue
+		}
+		if best == "" || v.Compare(bestVersion) > 0 {
+			best, bestVersion = candidate, v
+		}
+	}
+	if best != "" {
+		return best
+	}
+	return bestPre
+}
@@ -0,0 +1,94 @@
+package pip
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestVersion_Compare(t *testing.T) {
+	ordered := []string{
+		"1.0.dev456", "1.0a1", "1.0a2.dev456", "1.0a12.dev456", "1.0a12",
+		"1.0b1.dev456", "1.0b2", "1.0b2.post345.dev456", "1.0b2.post345",
+		"1.0rc1.dev456", "1.0rc1", "1.0", "1.0+abc.5", "1.0+abc.7", "1.0+5",
+		"1.0.post456.dev34", "1.0.post456", "1.1.dev1", "1!0.1",
+	}
+	for i := 0; i+1 < len(ordered); i++ {
+		a, err := ParseVersion(ordered[i])
+		assert.NoError(t, err)
+		b, err := ParseVersion(ordered[i+1])
+		assert.NoError(t, err)
+		assert.Equal(t, -1, a.Compare(b), "%s < %s", ordered[i], ordered[i+1])
+		assert.Equal(t, 1, b.Compare(a), "%s > %s", ordered[i+1], ordered[i])
+	}
+
+	a, _ := ParseVersion("v1.0.0")
+	b, _ := ParseVersion("1.0")
+	assert.Equal(t, 0, a.Compare(b))
+
+	_, err := ParseVersion("1.0-final")
+	assert.Error(t, err)
+
+	a, _ = ParseVersion("1.0-1")
+	b, _ = ParseVersion("1.0.post1")
+	assert.Equal(t, 0, a.Compare(b))
+
+	a, _ = ParseVersion("1.0alpha2")
+	b, _ = ParseVersion("1.0a2")
+	assert.Equal(t, 0, a.Compare(b))
+}
+
+func TestSpecifierSet_Contains(t *testing.T) {
+	cases := []struct {
+		specifier string
+		version   string
+		contains  bool
+	}{
+		{"~=2.2", "2.3", true},
+		{"~=2.2", "3.0", false},
+		{"~=1.4.5", "1.4.9", true},
+		{"~=1.4.5", "1.5.0", false},
+		{"==1.1.*", "1.1.post1", true},
+		{"==1.1.*", "1.10", false},
+		{"==1.1", "1.1.0", true},
+		{"==1.1", "1.1+local", true},
+		{"==1.1+local", "1.1", false},
+		{"!=1.1.*", "1.1.5", false},
+		{">=1.0,<2.0", "1.9", true},
+		{">=1.0,<2.0", "2.0", false},
+		{"<2.0", "2.0a1", false},
+		{"<2.0a5", "2.0a1", true},
+		{">1.7", "1.7.post2", false},
+		{">1.7.post1", "1.7.post2", true},
+		{">1.7", "1.7.1", true},
+		{"<=1.7", "1.7+local", true},
+		{"===1.0", "1.0", true},
+		{"===1.0", "1.0.0", false},
+	}
+	for _, c := range cases {
+		set, err := ParseSpecifierSet(c.specifier)
+		assert.NoError(t, err, c.specifier)
+		v, err := ParseVersion(c.version)
+		assert.NoError(t, err, c.version)
+		assert.Equal(t, c.contains, set.Contains(v), "%s in %s", c.version, c.specifier)
+	}
+
+	for _, invalid := range []string{"=1.0", "~=1", ">=1.*", ">= one"} {
+		_, err := ParseSpecifierSet(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestSpecifierSet_Best(t *testing.T) {
+	versions := []string{"1.0", "1.9", "2.0b1", "2.0.post1", "2.0", "3.0rc1", "not.a.version!"}
+	set, _ := ParseSpecifierSet(">=1.0")
+	assert.Equal(t, "2.0.post1", set.Best(versions))
+
+	set, _ = ParseSpecifierSet(">=3.0rc1")
+	assert.Equal(t, "3.0rc1", set.Best(versions))
+
+	set, _ = ParseSpecifierSet(">2.5")
+	assert.Equal(t, "3.0rc1", set.Best(versions))
+
+	set, _ = ParseSpecifierSet(">4")
+	assert.Equal(t, "", set.Best(versions))
+}
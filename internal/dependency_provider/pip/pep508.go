@@ -0,0 +1,413 @@
+package pip
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Requirement is a PEP 508 dependency specification.
+type Requirement struct {
+	Name      string
+	Extras    []string
+	Specifier SpecifierSet
+	URL       string
+	Marker    *Marker
+}
+
+var (
+	namePattern   = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?`)
+	normalizeRuns = regexp.MustCompile(`[-_.]+`)
+)
+
+// NormalizeName returns the PEP 503 normalized form of a project name.
+func NormalizeName(name string) string {
+	return strings.ToLower(normalizeRuns.ReplaceAllString(name, "-"))
+}
+
+// ParseRequirement parses entries such as `requests[socks]>=2.8.1,==2.*; python_version >= "3.7"`.
+func ParseRequirement(s string) (*Requirement, error) {
+	rest := strings.TrimSpace(s)
+	name := namePattern.FindString(rest)
+	if name == "" {
+		return nil, fmt.Errorf("invalid requirement %q: missing name", s)
+	}
+	req := &Requirement{Name: name}
+	rest = strings.TrimSpace(rest[len(name):])
+
+	if strings.HasPrefix(rest, "[") {
+		end := strings.IndexByte(rest, ']')
+		if end < 0 {
+			return nil, fmt.Errorf("invalid requirement %q: unclosed extras", s)
+		}
+		for _, extra := range strings.Split(rest[1:end], ",") {
+			extra = strings.TrimSpace(extra)
+			if extra == "" {
+				continue
+			}
+			if namePattern.FindString(extra) != extra {
+				return nil, fmt.Errorf("invalid requirement %q: invalid extra %q", s, extra)
+			}
+			req.Extras = append(req.Extras, NormalizeName(extra))
+		}
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+
+	if strings.HasPrefix(rest, "@") {
+		// a url must be separated from the marker by whitespace
+		rest = strings.TrimSpace(rest[1:])
+		end := strings.Index(rest, " ;")
+		if end < 0 {
+			end = strings.Index(rest, "\t;")
+		}
+		if end < 0 {
+			req.URL = rest
+			rest = ""
+		} else {
+			req.URL = rest[:end]
+			rest = strings.TrimSpace(rest[end:])
+		}
+		if req.URL == "" {
+			return nil, fmt.Errorf("invalid requirement %q: empty url", s)
+		}
+	} else {
+		specifier := rest
+		if i := strings.IndexByte(rest, ';'); i >= 0 {
+			specifier, rest = rest[:i], rest[i:]
+		} else {
+			rest = ""
+		}
+		specifier = strings.TrimSpace(specifier)
+		if strings.HasPrefix(specifier, "(") && strings.HasSuffix(specifier, ")") {
+			specifier = specifier[1 : len(specifier)-1]
+		}
+		set, err := ParseSpecifierSet(specifier)
+		if err != nil {
+			return nil, fmt.Errorf("invalid requirement %q: %w", s, err)
+		}
+		req.Specifier = set
+	}
+
+	if rest != "" {
+		if !strings.HasPrefix(rest, ";") {
+			return nil, fmt.Errorf("invalid requirement %q: unexpected %q", s, rest)
+		}
+		marker, err := ParseMarker(strings.TrimSpace(rest[1:]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid requirement %q: %w", s, err)
+		}
+		req.Marker = marker
+	}
+	return req, nil
+}
+
+// Marker is a parsed PEP 508 environment marker.
+type Marker struct {
+	expr markerExpr
+	text string
+}
+
+func (m *Marker) String() string {
+	return m.text
+}
+
+// Variables returns sorted names of environment variables the marker refers to.
+func (m *Marker) Variables() []string {
+	set := make(map[string]struct{})
+	m.expr.variables(set)
+	result := make([]string, 0, len(set))
+	for name := range set {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Mentions reports whether the marker refers to the environment variable.
+func (m *Marker) Mentions(variable string) bool {
+	set := make(map[string]struct{})
+	m.expr.variables(set)
+	_, ok := set[variable]
+	return ok
+}
+
+// Evaluate reports whether the marker may hold for the given variables.
+// Variables missing from values are unknown, so the result is false only if
+// the marker is false whatever they are.
+func (m *Marker) Evaluate(values map[string]string) bool {
+	return m.expr.evaluate(values) != markerFalse
+}
+
+// markerResult is a three-valued logic result of evaluation.
+type markerResult int
+
+const (
+	markerFalse markerResult = iota
+	markerTrue
+	markerUnknown
+)
+
+func toMarkerResult(b bool) markerResult {
+	if b {
+		return markerTrue
+	}
+	return markerFalse
+}
+
+type markerExpr interface {
+	variables(set map[string]struct{})
+	evaluate(values map[string]string) markerResult
+}
+
+type markerBool struct {
+	op          string // "and" or "or"
+	left, right markerExpr
+}
+
+func (b *markerBool) variables(set map[string]struct{}) {
+	b.left.variables(set)
+	b.right.variables(set)
+}
+
+func (b *markerBool) evaluate(values map[string]string) markerResult {
+	left, right := b.left.evaluate(values), b.right.evaluate(values)
+	if b.op == "and" {
+		switch {
+		case left == markerFalse || right == markerFalse:
+			return markerFalse
+		case left == markerTrue && right == markerTrue:
+			return markerTrue
+		}
+		return markerUnknown
+	}
+	switch {
+	case left == markerTrue || right == markerTrue:
+		return markerTrue
+	case left == markerFalse && right == markerFalse:
+		return markerFalse
+	}
+	return markerUnknown
+}
+
+type markerValue struct {
+	variable string
+	literal  string
+}
+
+type markerCompare struct {
+	left  markerValue
+	op    string
+	right markerValue
+}
+
+func (c *markerCompare) variables(set map[string]struct{}) {
+	for _, v := range []markerValue{c.left, c.right} {
+		if v.variable != "" {
+			set[v.variable] = struct{}{}
+		}
+	}
+}
+
+func (v markerValue) resolve(values map[string]string) (string, bool) {
+	if v.variable == "" {
+		return v.literal, true
+	}
+	value, ok := values[v.variable]
+	return value, ok
+}
+
+func (c *markerCompare) evaluate(values map[string]string) markerResult {
+	left, ok := c.left.resolve(values)
+	if !ok {
+		return markerUnknown
+	}
+	right, ok := c.right.resolve(values)
+	if !ok {
+		return markerUnknown
+	}
+	// extra names are compared normalized
+	if c.left.variable == "extra" || c.right.variable == "extra" {
+		left, right = NormalizeName(left), NormalizeName(right)
+	}
+
+	switch c.op {
+	case "in":
+		return toMarkerResult(strings.Contains(right, left))
+	case "not in":
+		return toMarkerResult(!strings.Contains(right, left))
+	}
+
+	// values are compared as versions when both of them are valid, as strings otherwise
+	if spec, err := parseSpecifier(c.op + right); err == nil {
+		if v, err := ParseVersion(left); err == nil {
+			return toMarkerResult(spec.contains(v))
+		}
+	}
+	switch c.op {
+	case "==", "===":
+		return toMarkerResult(left == right)
+	case "!=":
+		return toMarkerResult(left != right)
+	case "<":
+		return toMarkerResult(left < right)
+	case "<=":
+		return toMarkerResult(left <= right)
+	case ">":
+		return toMarkerResult(left > right)
+	case ">=":
+		return toMarkerResult(left >= right)
+	}
+	return markerFalse
+}
+
+// markerVariables maps accepted variable names, including legacy dotted ones,
+// to their canonical names.
+var markerVariables = map[string]string{
+	"python_version":                 "python_version",
+	"python_full_version":            "python_full_version",
+	"os_name":                        "os_name",
+	"os.name":                        "os_name",
+	"sys_platform":                   "sys_platform",
+	"sys.platform":                   "sys_platform",
+	"platform_release":               "platform_release",
+	"platform.release":               "platform_release",
+	"platform_system":                "platform_system",
+	"platform.system":                "platform_system",
+	"platform_version":               "platform_version",
+	"platform.version":               "platform_version",
+	"platform_machine":               "platform_machine",
+	"platform.machine":               "platform_machine",
+	"platform_python_implementation": "platform_python_implementation",
+	"platform.python_implementation": "platform_python_implementation",
+	"python_implementation":          "platform_python_implementation",
+	"implementation_name":            "implementation_name",
+	"implementation_version":         "implementation_version",
+	"extra":                          "extra",
+}
+
+type markerParser struct {
+	tokens []string
+	pos    int
+}
+
+var markerToken = regexp.MustCompile(`\s*(\(|\)|'[^']*'|"[^"]*"|===|==|!=|~=|<=|>=|<|>|[A-Za-z_][A-Za-z0-9_.]*)`)
+
+// ParseMarker parses an environment marker such as `python_version < "3.8" and extra == "socks"`.
+func ParseMarker(s string) (*Marker, error) {
+	p := &markerParser{}
+	rest := s
+	for strings.TrimSpace(rest) != "" {
+		loc := markerToken.FindStringSubmatchIndex(rest)
+		if loc == nil || loc[0] != 0 {
+			return nil, fmt.Errorf("invalid marker %q", s)
+		}
+		p.tokens = append(p.tokens, rest[loc[2]:loc[3]])
+		rest = rest[loc[1]:]
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid marker %q: %w", s, err)
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("invalid marker %q: unexpected %q", s, p.tokens[p.pos])
+	}
+	return &Marker{expr: expr, text: strings.TrimSpace(s)}, nil
+}
+
+func (p *markerParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *markerParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *markerParser) parseOr() (markerExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "or" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &markerBool{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *markerParser) parseAnd() (markerExpr, error) {
+	left, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "and" {
+		p.next()
+		right, err := p.parseAtom()
+		if err != nil {
+			return nil, err
+		}
+		left = &markerBool{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *markerParser) parseAtom() (markerExpr, error) {
+	if p.peek() == "(" {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return expr, nil
+	}
+
+	left, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	op := p.next()
+	switch op {
+	case "===", "==", "!=", "~=", "<=", ">=", "<", ">", "in":
+	case "not":
+		if p.next() != "in" {
+			return nil, fmt.Errorf("expected 'in' after 'not'")
+		}
+		op = "not in"
+	default:
+		return nil, fmt.Errorf("expected comparison operator, got %q", op)
+	}
+	right, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if left.variable == "" && right.variable == "" {
+		return nil, fmt.Errorf("comparison of two literals")
+	}
+	return &markerCompare{left: left, op: op, right: right}, nil
+}
+
+func (p *markerParser) parseValue() (markerValue, error) {
+	t := p.next()
+	if len(t) >= 2 && (t[0] == '"' || t[0] == '\'') {
+		return markerValue{literal: t[1 : len(t)-1]}, nil
+	}
+	if name, ok := markerVariables[t]; ok {
+		return markerValue{variable: name}, nil
+	}
+	if t == "" {
+		return markerValue{}, fmt.Errorf("unexpected end of marker")
+	}
+	return markerValue{}, fmt.Errorf("unknown marker variable %q", t)
+}
@@ -0,0 +1,89 @@
+package pip
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseRequirement(t *testing.T) {
+	t.Run("test parsing full requirement", func(t *testing.T) {
+		req, err := ParseRequirement(`Requests[Socks, use_chardet_on_py3] (>=2.8.1,==2.*) ; python_version >= "3.7" and (extra == 'socks' or os_name != "nt")`)
+		assert.NoError(t, err)
+		assert.Equal(t, "Requests", req.Name)
+		assert.Equal(t, []string{"socks", "use-chardet-on-py3"}, req.Extras)
+		assert.Equal(t, ">=2.8.1,==2.*", req.Specifier.String())
+		assert.Equal(t, []string{"extra", "os_name", "python_version"}, req.Marker.Variables())
+		assert.True(t, req.Marker.Mentions("extra"))
+	})
+
+	t.Run("test names are not confused with markers", func(t *testing.T) {
+		req, err := ParseRequirement("extra-utils>=1")
+		assert.NoError(t, err)
+		assert.Equal(t, "extra-utils", req.Name)
+		assert.Nil(t, req.Marker)
+	})
+
+	t.Run("test parsing url requirement", func(t *testing.T) {
+		req, err := ParseRequirement("pip @ https://github.com/pypa/pip/archive/1.3.1.zip#sha1=da9234ee ; sys.platform == 'win32'")
+		assert.NoError(t, err)
+		assert.Equal(t, "https://github.com/pypa/pip/archive/1.3.1.zip#sha1=da9234ee", req.URL)
+		assert.Equal(t, []string{"sys_platform"}, req.Marker.Variables())
+	})
+
+	t.Run("test parsing invalid requirements", func(t *testing.T) {
+		for _, invalid := range []string{
+			"",
+			"name[extra",
+			"name=1.0",
+			"name; extra",
+			"name; python_version <",
+			"name; unknown == '1'",
+			"name; '1' == '1'",
+			"name; (os_name == 'nt'",
+			"name @ ",
+		} {
+			_, err := ParseRequirement(invalid)
+			assert.Error(t, err, invalid)
+		}
+	})
+}
+
+func TestNormalizeName(t *testing.T) {
+	assert.Equal(t, "friendly-bard", NormalizeName("Friendly-Bard"))
+	assert.Equal(t, "friendly-bard", NormalizeName("FRIENDLY_BARD"))
+	assert.Equal(t, "friendly-bard", NormalizeName("friendly.._bard"))
+}
+
+func TestMarker_Evaluate(t *testing.T) {
+	env, err := ParseEnvironment("python_version=3.11,sys_platform=linux,platform_machine=x86_64,implementation_name=cpython")
+	assert.NoError(t, err)
+	values := env.Values()
+
+	cases := map[string]bool{
+		`python_version < "3.8"`:                                                     false,
+		`python_version >= "3.8"`:                                                    true,
+		`"3.8" < python_version`:                                                     true,
+		`python_full_version >= "3.11.0"`:                                            true,
+		`sys_platform == "win32" or platform_system == "Linux"`:                      true,
+		`sys_platform == "darwin" and python_version >= "3"`:                         false,
+		`os_name == "nt"`:                                                            false,
+		`platform_machine in "x86_64 aarch64"`:                                       true,
+		`platform_machine not in "arm64"`:                                            true,
+		`implementation_name == "pypy"`:                                              false,
+		`platform.python_implementation == 'CPython'`:                                true,
+		`(python_version < "3.9" or sys_platform == "linux") and os_name == "posix"`: true,
+		// unknown variables may hold
+		`platform_release >= "6"`:                         true,
+		`platform_release >= "6" and os_name == "nt"`:     false,
+		`platform_release >= "6" or python_version < "3"`: true,
+	}
+	for text, expected := range cases {
+		m, err := ParseMarker(text)
+		assert.NoError(t, err, text)
+		assert.Equal(t, expected, m.Evaluate(values), text)
+	}
+
+	m, _ := ParseMarker(`extra == "Socks_Proxy"`)
+	assert.True(t, m.Evaluate(map[string]string{"extra": "socks-proxy"}))
+	assert.False(t, m.Evaluate(map[string]string{"extra": ""}))
+}
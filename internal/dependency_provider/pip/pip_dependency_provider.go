@@ -3,18 +3,30 @@ package pip
 import (
 	"context"
 	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 )
 
 type DependencyProvider struct {
 	BaseURL string
 	Client  *http.Client
+	// Environment is the target python environment used to evaluate markers.
+	// Requirements are kept if their markers depend on unknown variables.
+	Environment *Environment
+	// Constraints restrict releases picked for packages keyed by normalized
+	// names, the way constraints files given to pip with -c do.
+	Constraints map[string]SpecifierSet
+
+	mu       sync.Mutex
+	projects map[string]*project
 }
 
 func Default() *DependencyProvider {
@@ -24,8 +36,19 @@ func Default() *DependencyProvider {
 	}
 }
 
-func (d *DependencyProvider) fetch(ctx context.Context, packageName string) (io.ReadCloser, error) {
-	uri, err := url.JoinPath(d.BaseURL, packageName, "json")
+// project holds the data of /pypi/{name}/json needed for resolution.
+type project struct {
+	// Version is the latest version, RequiresDist are its requirements
+	Version      string
+	RequiresDist []string
+	// Releases lists versions that have at least one not yanked file
+	Releases []string
+	// Yanked lists versions whose files are all yanked. They are only picked by pins.
+	Yanked []string
+}
+
+func (d *DependencyProvider) fetch(ctx context.Context, packageName string, elem ...string) (io.ReadCloser, error) {
+	uri, err := url.JoinPath(d.BaseURL, append([]string{packageName}, elem...)...)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
 	}
@@ -39,6 +62,7 @@ func (d *DependencyProvider) fetch(ctx context.Context, packageName string) (io.
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
 	} else if resp.StatusCode == 404 {
+		_ = resp.Body.Close()
 		return nil, fmt.Errorf("%w: %s", dep_errors.ErrPackageNotFound, packageName)
 	}
 
@@ -69,32 +93,253 @@ func parsePackageDeps(reader io.Reader) ([]string, error) {
 	return nil, fmt.Errorf("%w: invalid json", dep_errors.ErrFetch)
 }
 
-func (d *DependencyProvider) FetchPackageDeps(ctx context.Context, packageName string) ([]string, error) {
-	data, err := d.fetch(ctx, packageName)
+func parseProject(reader io.Reader) (*project, error) {
+	var schema struct {
+		Info *struct {
+			Version      string          `json:"version"`
+			RequiresDist json.RawMessage `json:"requires_dist"`
+		} `json:"info"`
+		Releases map[string][]struct {
+			Yanked bool `json:"yanked"`
+		} `json:"releases"`
+	}
+	if err := json.NewDecoder(reader).Decode(&schema); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	if schema.Info == nil || schema.Info.RequiresDist == nil {
+		return nil, fmt.Errorf("%w: invalid json schema", dep_errors.ErrFetch)
+	}
+
+	result := &project{Version: schema.Info.Version}
+	if err := json.Unmarshal(schema.Info.RequiresDist, &result.RequiresDist); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrFetch, err)
+	}
+	for version, files := range schema.Releases {
+		yanked := len(files) != 0
+		for _, file := range files {
+			if !file.Yanked {
+				yanked = false
+				break
+			}
+		}
+		if yanked {
+			result.Yanked = append(result.Yanked, version)
+		} else if len(files) != 0 {
+			result.Releases = append(result.Releases, version)
+		}
+	}
+	return result, nil
+}
+
+func (d *DependencyProvider) project(ctx context.Context, name string) (*project, error) {
+	key := NormalizeName(name)
+	d.mu.Lock()
+	cached, ok := d.projects[key]
+	d.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	body, err := d.fetch(ctx, name, "json")
 	if err != nil {
 		return nil, err
 	}
-	deps, err := parsePackageDeps(data)
+	defer func() {
+		_ = body.Close()
+	}()
+	result, err := parseProject(body)
 	if err != nil {
 		return nil, err
 	}
-	return cleanPackageDeps(deps), nil
+
+	d.mu.Lock()
+	if d.projects == nil {
+		d.projects = make(map[string]*project)
+	}
+	d.projects[key] = result
+	d.mu.Unlock()
+	return result, nil
 }
 
-func cleanPackageDeps(deps []string) []string {
-	pattern, err := regexp.Compile(`^[a-zA-Z\-_0-9.]+`)
+// bestVersion picks the release pip would install for the specifier set.
+func (p *project) bestVersion(specifier SpecifierSet) string {
+	candidates := p.Releases
+	if len(candidates) == 0 && p.Version != "" {
+		candidates = []string{p.Version}
+	}
+	return specifier.Best(candidates)
+}
+
+// requiresDist returns requirements of a specific release.
+func (d *DependencyProvider) requiresDist(ctx context.Context, name string, version string) ([]string, error) {
+	p, err := d.project(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if version == "" || version == p.Version {
+		return p.RequiresDist, nil
+	}
+
+	body, err := d.fetch(ctx, name, version, "json")
 	if err != nil {
-		panic(err.Error())
+		return nil, err
 	}
+	defer func() {
+		_ = body.Close()
+	}()
+	return parsePackageDeps(body)
+}
 
-	result := make([]string, 0, len(deps))
+// packageName returns the normalized name with sorted extras, e.g. "requests[socks]".
+// Packages installed with different extras have different dependencies, so
+// extras are a part of the node name.
+func packageName(req *Requirement) string {
+	name := NormalizeName(req.Name)
+	if len(req.Extras) != 0 {
+		extras := append([]string(nil), req.Extras...)
+		sort.Strings(extras)
+		name += "[" + strings.Join(extras, ",") + "]"
+	}
+	return name
+}
+
+// specifier returns the specifier set of the requirement narrowed by constraints.
+func (d *DependencyProvider) specifier(req *Requirement) SpecifierSet {
+	constraint, ok := d.Constraints[NormalizeName(req.Name)]
+	if !ok {
+		return req.Specifier
+	}
+	return append(append(SpecifierSet(nil), req.Specifier...), constraint...)
+}
+
+// resolve returns the package with the best release matching the requirement.
+// The version is empty if the index has no release information.
+func (d *DependencyProvider) resolve(ctx context.Context, req *Requirement) (models.Package, error) {
+	result := models.Package{Ecosystem: models.PyPI, Name: packageName(req)}
+	if req.URL != "" {
+		return result, nil
+	}
+	p, err := d.project(ctx, req.Name)
+	if err != nil {
+		return models.Package{}, err
+	}
+	result.Version = p.bestVersion(d.specifier(req))
+	return result, nil
+}
+
+// Resolve accepts a requirement such as "django", "django==3.2.18",
+// "django>=3,<4" or "fastapi[all]" and picks the matching release. Pinned
+// releases are matched the PEP 440 way, so "django==3.2" picks "3.2.0" if that
+// is how the release was published.
+func (d *DependencyProvider) Resolve(ctx context.Context, spec string) (models.Package, error) {
+	req, err := ParseRequirement(spec)
+	if err != nil {
+		return models.Package{}, fmt.Errorf("%w: %s", dep_errors.ErrInvalidPackageName, err)
+	}
+
+	result := models.Package{Ecosystem: models.PyPI, Name: packageName(req)}
+	p, err := d.project(ctx, req.Name)
+	if err != nil {
+		return models.Package{}, err
+	}
+	if len(req.Specifier) == 0 {
+		result.Version = p.Version
+	} else if result.Version = p.bestVersion(req.Specifier); result.Version == "" {
+		if pinned, ok := pinnedVersion(req.Specifier); ok {
+			// yanked releases can still be installed by pinning them
+			if result.Version = req.Specifier.Best(p.Yanked); result.Version != "" {
+				return result, nil
+			}
+			// a mirror may not list releases, the metadata is fetched from /{name}/{version}/json anyway
+			if len(p.Releases) == 0 {
+				result.Version = pinned
+				return result, nil
+			}
+		}
+		return models.Package{}, fmt.Errorf("%w: no release of %s matches %s", dep_errors.ErrPackageNotFound, req.Name, req.Specifier)
+	}
+	return result, nil
+}
+
+// pinnedVersion returns the version of a specifier set made of a single "==" or "===" clause.
+func pinnedVersion(specifier SpecifierSet) (string, bool) {
+	if len(specifier) != 1 || specifier[0].wildcard {
+		return "", false
+	}
+	if specifier[0].Operator != "==" && specifier[0].Operator != "===" {
+		return "", false
+	}
+	return specifier[0].Version, true
+}
+
+// FetchPackageDeps returns requirements of the release resolved to the best
+// matching releases. Requirements of the extras in the package name are included
+// and marked optional.
+func (d *DependencyProvider) FetchPackageDeps(ctx context.Context, pkg models.Package) ([]models.Dependency, error) {
+	req, err := ParseRequirement(pkg.Name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidPackageName, err)
+	}
+
+	deps, err := d.requiresDist(ctx, req.Name, pkg.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.Dependency, 0, len(deps))
+	for _, dep := range cleanPackageDeps(deps, d.Environment, req.Extras) {
+		resolved, err := d.resolve(ctx, dep)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, models.Dependency{
+			Package:    resolved,
+			Constraint: dep.Specifier.String(),
+			Optional:   dep.Marker != nil && dep.Marker.Mentions("extra"),
+		})
+	}
+	return result, nil
+}
+
+var legacyName = regexp.MustCompile(`^[a-zA-Z\-_0-9.]+`)
+
+// cleanPackageDeps parses requires_dist entries. Entries whose markers don't
+// hold in env for any of the requested extras are skipped. Entries that aren't
+// valid PEP 508 fall back to their name.
+func cleanPackageDeps(deps []string, env *Environment, extras []string) []*Requirement {
+	values := env.Values()
+	// "" stands for the package installed without extras
+	candidates := append([]string{""}, extras...)
+
+	result := make([]*Requirement, 0, len(deps))
 	for _, dep := range deps {
-		// ignore extra dependencies
-		if strings.Contains(dep, "extra") {
+		req, err := ParseRequirement(dep)
+		if err != nil {
+			// metadata of old releases sometimes has malformed specifiers, but a
+			// malformed marker makes it impossible to tell if the entry applies
+			if strings.Contains(dep, ";") {
+				continue
+			}
+			name := legacyName.FindString(dep)
+			if name == "" {
+				continue
+			}
+			req = &Requirement{Name: name}
+		}
+		if req.Marker != nil && !markerHolds(req.Marker, values, candidates) {
 			continue
 		}
-
-		result = append(result, string(pattern.Find([]byte(dep))))
+		result = append(result, req)
 	}
 	return result
 }
+
+func markerHolds(marker *Marker, values map[string]string, extras []string) bool {
+	for _, extra := range extras {
+		values["extra"] = extra
+		if marker.Evaluate(values) {
+			return true
+		}
+	}
+	return false
+}
@@ -5,10 +5,10 @@ import (
 	"context"
 	"depviz/internal/dependency_provider/dep_errors"
 	"depviz/internal/dependency_provider/pip/test_utils"
+	"depviz/internal/models"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
-	"sort"
 	"testing"
 	"time"
 )
@@ -76,7 +76,7 @@ func TestDownloader_FetchPackageDeps(t *testing.T) {
 			BaseURL: srv.URL,
 			Client:  &http.Client{},
 		}
-		deps, err := d.FetchPackageDeps(ctx, "requests")
+		deps, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.PyPI, Name: "requests"})
 		assert.Empty(t, deps)
 		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
 	})
@@ -98,7 +98,7 @@ func TestDownloader_FetchPackageDeps(t *testing.T) {
 			BaseURL: srv.URL,
 			Client:  &http.Client{},
 		}
-		deps, err := d.FetchPackageDeps(ctx, "requests")
+		deps, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.PyPI, Name: "requests"})
 		assert.Empty(t, deps)
 		assert.ErrorIs(t, err, dep_errors.ErrFetch)
 	})
@@ -120,7 +120,7 @@ func TestDownloader_FetchPackageDeps(t *testing.T) {
 			BaseURL: srv.URL,
 			Client:  &http.Client{},
 		}
-		deps, err := d.FetchPackageDeps(ctx, "requests")
+		deps, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.PyPI, Name: "requests"})
 		assert.Empty(t, deps)
 		assert.ErrorIs(t, err, dep_errors.ErrFetch)
 	})
@@ -142,7 +142,7 @@ func TestDownloader_FetchPackageDeps(t *testing.T) {
 			BaseURL: srv.URL,
 			Client:  &http.Client{},
 		}
-		deps, err := d.FetchPackageDeps(ctx, "requests")
+		deps, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.PyPI, Name: "requests"})
 		assert.Empty(t, deps)
 		assert.ErrorIs(t, err, dep_errors.ErrFetch)
 	})
@@ -164,7 +164,7 @@ func TestDownloader_FetchPackageDeps(t *testing.T) {
 			BaseURL: srv.URL,
 			Client:  &http.Client{},
 		}
-		deps, err := d.FetchPackageDeps(ctx, "requests")
+		deps, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.PyPI, Name: "requests"})
 		assert.Empty(t, deps)
 		assert.NoError(t, err)
 	})
@@ -180,6 +180,14 @@ func TestDownloader_FetchPackageDeps(t *testing.T) {
 				w.WriteHeader(200)
 
 			})
+		mux.HandleFunc("/starlette/json",
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(test_utils.NewServerResponse())
+			})
+		mux.HandleFunc("/pydantic/json",
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(test_utils.NewServerResponse())
+			})
 		srv := httptest.NewServer(mux)
 		defer srv.Close()
 
@@ -187,12 +195,194 @@ func TestDownloader_FetchPackageDeps(t *testing.T) {
 			BaseURL: srv.URL,
 			Client:  &http.Client{},
 		}
-		deps, err := d.FetchPackageDeps(ctx, "fastapi")
-		sort.Strings(deps)
-		assert.Equal(t, []string{"pydantic", "starlette"}, deps)
+		deps, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.PyPI, Name: "fastapi"})
+		assert.Equal(t, []models.Dependency{
+			{Package: models.Package{Ecosystem: models.PyPI, Name: "starlette"}},
+			{Package: models.Package{Ecosystem: models.PyPI, Name: "pydantic"}, Constraint: ">=3"},
+		}, deps)
 		assert.NoError(t, err)
 	})
 
+	t.Run("test resolving versions of dependencies", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/fastapi/json",
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(test_utils.NewProjectResponse("0.100.0", []string{"0.99.0", "0.100.0"},
+					"starlette<0.28.0,>=0.27.0",
+					"Pydantic_Core (>=2)",
+					"extra-utils",
+					"email-validator[idna]>=2.0.0; extra == \"all\"",
+					"typing-extensions>=4.5.0; python_version < \"3.8\"",
+				))
+			})
+		mux.HandleFunc("/fastapi/0.99.0/json",
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(test_utils.NewServerResponse("starlette==0.27.0"))
+			})
+		mux.HandleFunc("/starlette/json",
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(test_utils.NewProjectResponse("0.31.0", []string{"0.26.0", "0.27.0", "0.27.1rc1", "0.31.0"}))
+			})
+		mux.HandleFunc("/Pydantic_Core/json",
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(test_utils.NewProjectResponse("2.1.0", []string{"1.0", "2.0.0", "2.1.0", "3.0.0a1"}))
+			})
+		mux.HandleFunc("/extra-utils/json",
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(test_utils.NewProjectResponse("1.0", []string{"1.0"}))
+			})
+		mux.HandleFunc("/email-validator/json",
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(test_utils.NewProjectResponse("2.1.0", []string{"2.1.0"}))
+			})
+		mux.HandleFunc("/typing-extensions/json",
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(test_utils.NewProjectResponse("4.8.0", []string{"4.8.0"}))
+			})
+		srv := httptest.NewServer(mux)
+		defer srv.Close()
+
+		d := DependencyProvider{
+			BaseURL: srv.URL,
+			Client:  &http.Client{},
+		}
+		fetch := func(spec string) ([]string, error) {
+			pkg, err := d.Resolve(ctx, spec)
+			if err != nil {
+				return nil, err
+			}
+			deps, err := d.FetchPackageDeps(ctx, pkg)
+			names := make([]string, 0, len(deps))
+			for _, dep := range deps {
+				names = append(names, dep.Package.String())
+			}
+			return names, err
+		}
+
+		pkg, err := d.Resolve(ctx, "fastapi")
+		assert.NoError(t, err)
+		assert.Equal(t, models.Package{Ecosystem: models.PyPI, Name: "fastapi", Version: "0.100.0"}, pkg)
+		deps, err := d.FetchPackageDeps(ctx, pkg)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{
+				Package:    models.Package{Ecosystem: models.PyPI, Name: "starlette", Version: "0.27.0"},
+				Constraint: "<0.28.0,>=0.27.0",
+			},
+			{
+				Package:    models.Package{Ecosystem: models.PyPI, Name: "pydantic-core", Version: "2.1.0"},
+				Constraint: ">=2",
+			},
+			{
+				Package: models.Package{Ecosystem: models.PyPI, Name: "extra-utils", Version: "1.0"},
+			},
+			{
+				Package:    models.Package{Ecosystem: models.PyPI, Name: "typing-extensions", Version: "4.8.0"},
+				Constraint: ">=4.5.0",
+			},
+		}, deps)
+
+		names, err := fetch("fastapi<0.100")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"starlette@0.27.0"}, names)
+
+		names, err = fetch("fastapi==0.99.0")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"starlette@0.27.0"}, names)
+
+		_, err = fetch("fastapi>=1")
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+
+		pkg, err = d.Resolve(ctx, "FastAPI[All]")
+		assert.NoError(t, err)
+		assert.Equal(t, "fastapi[all]@0.100.0", pkg.String())
+		deps, err = d.FetchPackageDeps(ctx, pkg)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, len(deps))
+		assert.Equal(t, models.Dependency{
+			Package:    models.Package{Ecosystem: models.PyPI, Name: "email-validator[idna]", Version: "2.1.0"},
+			Constraint: ">=2.0.0",
+			Optional:   true,
+		}, deps[3])
+
+		d.Environment = &Environment{PythonVersion: "3.11"}
+		names, err = fetch("fastapi")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"starlette@0.27.0",
+			"pydantic-core@2.1.0",
+			"extra-utils@1.0",
+		}, names)
+
+		_, err = fetch("fastapi>>1")
+		assert.ErrorIs(t, err, dep_errors.ErrInvalidPackageName)
+	})
+
+	t.Run("test pinning root release", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/django/json",
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{
+					"info": {"version": "4.2.0", "requires_dist": ["asgiref>=3.6.0"]},
+					"releases": {
+						"3.2.0": [{"yanked": false}],
+						"3.2.18": [{"yanked": false}],
+						"3.2.19": [{"yanked": true}, {"yanked": true}],
+						"4.2.0": [{"yanked": false}]
+					}
+				}`))
+			})
+		mux.HandleFunc("/django/3.2.18/json",
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(test_utils.NewServerResponse("asgiref<4,>=3.3.2", "pytz"))
+			})
+		mux.HandleFunc("/asgiref/json",
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(test_utils.NewProjectResponse("3.7.2", []string{"3.3.2", "3.7.2"}))
+			})
+		mux.HandleFunc("/pytz/json",
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(test_utils.NewProjectResponse("2023.3", []string{"2023.3"}))
+			})
+		srv := httptest.NewServer(mux)
+		defer srv.Close()
+
+		d := DependencyProvider{
+			BaseURL: srv.URL,
+			Client:  &http.Client{},
+		}
+		pkg, err := d.Resolve(ctx, "django==3.2.18")
+		assert.NoError(t, err)
+		assert.Equal(t, models.Package{Ecosystem: models.PyPI, Name: "django", Version: "3.2.18"}, pkg)
+		deps, err := d.FetchPackageDeps(ctx, pkg)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: models.Package{Ecosystem: models.PyPI, Name: "asgiref", Version: "3.7.2"}, Constraint: "<4,>=3.3.2"},
+			{Package: models.Package{Ecosystem: models.PyPI, Name: "pytz", Version: "2023.3"}},
+		}, deps)
+
+		pkg, err = d.Resolve(ctx, "django==3.2")
+		assert.NoError(t, err)
+		assert.Equal(t, "3.2.0", pkg.Version)
+
+		pkg, err = d.Resolve(ctx, "django==3.2.19")
+		assert.NoError(t, err)
+		assert.Equal(t, "3.2.19", pkg.Version)
+
+		pkg, err = d.Resolve(ctx, "django~=3.2.0")
+		assert.NoError(t, err)
+		assert.Equal(t, "3.2.18", pkg.Version)
+
+		_, err = d.Resolve(ctx, "django==3.1.0")
+		assert.ErrorIs(t, err, dep_errors.ErrPackageNotFound)
+	})
+
 	t.Run("test fetching if server is unreachable", func(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 		defer cancel()
@@ -202,7 +392,7 @@ func TestDownloader_FetchPackageDeps(t *testing.T) {
 			Client:  &http.Client{},
 		}
 
-		_, err := d.FetchPackageDeps(ctx, "valid-dependencies")
+		_, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.PyPI, Name: "valid-dependencies"})
 		assert.ErrorIs(t, err, dep_errors.ErrFetch)
 	})
 	t.Run("test if fetching url is invalid", func(t *testing.T) {
@@ -214,7 +404,7 @@ func TestDownloader_FetchPackageDeps(t *testing.T) {
 			Client:  &http.Client{},
 		}
 
-		_, err := d.FetchPackageDeps(ctx, "valid-dependencies")
+		_, err := d.FetchPackageDeps(ctx, models.Package{Ecosystem: models.PyPI, Name: "valid-dependencies"})
 		assert.ErrorIs(t, err, dep_errors.ErrFetch)
 	})
 }
@@ -225,3 +415,28 @@ func TestDefault(t *testing.T) {
 	assert.NotNil(t, d.Client)
 	assert.Equal(t, d.BaseURL, "https://pypi.python.org/pypi")
 }
+
+func Test_cleanPackageDeps(t *testing.T) {
+	deps := []string{
+		"idna>=2",
+		`PySocks!=1.5.7,>=1.5.6; extra == "socks"`,
+		`chardet<6,>=3.0.2; extra == "use-chardet-on-py3"`,
+		`win-inet-pton; sys_platform == "win32" and extra == "socks"`,
+		"extra-utils",
+		"broken=1.0",
+		"broken-marker; extra",
+	}
+	names := func(reqs []*Requirement) []string {
+		result := make([]string, 0, len(reqs))
+		for _, r := range reqs {
+			result = append(result, r.Name)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"idna", "extra-utils", "broken"}, names(cleanPackageDeps(deps, nil, nil)))
+	assert.Equal(t, []string{"idna", "PySocks", "win-inet-pton", "extra-utils", "broken"},
+		names(cleanPackageDeps(deps, nil, []string{"socks"})))
+	assert.Equal(t, []string{"idna", "PySocks", "chardet", "extra-utils", "broken"},
+		names(cleanPackageDeps(deps, &Environment{SysPlatform: "linux"}, []string{"socks", "use_chardet_on_py3"})))
+}
@@ -5,14 +5,33 @@ import (
 )
 
 type ServerResponse struct {
-	Info Info `json:"info"`
+	Info     Info                 `json:"info"`
+	Releases map[string][]Release `json:"releases,omitempty"`
 }
 type Info struct {
+	Version  string   `json:"version,omitempty"`
 	Requires []string `json:"requires_dist"`
 }
+type Release struct {
+	Yanked bool `json:"yanked"`
+}
 
 func NewServerResponse(deps ...string) []byte {
 	r := ServerResponse{Info: Info{Requires: deps}}
 	b, _ := json.Marshal(&r)
 	return b
 }
+
+// NewProjectResponse returns project metadata of the latest version with
+// one file uploaded for each of releases.
+func NewProjectResponse(version string, releases []string, deps ...string) []byte {
+	r := ServerResponse{
+		Info:     Info{Version: version, Requires: deps},
+		Releases: make(map[string][]Release, len(releases)),
+	}
+	for _, release := range releases {
+		r.Releases[release] = []Release{{}}
+	}
+	b, _ := json.Marshal(&r)
+	return b
+}
@@ -0,0 +1,92 @@
+package sbom
+
+import (
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/dependency_provider/lockfile"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+)
+
+type cdxComponent struct {
+	BOMRef     string         `json:"bom-ref" xml:"bom-ref,attr"`
+	Group      string         `json:"group" xml:"group"`
+	Name       string         `json:"name" xml:"name"`
+	Version    string         `json:"version" xml:"version"`
+	PURL       string         `json:"purl" xml:"purl"`
+	Scope      string         `json:"scope" xml:"scope"`
+	Components []cdxComponent `json:"components" xml:"components>component"`
+}
+
+// cdxDependency is an entry of the dependencies section. JSON lists the
+// references in dependsOn, XML nests dependency elements.
+type cdxDependency struct {
+	Ref       string   `json:"ref" xml:"ref,attr"`
+	DependsOn []string `json:"dependsOn" xml:"-"`
+	Nested    []struct {
+		Ref string `xml:"ref,attr"`
+	} `json:"-" xml:"dependency"`
+}
+
+type cdxBOM struct {
+	XMLName  xml.Name `json:"-"`
+	Metadata struct {
+		Component *cdxComponent `json:"component" xml:"component"`
+	} `json:"metadata" xml:"metadata"`
+	Components   []cdxComponent  `json:"components" xml:"components>component"`
+	Dependencies []cdxDependency `json:"dependencies" xml:"dependencies>dependency"`
+}
+
+func (b *builder) addComponents(components []cdxComponent) {
+	for _, c := range components {
+		name := c.Name
+		if c.Group != "" {
+			name = c.Group + "/" + c.Name
+		}
+		ref := b.add(c.BOMRef, c.PURL, name, c.Version)
+		if c.Scope == "optional" {
+			b.optional[ref] = true
+		}
+		b.addComponents(c.Components)
+	}
+}
+
+// graph turns the BOM into a graph, the component of the metadata is the root.
+func (bom *cdxBOM) graph(project string) *lockfile.Graph {
+	b := newBuilder()
+	if c := bom.Metadata.Component; c != nil {
+		b.addComponents([]cdxComponent{*c})
+		if c.BOMRef != "" {
+			b.roots = append(b.roots, c.BOMRef)
+		}
+	}
+	b.addComponents(bom.Components)
+	for _, dep := range bom.Dependencies {
+		for _, ref := range dep.DependsOn {
+			b.depend(dep.Ref, ref, false)
+		}
+		for _, nested := range dep.Nested {
+			b.depend(dep.Ref, nested.Ref, false)
+		}
+	}
+	return b.graph(project)
+}
+
+func parseCycloneDXJSON(data []byte, project string) (*lockfile.Graph, error) {
+	var bom cdxBOM
+	if err := json.Unmarshal(data, &bom); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidSBOM, err)
+	}
+	return bom.graph(project), nil
+}
+
+func parseCycloneDXXML(data []byte, project string) (*lockfile.Graph, error) {
+	var bom cdxBOM
+	if err := xml.Unmarshal(data, &bom); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidSBOM, err)
+	}
+	if bom.XMLName.Local != "bom" {
+		return nil, fmt.Errorf("%w: unexpected root element %s", dep_errors.ErrInvalidSBOM, bom.XMLName.Local)
+	}
+	return bom.graph(project), nil
+}
@@ -0,0 +1,142 @@
+package sbom
+
+import (
+	"bytes"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/dependency_provider/lockfile"
+	"depviz/internal/models"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadFile reads a CycloneDX (JSON or XML) or SPDX 2.x (JSON or tag-value)
+// SBOM, the format is detected by the content.
+func ReadFile(path string) (*lockfile.Graph, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read sbom: %w", err)
+	}
+	name := filepath.Base(path)
+	return Parse(data, strings.TrimSuffix(name, filepath.Ext(name)))
+}
+
+// Parse reads an SBOM. project names the root if the SBOM doesn't describe
+// a single component.
+func Parse(data []byte, project string) (*lockfile.Graph, error) {
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	switch {
+	case bytes.HasPrefix(data, []byte("{")):
+		var probe struct {
+			BOMFormat   string `json:"bomFormat"`
+			SPDXVersion string `json:"spdxVersion"`
+		}
+		if err := json.Unmarshal(data, &probe); err != nil {
+			return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidSBOM, err)
+		}
+		if probe.BOMFormat == "CycloneDX" {
+			return parseCycloneDXJSON(data, project)
+		}
+		if probe.SPDXVersion != "" {
+			return parseSPDXJSON(data, project)
+		}
+	case bytes.HasPrefix(data, []byte("<")):
+		return parseCycloneDXXML(data, project)
+	case bytes.Contains(data, []byte("SPDXVersion:")):
+		return parseSPDXTagValue(data, project)
+	}
+	return nil, fmt.Errorf("%w: neither CycloneDX nor SPDX", dep_errors.ErrInvalidSBOM)
+}
+
+type edge struct {
+	from, to string
+	optional bool
+}
+
+// builder collects components of an SBOM and the dependencies between them
+// by their references, which may be given in any order.
+type builder struct {
+	refs     []string
+	packages map[string]models.Package
+	optional map[string]bool
+	edges    []edge
+	// roots are the components the SBOM describes
+	roots []string
+}
+
+func newBuilder() *builder {
+	return &builder{packages: make(map[string]models.Package), optional: make(map[string]bool)}
+}
+
+// add registers a component and returns its reference. Its package is read
+// from the package url if there is a valid one, otherwise it has no ecosystem.
+func (b *builder) add(ref, purl, name, version string) string {
+	pkg, err := models.ParsePURL(purl)
+	if purl == "" || err != nil {
+		pkg = models.Package{Name: name, Version: version}
+	}
+	if ref == "" {
+		ref = pkg.String()
+	}
+	if _, ok := b.packages[ref]; !ok {
+		b.refs = append(b.refs, ref)
+	}
+	b.packages[ref] = pkg
+	return ref
+}
+
+func (b *builder) depend(from, to string, optional bool) {
+	if from != to {
+		b.edges = append(b.edges, edge{from: from, to: to, optional: optional})
+	}
+}
+
+// pkg returns the package of a reference. References to components missing
+// from the SBOM are drawn by their name.
+func (b *builder) pkg(ref string) models.Package {
+	if pkg, ok := b.packages[ref]; ok {
+		return pkg
+	}
+	return models.Package{Name: ref}
+}
+
+// graph builds the dependency graph. If the SBOM doesn't describe a single
+// component, the root is named after the project and points to the described
+// components, or to the components nothing depends on.
+func (b *builder) graph(project string) *lockfile.Graph {
+	if len(b.roots) == 1 {
+		g := lockfile.NewGraph(b.pkg(b.roots[0]))
+		b.addEdges(g)
+		return g
+	}
+
+	g := lockfile.NewGraph(models.Package{Name: project})
+	roots := b.roots
+	if len(roots) == 0 {
+		required := make(map[string]struct{}, len(b.edges))
+		for _, e := range b.edges {
+			required[e.to] = struct{}{}
+		}
+		for _, ref := range b.refs {
+			if _, ok := required[ref]; !ok {
+				roots = append(roots, ref)
+			}
+		}
+	}
+	for _, ref := range roots {
+		g.AddDependency(g.Root, models.Dependency{Package: b.pkg(ref), Optional: b.optional[ref]})
+	}
+	b.addEdges(g)
+	return g
+}
+
+func (b *builder) addEdges(g *lockfile.Graph) {
+	for _, ref := range b.refs {
+		g.AddPackage(b.packages[ref])
+	}
+	for _, e := range b.edges {
+		g.AddDependency(b.pkg(e.from), models.Dependency{Package: b.pkg(e.to), Optional: e.optional || b.optional[e.to]})
+	}
+}
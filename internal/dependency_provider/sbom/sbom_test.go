@@ -0,0 +1,177 @@
+package sbom
+
+import (
+	"context"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	app    = models.Package{Ecosystem: models.Npm, Name: "app", Version: "1.0.0"}
+	lodash = models.Package{Ecosystem: models.Npm, Name: "lodash", Version: "4.17.21"}
+	core   = models.Package{Ecosystem: models.Npm, Name: "@babel/core", Version: "7.23.5"}
+)
+
+func TestParse(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name string
+		data string
+	}{
+		{name: "CycloneDX JSON", data: `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "metadata": {
+    "component": {"bom-ref": "app", "type": "application", "name": "app", "version": "1.0.0", "purl": "pkg:npm/app@1.0.0"}
+  },
+  "components": [
+    {"bom-ref": "pkg:npm/lodash@4.17.21", "type": "library", "name": "lodash", "version": "4.17.21", "purl": "pkg:npm/lodash@4.17.21"},
+    {"bom-ref": "babel", "type": "library", "group": "@babel", "name": "core", "version": "7.23.5", "purl": "pkg:npm/%40babel/core@7.23.5", "scope": "optional"}
+  ],
+  "dependencies": [
+    {"ref": "app", "dependsOn": ["pkg:npm/lodash@4.17.21", "babel"]},
+    {"ref": "babel", "dependsOn": ["pkg:npm/lodash@4.17.21"]},
+    {"ref": "pkg:npm/lodash@4.17.21", "dependsOn": []}
+  ]
+}`},
+		{name: "CycloneDX XML", data: `<?xml version="1.0" encoding="UTF-8"?>
+<bom xmlns="http://cyclonedx.org/schema/bom/1.5" version="1">
+  <metadata>
+    <component type="application" bom-ref="app">
+      <name>app</name>
+      <version>1.0.0</version>
+      <purl>pkg:npm/app@1.0.0</purl>
+    </component>
+  </metadata>
+  <components>
+    <component type="library" bom-ref="pkg:npm/lodash@4.17.21">
+      <name>lodash</name>
+      <version>4.17.21</version>
+      <purl>pkg:npm/lodash@4.17.21</purl>
+    </component>
+    <component type="library" bom-ref="babel">
+      <group>@babel</group>
+      <name>core</name>
+      <version>7.23.5</version>
+      <scope>optional</scope>
+      <purl>pkg:npm/%40babel/core@7.23.5</purl>
+    </component>
+  </components>
+  <dependencies>
+    <dependency ref="app">
+      <dependency ref="pkg:npm/lodash@4.17.21"/>
+      <dependency ref="babel"/>
+    </dependency>
+    <dependency ref="babel">
+      <dependency ref="pkg:npm/lodash@4.17.21"/>
+    </dependency>
+  </dependencies>
+</bom>`},
+		{name: "SPDX JSON", data: `{
+  "spdxVersion": "SPDX-2.3",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "name": "app",
+  "packages": [
+    {"SPDXID": "SPDXRef-app", "name": "app", "versionInfo": "1.0.0",
+     "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:npm/app@1.0.0"}]},
+    {"SPDXID": "SPDXRef-lodash", "name": "lodash", "versionInfo": "4.17.21",
+     "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:npm/lodash@4.17.21"}]},
+    {"SPDXID": "SPDXRef-babel", "name": "@babel/core", "versionInfo": "7.23.5",
+     "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:npm/%40babel/core@7.23.5"}]}
+  ],
+  "relationships": [
+    {"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-app"},
+    {"spdxElementId": "SPDXRef-app", "relationshipType": "DEPENDS_ON", "relatedSpdxElement": "SPDXRef-lodash"},
+    {"spdxElementId": "SPDXRef-babel", "relationshipType": "OPTIONAL_DEPENDENCY_OF", "relatedSpdxElement": "SPDXRef-app"},
+    {"spdxElementId": "SPDXRef-lodash", "relationshipType": "DEPENDENCY_OF", "relatedSpdxElement": "SPDXRef-babel"},
+    {"spdxElementId": "SPDXRef-lodash", "relationshipType": "DEPENDS_ON", "relatedSpdxElement": "NONE"}
+  ]
+}`},
+		{name: "SPDX tag-value", data: `SPDXVersion: SPDX-2.3
+DataLicense: CC0-1.0
+SPDXID: SPDXRef-DOCUMENT
+DocumentName: app
+Creator: Tool: example
+Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-app
+
+## Packages
+PackageName: app
+SPDXID: SPDXRef-app
+PackageVersion: 1.0.0
+PackageComment: <text>The application,
+SPDXID: not an identifier</text>
+ExternalRef: PACKAGE-MANAGER purl pkg:npm/app@1.0.0
+
+PackageName: lodash
+SPDXID: SPDXRef-lodash
+PackageVersion: 4.17.21
+ExternalRef: PACKAGE-MANAGER purl pkg:npm/lodash@4.17.21
+
+PackageName: @babel/core
+SPDXID: SPDXRef-babel
+PackageVersion: 7.23.5
+ExternalRef: PACKAGE-MANAGER purl pkg:npm/%40babel/core@7.23.5
+
+Relationship: SPDXRef-app DEPENDS_ON SPDXRef-lodash
+Relationship: SPDXRef-babel OPTIONAL_DEPENDENCY_OF SPDXRef-app
+Relationship: SPDXRef-lodash DEV_DEPENDENCY_OF SPDXRef-babel
+`},
+	} {
+		t.Run("test reads "+tc.name, func(t *testing.T) {
+			g, err := Parse([]byte(tc.data), "sbom")
+			assert.NoError(t, err)
+			assert.Equal(t, app, g.Root)
+
+			deps, err := g.FetchPackageDeps(ctx, g.Root)
+			assert.NoError(t, err)
+			assert.Equal(t, []models.Dependency{
+				{Package: lodash},
+				{Package: core, Optional: true},
+			}, deps)
+
+			deps, err = g.FetchPackageDeps(ctx, core)
+			assert.NoError(t, err)
+			assert.Equal(t, []models.Dependency{{Package: lodash}}, deps)
+		})
+	}
+
+	t.Run("test links components nothing depends on to the project", func(t *testing.T) {
+		g, err := Parse([]byte(`{
+  "bomFormat": "CycloneDX",
+  "components": [
+    {"name": "left-pad", "version": "1.3.0"},
+    {"bom-ref": "a", "name": "a", "version": "1.0.0"}
+  ],
+  "dependencies": [{"ref": "a", "dependsOn": ["missing"]}]
+}`), "sbom")
+		assert.NoError(t, err)
+		assert.Equal(t, models.Package{Name: "sbom"}, g.Root)
+
+		deps, err := g.FetchPackageDeps(ctx, g.Root)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: models.Package{Name: "left-pad", Version: "1.3.0"}},
+			{Package: models.Package{Name: "a", Version: "1.0.0"}},
+		}, deps)
+
+		deps, err = g.FetchPackageDeps(ctx, models.Package{Name: "a", Version: "1.0.0"})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{{Package: models.Package{Name: "missing"}}}, deps)
+	})
+
+	t.Run("test returns error for invalid sbom", func(t *testing.T) {
+		for _, data := range []string{
+			`{"name": "package.json"}`,
+			`{"spdxVersion": "SPDX-3.0"}`,
+			`<project><name>app</name></project>`,
+			"SPDXVersion: SPDX-2.3\nRelationship: SPDXRef-a DEPENDS_ON\n",
+			"lodash==4.17.21",
+		} {
+			_, err := Parse([]byte(data), "sbom")
+			assert.ErrorIs(t, err, dep_errors.ErrInvalidSBOM)
+		}
+	})
+}
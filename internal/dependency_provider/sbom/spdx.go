@@ -0,0 +1,182 @@
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"depviz/internal/dependency_provider/dep_errors"
+	"depviz/internal/dependency_provider/lockfile"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+// relate applies a relationship of the document doc. Dependencies are given
+// as "A DEPENDS_ON B" or the other way around as "B DEPENDENCY_OF A", the
+// latter has variants for development, build, runtime and test dependencies.
+func (b *builder) relate(doc string, r spdxRelationship) {
+	if r.Related == "NONE" || r.Related == "NOASSERTION" {
+		return
+	}
+	switch r.Type {
+	case "DESCRIBES":
+		if r.Element == doc {
+			b.roots = append(b.roots, r.Related)
+		}
+	case "DESCRIBED_BY":
+		if r.Related == doc {
+			b.roots = append(b.roots, r.Element)
+		}
+	case "DEPENDS_ON":
+		b.depend(r.Element, r.Related, false)
+	case "DEPENDENCY_OF", "DEV_DEPENDENCY_OF", "BUILD_DEPENDENCY_OF", "RUNTIME_DEPENDENCY_OF", "TEST_DEPENDENCY_OF":
+		b.depend(r.Related, r.Element, false)
+	case "OPTIONAL_DEPENDENCY_OF":
+		b.depend(r.Related, r.Element, true)
+	}
+}
+
+func checkSPDXVersion(version string) error {
+	if !strings.HasPrefix(version, "SPDX-2.") {
+		return fmt.Errorf("%w: unsupported SPDX version %q", dep_errors.ErrInvalidSBOM, version)
+	}
+	return nil
+}
+
+func parseSPDXJSON(data []byte, project string) (*lockfile.Graph, error) {
+	var doc struct {
+		SPDXVersion       string   `json:"spdxVersion"`
+		SPDXID            string   `json:"SPDXID"`
+		Name              string   `json:"name"`
+		DocumentDescribes []string `json:"documentDescribes"`
+		Packages          []struct {
+			SPDXID       string `json:"SPDXID"`
+			Name         string `json:"name"`
+			VersionInfo  string `json:"versionInfo"`
+			ExternalRefs []struct {
+				ReferenceType    string `json:"referenceType"`
+				ReferenceLocator string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+		Relationships []spdxRelationship `json:"relationships"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidSBOM, err)
+	}
+	if err := checkSPDXVersion(doc.SPDXVersion); err != nil {
+		return nil, err
+	}
+
+	b := newBuilder()
+	for _, p := range doc.Packages {
+		purl := ""
+		for _, ref := range p.ExternalRefs {
+			if ref.ReferenceType == "purl" {
+				purl = ref.ReferenceLocator
+			}
+		}
+		b.add(p.SPDXID, purl, p.Name, p.VersionInfo)
+	}
+	b.roots = append(b.roots, doc.DocumentDescribes...)
+	for _, r := range doc.Relationships {
+		b.relate(doc.SPDXID, r)
+	}
+	if doc.Name != "" {
+		project = doc.Name
+	}
+	return b.graph(project), nil
+}
+
+// parseSPDXTagValue reads the tag-value format: "Tag: value" lines, where
+// SPDXID, PackageVersion and ExternalRef belong to the preceding PackageName.
+func parseSPDXTagValue(data []byte, project string) (*lockfile.Graph, error) {
+	type spdxPackage struct {
+		id, name, version, purl string
+	}
+	var packages []*spdxPackage
+	var current *spdxPackage
+	var relationships []spdxRelationship
+	version, doc, name := "", "", ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	inText := false
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		// <text> values may span several lines
+		if inText {
+			inText = !strings.Contains(line, "</text>")
+			continue
+		}
+		tag, value, ok := strings.Cut(line, ":")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: line %d: expected a tag", dep_errors.ErrInvalidSBOM, lineNo)
+		}
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, "<text>") && !strings.Contains(value, "</text>") {
+			inText = true
+			continue
+		}
+
+		switch strings.TrimSpace(tag) {
+		case "SPDXVersion":
+			version = value
+		case "DocumentName":
+			name = value
+		case "PackageName":
+			current = &spdxPackage{name: value}
+			packages = append(packages, current)
+		case "FileName", "SnippetSPDXID":
+			// identifiers of files and snippets aren't packages
+			current = nil
+		case "SPDXID":
+			if current != nil {
+				current.id = value
+			} else if doc == "" {
+				doc = value
+			}
+		case "PackageVersion":
+			if current != nil {
+				current.version = value
+			}
+		case "ExternalRef":
+			// ExternalRef: PACKAGE-MANAGER purl pkg:npm/lodash@4.17.21
+			if fields := strings.Fields(value); current != nil && len(fields) == 3 && fields[1] == "purl" {
+				current.purl = fields[2]
+			}
+		case "Relationship":
+			fields := strings.Fields(value)
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("%w: line %d: invalid relationship %q", dep_errors.ErrInvalidSBOM, lineNo, value)
+			}
+			relationships = append(relationships, spdxRelationship{Element: fields[0], Type: fields[1], Related: fields[2]})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", dep_errors.ErrInvalidSBOM, err)
+	}
+	if err := checkSPDXVersion(version); err != nil {
+		return nil, err
+	}
+
+	b := newBuilder()
+	for _, p := range packages {
+		b.add(p.id, p.purl, p.name, p.version)
+	}
+	for _, r := range relationships {
+		b.relate(doc, r)
+	}
+	if name != "" {
+		project = name
+	}
+	return b.graph(project), nil
+}
@@ -1,6 +1,46 @@
 package models
 
+// Ecosystems of packages, named after package url types.
+const (
+	Npm      = "npm"
+	PyPI     = "pypi"
+	Cargo    = "cargo"
+	Golang   = "golang"
+	Maven    = "maven"
+	Gem      = "gem"
+	NuGet    = "nuget"
+	Composer = "composer"
+)
+
+// Package identifies a node of the dependency graph. Version is empty if
+// the provider could not resolve the requirement to a published release.
+type Package struct {
+	Ecosystem string
+	Name      string
+	Version   string
+}
+
+func (p Package) String() string {
+	if p.Version == "" {
+		return p.Name
+	}
+	return p.Name + "@" + p.Version
+}
+
+// Dependency is a requirement of a package resolved to another package.
+type Dependency struct {
+	Package Package
+	// Constraint is the version requirement as written by the dependent package.
+	Constraint string
+	// Kind is the provider specific dependency kind, e.g. dev or build.
+	Kind     string
+	Optional bool
+}
+
 type Edge struct {
-	From string
-	To   string
+	From       Package
+	To         Package
+	Constraint string
+	Kind       string
+	Optional   bool
 }
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ParsePURL parses a package url, e.g. "pkg:npm/%40babel/core@7.23.5". The
+// type of the url is the ecosystem. Names follow the conventions of the
+// providers: npm scopes and Go module paths keep their slashes, maven group
+// and artifact are joined with ":".
+func ParsePURL(purl string) (Package, error) {
+	rest := strings.TrimPrefix(purl, "pkg:")
+	if rest == purl {
+		return Package{}, fmt.Errorf("invalid package url %q: scheme is not pkg", purl)
+	}
+	rest = strings.TrimLeft(rest, "/")
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, _, _ = strings.Cut(rest, "?")
+
+	ecosystem, rest, ok := strings.Cut(rest, "/")
+	if !ok || ecosystem == "" {
+		return Package{}, fmt.Errorf("invalid package url %q: type is missing", purl)
+	}
+	ecosystem = strings.ToLower(ecosystem)
+
+	version := ""
+	if i := strings.LastIndex(rest, "@"); i > strings.LastIndex(rest, "/") && i > 0 {
+		rest, version = rest[:i], rest[i+1:]
+	}
+	segments := strings.Split(strings.Trim(rest, "/"), "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return Package{}, fmt.Errorf("invalid package url %q: %w", purl, err)
+		}
+		segments[i] = unescaped
+	}
+	version, err := url.PathUnescape(version)
+	if err != nil {
+		return Package{}, fmt.Errorf("invalid package url %q: %w", purl, err)
+	}
+
+	name := segments[len(segments)-1]
+	namespace := strings.Join(segments[:len(segments)-1], "/")
+	if name == "" {
+		return Package{}, fmt.Errorf("invalid package url %q: name is missing", purl)
+	}
+	switch {
+	case ecosystem == Maven && namespace != "":
+		name = namespace + ":" + name
+	case ecosystem == PyPI:
+		// python names are case insensitive and "_" is the same as "-"
+		name = strings.ReplaceAll(strings.ToLower(name), "_", "-")
+	case namespace != "":
+		name = namespace + "/" + name
+	}
+	return Package{Ecosystem: ecosystem, Name: name, Version: version}, nil
+}
+
+// WithoutExtras returns a python package without its extras, e.g. requests
+// for requests[socks]. Extras select optional dependencies of the same
+// distribution. Packages of other ecosystems are returned as they are.
+func (p Package) WithoutExtras() Package {
+	if p.Ecosystem == PyPI {
+		p.Name, _, _ = strings.Cut(p.Name, "[")
+	}
+	return p
+}
+
+// PURL returns the package url of the package, packages without an ecosystem
+// get the generic type. Extras of python packages are left out.
+func (p Package) PURL() string {
+	p = p.WithoutExtras()
+	ecosystem := p.Ecosystem
+	if ecosystem == "" {
+		ecosystem = "generic"
+	}
+	name := p.Name
+	if ecosystem == Maven {
+		name = strings.Replace(name, ":", "/", 1)
+	}
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = escapePURL(segment)
+	}
+	result := "pkg:" + ecosystem + "/" + strings.Join(segments, "/")
+	if p.Version != "" {
+		result += "@" + escapePURL(p.Version)
+	}
+	return result
+}
+
+func escapePURL(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "@", "%40")
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParsePURL(t *testing.T) {
+	for purl, expected := range map[string]Package{
+		"pkg:npm/%40babel/core@7.23.5":                         {Ecosystem: Npm, Name: "@babel/core", Version: "7.23.5"},
+		"pkg:npm/lodash":                                       {Ecosystem: Npm, Name: "lodash"},
+		"pkg:pypi/Django_Rest@3.14.0?file_name=x.whl":          {Ecosystem: PyPI, Name: "django-rest", Version: "3.14.0"},
+		"pkg:maven/org.slf4j/slf4j-api@2.0.9?type=jar":         {Ecosystem: Maven, Name: "org.slf4j:slf4j-api", Version: "2.0.9"},
+		"pkg:golang/github.com/stretchr/testify@v1.8.4#assert": {Ecosystem: Golang, Name: "github.com/stretchr/testify", Version: "v1.8.4"},
+		"pkg:GEM/rails@7.1.2":                                  {Ecosystem: Gem, Name: "rails", Version: "7.1.2"},
+	} {
+		pkg, err := ParsePURL(purl)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, pkg, purl)
+	}
+
+	for _, purl := range []string{"npm/lodash", "pkg:lodash", "pkg:npm/", "pkg:npm/a%zz"} {
+		_, err := ParsePURL(purl)
+		assert.Error(t, err, purl)
+	}
+}
+
+func TestPackage_PURL(t *testing.T) {
+	for _, pkg := range []Package{
+		{Ecosystem: Npm, Name: "@babel/core", Version: "7.23.5"},
+		{Ecosystem: Maven, Name: "org.slf4j:slf4j-api", Version: "2.0.9"},
+		{Ecosystem: Golang, Name: "github.com/stretchr/testify", Version: "v1.8.4"},
+		{Ecosystem: Composer, Name: "symfony/console"},
+	} {
+		parsed, err := ParsePURL(pkg.PURL())
+		assert.NoError(t, err)
+		assert.Equal(t, pkg, parsed)
+	}
+	assert.Equal(t, "pkg:npm/%40babel/core@7.23.5", Package{Ecosystem: Npm, Name: "@babel/core", Version: "7.23.5"}.PURL())
+	assert.Equal(t, "pkg:generic/my%20tool@1.0", Package{Name: "my tool", Version: "1.0"}.PURL())
+	assert.Equal(t, "pkg:pypi/requests@2.31.0", Package{Ecosystem: PyPI, Name: "requests[security,socks]", Version: "2.31.0"}.PURL())
+}
@@ -0,0 +1,107 @@
+package cyclonedx
+
+import (
+	"depviz/internal/models"
+	"depviz/internal/serializer"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// CycloneDXSerializer writes the graph as a CycloneDX 1.5 JSON BOM. The root
+// package is the component of the metadata, package urls are the references.
+type CycloneDXSerializer struct {
+}
+
+type component struct {
+	Type    string `json:"type"`
+	BOMRef  string `json:"bom-ref,omitempty"`
+	Group   string `json:"group,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Scope   string `json:"scope,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+}
+
+type dependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+type bom struct {
+	BOMFormat   string `json:"bomFormat"`
+	SpecVersion string `json:"specVersion"`
+	Version     int    `json:"version"`
+	Metadata    struct {
+		Tools struct {
+			Components []component `json:"components"`
+		} `json:"tools"`
+		Component *component `json:"component,omitempty"`
+	} `json:"metadata"`
+	Components   []component  `json:"components"`
+	Dependencies []dependency `json:"dependencies"`
+}
+
+// newComponent splits npm scopes and maven groups into the group of the
+// component, extras of python packages are left out.
+func newComponent(pkg models.Package, componentType string) component {
+	pkg = pkg.WithoutExtras()
+	c := component{Type: componentType, BOMRef: pkg.PURL(), Name: pkg.Name, Version: pkg.Version, PURL: pkg.PURL()}
+	switch {
+	case pkg.Ecosystem == models.Maven && strings.Contains(pkg.Name, ":"):
+		c.Group, c.Name, _ = strings.Cut(pkg.Name, ":")
+	case pkg.Ecosystem == models.Npm && strings.HasPrefix(pkg.Name, "@") && strings.Contains(pkg.Name, "/"):
+		c.Group, c.Name, _ = strings.Cut(pkg.Name, "/")
+	}
+	return c
+}
+
+func (s *CycloneDXSerializer) Serialize(root models.Package, graph []models.Edge, out io.Writer) error {
+	result := bom{BOMFormat: "CycloneDX", SpecVersion: "1.5", Version: 1}
+	result.Metadata.Tools.Components = []component{{Type: "application", Name: "depviz"}}
+	result.Components = []component{}
+	result.Dependencies = []dependency{}
+
+	// Components are keyed by package url, python packages with extras are the
+	// same component. A component is optional if it is only required optionally.
+	required := make(map[string]bool)
+	dependsOn := make(map[string][]string)
+	seen := make(map[[2]string]struct{})
+	for _, edge := range graph {
+		from, to := edge.From.PURL(), edge.To.PURL()
+		required[to] = required[to] || !edge.Optional
+		key := [2]string{from, to}
+		if _, ok := seen[key]; !ok && from != to {
+			seen[key] = struct{}{}
+			dependsOn[from] = append(dependsOn[from], to)
+		}
+	}
+
+	added := make(map[string]struct{})
+	for i, pkg := range serializer.Nodes(root, graph) {
+		ref := pkg.PURL()
+		if _, ok := added[ref]; ok {
+			continue
+		}
+		added[ref] = struct{}{}
+		if i == 0 {
+			c := newComponent(pkg, "application")
+			result.Metadata.Component = &c
+		} else {
+			c := newComponent(pkg, "library")
+			if !required[ref] {
+				c.Scope = "optional"
+			}
+			result.Components = append(result.Components, c)
+		}
+		deps := dependsOn[ref]
+		if deps == nil {
+			deps = []string{}
+		}
+		result.Dependencies = append(result.Dependencies, dependency{Ref: ref, DependsOn: deps})
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
@@ -0,0 +1,138 @@
+package cyclonedx
+
+import (
+	"bytes"
+	"context"
+	"depviz/internal/dependency_provider/sbom"
+	"depviz/internal/models"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_CycloneDXSerializer_Serialize(t *testing.T) {
+	app := models.Package{Ecosystem: models.Npm, Name: "app", Version: "1.0.0"}
+	core := models.Package{Ecosystem: models.Npm, Name: "@babel/core", Version: "7.23.5"}
+	lodash := models.Package{Ecosystem: models.Npm, Name: "lodash", Version: "4.17.21"}
+	edges := []models.Edge{
+		{From: app, To: core, Optional: true},
+		{From: app, To: lodash, Constraint: "^4.17.0", Kind: "prod"},
+		{From: core, To: lodash, Kind: "prod"},
+		{From: core, To: lodash, Kind: "peer"},
+	}
+
+	t.Run("test CycloneDXSerializer", func(t *testing.T) {
+		expected := `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "version": 1,
+  "metadata": {
+    "tools": {
+      "components": [
+        {
+          "type": "application",
+          "name": "depviz"
+        }
+      ]
+    },
+    "component": {
+      "type": "application",
+      "bom-ref": "pkg:npm/app@1.0.0",
+      "name": "app",
+      "version": "1.0.0",
+      "purl": "pkg:npm/app@1.0.0"
+    }
+  },
+  "components": [
+    {
+      "type": "library",
+      "bom-ref": "pkg:npm/%40babel/core@7.23.5",
+      "group": "@babel",
+      "name": "core",
+      "version": "7.23.5",
+      "scope": "optional",
+      "purl": "pkg:npm/%40babel/core@7.23.5"
+    },
+    {
+      "type": "library",
+      "bom-ref": "pkg:npm/lodash@4.17.21",
+      "name": "lodash",
+      "version": "4.17.21",
+      "purl": "pkg:npm/lodash@4.17.21"
+    }
+  ],
+  "dependencies": [
+    {
+      "ref": "pkg:npm/app@1.0.0",
+      "dependsOn": [
+        "pkg:npm/%40babel/core@7.23.5",
+        "pkg:npm/lodash@4.17.21"
+      ]
+    },
+    {
+      "ref": "pkg:npm/%40babel/core@7.23.5",
+      "dependsOn": [
+        "pkg:npm/lodash@4.17.21"
+      ]
+    },
+    {
+      "ref": "pkg:npm/lodash@4.17.21",
+      "dependsOn": []
+    }
+  ]
+}
+`
+		s := CycloneDXSerializer{}
+		var buf bytes.Buffer
+		err := s.Serialize(app, edges, &buf)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("test BOM can be read back", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, (&CycloneDXSerializer{}).Serialize(app, edges, &buf))
+		g, err := sbom.Parse(buf.Bytes(), "bom")
+		assert.NoError(t, err)
+		assert.Equal(t, app, g.Root)
+
+		deps, err := g.FetchPackageDeps(context.Background(), core)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{{Package: lodash}}, deps)
+	})
+
+	t.Run("test python extras are the same component", func(t *testing.T) {
+		app := models.Package{Ecosystem: models.PyPI, Name: "app", Version: "1.0.0"}
+		requests := models.Package{Ecosystem: models.PyPI, Name: "requests", Version: "2.31.0"}
+		socks := models.Package{Ecosystem: models.PyPI, Name: "requests[socks]", Version: "2.31.0"}
+		pysocks := models.Package{Ecosystem: models.PyPI, Name: "pysocks", Version: "1.7.1"}
+
+		var buf bytes.Buffer
+		assert.NoError(t, (&CycloneDXSerializer{}).Serialize(app, []models.Edge{
+			{From: app, To: socks},
+			{From: app, To: requests},
+			{From: socks, To: pysocks, Optional: true},
+		}, &buf))
+		var result bom
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		assert.Equal(t, []component{
+			{Type: "library", BOMRef: "pkg:pypi/requests@2.31.0", Name: "requests", Version: "2.31.0", PURL: "pkg:pypi/requests@2.31.0"},
+			{Type: "library", BOMRef: "pkg:pypi/pysocks@1.7.1", Name: "pysocks", Version: "1.7.1", Scope: "optional", PURL: "pkg:pypi/pysocks@1.7.1"},
+		}, result.Components)
+		assert.Equal(t, []dependency{
+			{Ref: "pkg:pypi/app@1.0.0", DependsOn: []string{"pkg:pypi/requests@2.31.0"}},
+			{Ref: "pkg:pypi/requests@2.31.0", DependsOn: []string{"pkg:pypi/pysocks@1.7.1"}},
+			{Ref: "pkg:pypi/pysocks@1.7.1", DependsOn: []string{}},
+		}, result.Dependencies)
+	})
+
+	t.Run("test root without dependencies", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, (&CycloneDXSerializer{}).Serialize(app, nil, &buf))
+		var result bom
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		assert.Equal(t, &component{Type: "application", BOMRef: "pkg:npm/app@1.0.0", Name: "app", Version: "1.0.0", PURL: "pkg:npm/app@1.0.0"}, result.Metadata.Component)
+		assert.Empty(t, result.Components)
+		assert.Equal(t, []dependency{{Ref: "pkg:npm/app@1.0.0", DependsOn: []string{}}}, result.Dependencies)
+	})
+}
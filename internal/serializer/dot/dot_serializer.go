@@ -10,7 +10,7 @@ import (
 type DotSerializer struct {
 }
 
-type labelsMap map[string]int
+type labelsMap map[models.Package]int
 
 type pair struct {
 	label string
@@ -20,7 +20,7 @@ type pair struct {
 func (m labelsMap) AsSortedPairs() []pair {
 	result := make([]pair, 0, len(m))
 	for label, value := range m {
-		result = append(result, pair{label, value})
+		result = append(result, pair{label.String(), value})
 	}
 	sort.SliceStable(result, func(i, j int) bool {
 		return result[i].label < result[j].label
@@ -31,9 +31,9 @@ func (m labelsMap) AsSortedPairs() []pair {
 	return result
 }
 
-func (s *DotSerializer) Serialize(graph []models.Edge, out io.Writer) error {
-	idx := 0
-	labels := make(labelsMap)
+func (s *DotSerializer) Serialize(root models.Package, graph []models.Edge, out io.Writer) error {
+	idx := 1
+	labels := labelsMap{root: idx}
 
 	for _, edge := range graph {
 		if _, ok := labels[edge.From]; !ok {
@@ -60,7 +60,11 @@ func (s *DotSerializer) Serialize(graph []models.Edge, out io.Writer) error {
 	for _, edge := range graph {
 		from := labels[edge.From]
 		to := labels[edge.To]
-		if _, err := fmt.Fprintf(out, "\t%d -> %d;\n", from, to); err != nil {
+		attributes := ""
+		if edge.Optional {
+			attributes = " [style=dashed]"
+		}
+		if _, err := fmt.Fprintf(out, "\t%d -> %d%s;\n", from, to, attributes); err != nil {
 			return nil
 		}
 	}
@@ -9,30 +9,54 @@ import (
 
 func Test_DotSerializer_Serialize(t *testing.T) {
 	t.Run("test DotSerializer", func(t *testing.T) {
+		x := models.Package{Name: "x", Version: "1.0.0"}
+		y := models.Package{Name: "y"}
+		z := models.Package{Name: "z", Version: "2.0.0"}
 		edges := []models.Edge{
-			{"x", "y"},
-			{"x", "z"},
-			{"y", "z"},
+			{From: x, To: y},
+			{From: x, To: z, Optional: true},
+			{From: y, To: z},
 		}
 		expected := `digraph dependencies {
-	1 [label="x"];
+	1 [label="x@1.0.0"];
 	2 [label="y"];
-	3 [label="z"];
+	3 [label="z@2.0.0"];
 	1 -> 2;
-	1 -> 3;
+	1 -> 3 [style=dashed];
 	2 -> 3;
 }`
 		s := DotSerializer{}
 		var buf bytes.Buffer
-		err := s.Serialize(edges, &buf)
+		err := s.Serialize(x, edges, &buf)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, buf.String())
+	})
+	t.Run("test versions of a package are separate nodes", func(t *testing.T) {
+		edges := []models.Edge{
+			{From: models.Package{Name: "app"}, To: models.Package{Name: "lodash", Version: "4.17.21"}},
+			{From: models.Package{Name: "app"}, To: models.Package{Name: "legacy", Version: "1.0.0"}},
+			{From: models.Package{Name: "legacy", Version: "1.0.0"}, To: models.Package{Name: "lodash", Version: "3.10.1"}},
+		}
+		expected := `digraph dependencies {
+	1 [label="app"];
+	2 [label="lodash@4.17.21"];
+	3 [label="legacy@1.0.0"];
+	4 [label="lodash@3.10.1"];
+	1 -> 2;
+	1 -> 3;
+	3 -> 4;
+}`
+		s := DotSerializer{}
+		var buf bytes.Buffer
+		err := s.Serialize(models.Package{Name: "app"}, edges, &buf)
 		assert.NoError(t, err)
 		assert.Equal(t, expected, buf.String())
 	})
-	t.Run("test serialization of empty graph", func(t *testing.T) {
-		expected := "digraph dependencies {\n}"
+	t.Run("test root without dependencies", func(t *testing.T) {
+		expected := "digraph dependencies {\n\t1 [label=\"app@1.0.0\"];\n}"
 		s := DotSerializer{}
 		var buf bytes.Buffer
-		err := s.Serialize(nil, &buf)
+		err := s.Serialize(models.Package{Name: "app", Version: "1.0.0"}, nil, &buf)
 		assert.NoError(t, err)
 		assert.Equal(t, expected, buf.String())
 	})
@@ -0,0 +1,134 @@
+package gexf
+
+import (
+	"depviz/internal/models"
+	"depviz/internal/serializer"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// GEXFSerializer writes the graph as a GEXF 1.3 document, the native format
+// of Gephi. Packages and dependencies carry their attributes as typed keys.
+type GEXFSerializer struct {
+}
+
+type attribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type attributes struct {
+	Class      string      `xml:"class,attr"`
+	Attributes []attribute `xml:"attribute"`
+}
+
+// nodeAttributes and edgeAttributes declare the attributes.
+var (
+	nodeAttributes = attributes{Class: "node", Attributes: []attribute{
+		{ID: "name", Title: "name", Type: "string"},
+		{ID: "version", Title: "version", Type: "string"},
+		{ID: "ecosystem", Title: "ecosystem", Type: "string"},
+		{ID: "depth", Title: "depth", Type: "integer"},
+	}}
+	edgeAttributes = attributes{Class: "edge", Attributes: []attribute{
+		{ID: "kind", Title: "kind", Type: "string"},
+		{ID: "constraint", Title: "constraint", Type: "string"},
+		{ID: "optional", Title: "optional", Type: "boolean"},
+	}}
+)
+
+type attvalue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type node struct {
+	ID        string     `xml:"id,attr"`
+	Label     string     `xml:"label,attr"`
+	AttValues []attvalue `xml:"attvalues>attvalue"`
+}
+
+type edge struct {
+	ID        string     `xml:"id,attr"`
+	Source    string     `xml:"source,attr"`
+	Target    string     `xml:"target,attr"`
+	AttValues []attvalue `xml:"attvalues>attvalue,omitempty"`
+}
+
+type document struct {
+	XMLName        xml.Name `xml:"gexf"`
+	XMLNS          string   `xml:"xmlns,attr"`
+	XSI            string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Version        string   `xml:"version,attr"`
+	Creator        string   `xml:"meta>creator"`
+	Graph          struct {
+		DefaultEdgeType string       `xml:"defaultedgetype,attr"`
+		Mode            string       `xml:"mode,attr"`
+		Attributes      []attributes `xml:"attributes"`
+		Nodes           []node       `xml:"nodes>node"`
+		Edges           []edge       `xml:"edges>edge"`
+	} `xml:"graph"`
+}
+
+// values skips empty values, tools treat missing values as the default.
+func values(pairs ...string) []attvalue {
+	var result []attvalue
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			result = append(result, attvalue{For: pairs[i], Value: pairs[i+1]})
+		}
+	}
+	return result
+}
+
+func (s *GEXFSerializer) Serialize(root models.Package, graph []models.Edge, out io.Writer) error {
+	doc := document{
+		XMLNS:          "http://gexf.net/1.3",
+		XSI:            "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: "http://gexf.net/1.3 http://gexf.net/1.3/gexf.xsd",
+		Version:        "1.3",
+		Creator:        "depviz",
+	}
+	doc.Graph.DefaultEdgeType = "directed"
+	doc.Graph.Mode = "static"
+	doc.Graph.Attributes = []attributes{nodeAttributes, edgeAttributes}
+
+	depths := serializer.Depths(root, graph)
+	ids := make(map[models.Package]string)
+	for i, pkg := range serializer.Nodes(root, graph) {
+		ids[pkg] = fmt.Sprintf("n%d", i+1)
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node{ID: ids[pkg], Label: pkg.String(), AttValues: values(
+			"name", pkg.Name,
+			"version", pkg.Version,
+			"ecosystem", pkg.Ecosystem,
+			"depth", strconv.Itoa(depths[pkg]),
+		)})
+	}
+	for i, e := range graph {
+		doc.Graph.Edges = append(doc.Graph.Edges, edge{
+			ID:     fmt.Sprintf("e%d", i+1),
+			Source: ids[e.From],
+			Target: ids[e.To],
+			AttValues: values(
+				"kind", e.Kind,
+				"constraint", e.Constraint,
+				"optional", strconv.FormatBool(e.Optional),
+			),
+		})
+	}
+
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(out, "\n")
+	return err
+}
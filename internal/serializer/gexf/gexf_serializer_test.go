@@ -0,0 +1,89 @@
+package gexf
+
+import (
+	"bytes"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_GEXFSerializer_Serialize(t *testing.T) {
+	app := models.Package{Name: "app", Version: "1.0.0"}
+	core := models.Package{Ecosystem: models.Npm, Name: "@babel/core", Version: "7.23.5"}
+	lodash := models.Package{Ecosystem: models.Npm, Name: "lodash"}
+	edges := []models.Edge{
+		{From: app, To: core, Constraint: "^7.0.0", Kind: "prod"},
+		{From: app, To: lodash, Optional: true},
+		{From: core, To: lodash, Kind: "peer"},
+	}
+
+	t.Run("test GEXFSerializer", func(t *testing.T) {
+		expected := `<?xml version="1.0" encoding="UTF-8"?>
+<gexf xmlns="http://gexf.net/1.3" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://gexf.net/1.3 http://gexf.net/1.3/gexf.xsd" version="1.3">
+  <meta>
+    <creator>depviz</creator>
+  </meta>
+  <graph defaultedgetype="directed" mode="static">
+    <attributes class="node">
+      <attribute id="name" title="name" type="string"></attribute>
+      <attribute id="version" title="version" type="string"></attribute>
+      <attribute id="ecosystem" title="ecosystem" type="string"></attribute>
+      <attribute id="depth" title="depth" type="integer"></attribute>
+    </attributes>
+    <attributes class="edge">
+      <attribute id="kind" title="kind" type="string"></attribute>
+      <attribute id="constraint" title="constraint" type="string"></attribute>
+      <attribute id="optional" title="optional" type="boolean"></attribute>
+    </attributes>
+    <nodes>
+      <node id="n1" label="app@1.0.0">
+        <attvalues>
+          <attvalue for="name" value="app"></attvalue>
+          <attvalue for="version" value="1.0.0"></attvalue>
+          <attvalue for="depth" value="0"></attvalue>
+        </attvalues>
+      </node>
+      <node id="n2" label="@babel/core@7.23.5">
+        <attvalues>
+          <attvalue for="name" value="@babel/core"></attvalue>
+          <attvalue for="version" value="7.23.5"></attvalue>
+          <attvalue for="ecosystem" value="npm"></attvalue>
+          <attvalue for="depth" value="1"></attvalue>
+        </attvalues>
+      </node>
+      <node id="n3" label="lodash">
+        <attvalues>
+          <attvalue for="name" value="lodash"></attvalue>
+          <attvalue for="ecosystem" value="npm"></attvalue>
+          <attvalue for="depth" value="1"></attvalue>
+        </attvalues>
+      </node>
+    </nodes>
+    <edges>
+      <edge id="e1" source="n1" target="n2">
+        <attvalues>
+          <attvalue for="kind" value="prod"></attvalue>
+          <attvalue for="constraint" value="^7.0.0"></attvalue>
+          <attvalue for="optional" value="false"></attvalue>
+        </attvalues>
+      </edge>
+      <edge id="e2" source="n1" target="n3">
+        <attvalues>
+          <attvalue for="optional" value="true"></attvalue>
+        </attvalues>
+      </edge>
+      <edge id="e3" source="n2" target="n3">
+        <attvalues>
+          <attvalue for="kind" value="peer"></attvalue>
+          <attvalue for="optional" value="false"></attvalue>
+        </attvalues>
+      </edge>
+    </edges>
+  </graph>
+</gexf>
+`
+		var buf bytes.Buffer
+		assert.NoError(t, (&GEXFSerializer{}).Serialize(app, edges, &buf))
+		assert.Equal(t, expected, buf.String())
+	})
+}
@@ -0,0 +1,75 @@
+package gml
+
+import (
+	"depviz/internal/models"
+	"depviz/internal/serializer"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// GMLSerializer writes the graph in the Graph Modelling Language. Integer
+// attributes are written as numbers, the others as strings.
+type GMLSerializer struct {
+}
+
+// quote writes a GML string. Strings can't contain quotes and are 7-bit ASCII,
+// other characters are written as entities.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"':
+			b.WriteString("&quot;")
+		case r == '&':
+			b.WriteString("&amp;")
+		case r < ' ' || r > '~':
+			fmt.Fprintf(&b, "&#%d;", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (s *GMLSerializer) Serialize(root models.Package, graph []models.Edge, out io.Writer) error {
+	var b strings.Builder
+	b.WriteString("graph [\n\tdirected 1\n")
+
+	depths := serializer.Depths(root, graph)
+	ids := make(map[models.Package]int)
+	for i, pkg := range serializer.Nodes(root, graph) {
+		ids[pkg] = i + 1
+		fmt.Fprintf(&b, "\tnode [\n\t\tid %d\n\t\tlabel %s\n\t\tname %s\n", i+1, quote(pkg.String()), quote(pkg.Name))
+		if pkg.Version != "" {
+			fmt.Fprintf(&b, "\t\tversion %s\n", quote(pkg.Version))
+		}
+		if pkg.Ecosystem != "" {
+			fmt.Fprintf(&b, "\t\tecosystem %s\n", quote(pkg.Ecosystem))
+		}
+		fmt.Fprintf(&b, "\t\tdepth %d\n\t]\n", depths[pkg])
+	}
+	for _, edge := range graph {
+		fmt.Fprintf(&b, "\tedge [\n\t\tsource %d\n\t\ttarget %d\n", ids[edge.From], ids[edge.To])
+		if edge.Kind != "" {
+			fmt.Fprintf(&b, "\t\tkind %s\n", quote(edge.Kind))
+		}
+		if edge.Constraint != "" {
+			fmt.Fprintf(&b, "\t\tconstraint %s\n", quote(edge.Constraint))
+		}
+		fmt.Fprintf(&b, "\t\toptional %d\n\t]\n", boolToInt(edge.Optional))
+	}
+	b.WriteString("]\n")
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
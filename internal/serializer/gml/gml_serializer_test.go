@@ -0,0 +1,74 @@
+package gml
+
+import (
+	"bytes"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_GMLSerializer_Serialize(t *testing.T) {
+	app := models.Package{Name: "app", Version: "1.0.0"}
+	core := models.Package{Ecosystem: models.Npm, Name: "@babel/core", Version: "7.23.5"}
+	lodash := models.Package{Ecosystem: models.Npm, Name: "lodash"}
+	edges := []models.Edge{
+		{From: app, To: core, Constraint: "^7.0.0", Kind: "prod"},
+		{From: app, To: lodash, Optional: true},
+		{From: core, To: lodash, Kind: "peer"},
+	}
+
+	t.Run("test GMLSerializer", func(t *testing.T) {
+		expected := `graph [
+	directed 1
+	node [
+		id 1
+		label "app@1.0.0"
+		name "app"
+		version "1.0.0"
+		depth 0
+	]
+	node [
+		id 2
+		label "@babel/core@7.23.5"
+		name "@babel/core"
+		version "7.23.5"
+		ecosystem "npm"
+		depth 1
+	]
+	node [
+		id 3
+		label "lodash"
+		name "lodash"
+		ecosystem "npm"
+		depth 1
+	]
+	edge [
+		source 1
+		target 2
+		kind "prod"
+		constraint "^7.0.0"
+		optional 0
+	]
+	edge [
+		source 1
+		target 3
+		optional 1
+	]
+	edge [
+		source 2
+		target 3
+		kind "peer"
+		optional 0
+	]
+]
+`
+		var buf bytes.Buffer
+		assert.NoError(t, (&GMLSerializer{}).Serialize(app, edges, &buf))
+		assert.Equal(t, expected, buf.String())
+	})
+	t.Run("test quote", func(t *testing.T) {
+		assert.Equal(t, `"@babel/core"`, quote("@babel/core"))
+		assert.Equal(t, `"a&quot;b&amp;c"`, quote(`a"b&c`))
+		assert.Equal(t, `"caf&#233;"`, quote("café"))
+	})
+}
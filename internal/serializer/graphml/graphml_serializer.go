@@ -0,0 +1,123 @@
+package graphml
+
+import (
+	"depviz/internal/models"
+	"depviz/internal/serializer"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// GraphMLSerializer writes the graph as GraphML, e.g. for yEd or Gephi.
+// Packages and dependencies carry their attributes as typed keys.
+type GraphMLSerializer struct {
+}
+
+type key struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+// keys declares the attributes.
+var keys = []key{
+	{ID: "label", For: "node", Name: "label", Type: "string"},
+	{ID: "name", For: "node", Name: "name", Type: "string"},
+	{ID: "version", For: "node", Name: "version", Type: "string"},
+	{ID: "ecosystem", For: "node", Name: "ecosystem", Type: "string"},
+	{ID: "depth", For: "node", Name: "depth", Type: "int"},
+	{ID: "kind", For: "edge", Name: "kind", Type: "string"},
+	{ID: "constraint", For: "edge", Name: "constraint", Type: "string"},
+	{ID: "optional", For: "edge", Name: "optional", Type: "boolean"},
+}
+
+type data struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type node struct {
+	ID   string `xml:"id,attr"`
+	Data []data `xml:"data"`
+}
+
+type edge struct {
+	ID     string `xml:"id,attr"`
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+	Data   []data `xml:"data"`
+}
+
+type document struct {
+	XMLName        xml.Name `xml:"graphml"`
+	XMLNS          string   `xml:"xmlns,attr"`
+	XSI            string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Keys           []key    `xml:"key"`
+	Graph          struct {
+		ID          string `xml:"id,attr"`
+		EdgeDefault string `xml:"edgedefault,attr"`
+		Nodes       []node `xml:"node"`
+		Edges       []edge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+// attributes skips empty values, tools treat missing data as the default.
+func attributes(pairs ...string) []data {
+	var result []data
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			result = append(result, data{Key: pairs[i], Value: pairs[i+1]})
+		}
+	}
+	return result
+}
+
+func (s *GraphMLSerializer) Serialize(root models.Package, graph []models.Edge, out io.Writer) error {
+	doc := document{
+		XMLNS:          "http://graphml.graphdrawing.org/xmlns",
+		XSI:            "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: "http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd",
+		Keys:           keys,
+	}
+	doc.Graph.ID = "dependencies"
+	doc.Graph.EdgeDefault = "directed"
+
+	depths := serializer.Depths(root, graph)
+	ids := make(map[models.Package]string)
+	for i, pkg := range serializer.Nodes(root, graph) {
+		ids[pkg] = fmt.Sprintf("n%d", i+1)
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node{ID: ids[pkg], Data: attributes(
+			"label", pkg.String(),
+			"name", pkg.Name,
+			"version", pkg.Version,
+			"ecosystem", pkg.Ecosystem,
+			"depth", strconv.Itoa(depths[pkg]),
+		)})
+	}
+	for i, e := range graph {
+		doc.Graph.Edges = append(doc.Graph.Edges, edge{
+			ID:     fmt.Sprintf("e%d", i+1),
+			Source: ids[e.From],
+			Target: ids[e.To],
+			Data: attributes(
+				"kind", e.Kind,
+				"constraint", e.Constraint,
+				"optional", strconv.FormatBool(e.Optional),
+			),
+		})
+	}
+
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(out, "\n")
+	return err
+}
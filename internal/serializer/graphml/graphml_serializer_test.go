@@ -0,0 +1,80 @@
+package graphml
+
+import (
+	"bytes"
+	"depviz/internal/models"
+	"encoding/xml"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_GraphMLSerializer_Serialize(t *testing.T) {
+	app := models.Package{Name: "app", Version: "1.0.0"}
+	core := models.Package{Ecosystem: models.Npm, Name: "@babel/core", Version: "7.23.5"}
+	lodash := models.Package{Ecosystem: models.Npm, Name: "lodash"}
+	edges := []models.Edge{
+		{From: app, To: core, Constraint: "^7.0.0", Kind: "prod"},
+		{From: app, To: lodash, Optional: true},
+		{From: core, To: lodash, Kind: "peer"},
+	}
+
+	t.Run("test GraphMLSerializer", func(t *testing.T) {
+		expected := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd">
+  <key id="label" for="node" attr.name="label" attr.type="string"></key>
+  <key id="name" for="node" attr.name="name" attr.type="string"></key>
+  <key id="version" for="node" attr.name="version" attr.type="string"></key>
+  <key id="ecosystem" for="node" attr.name="ecosystem" attr.type="string"></key>
+  <key id="depth" for="node" attr.name="depth" attr.type="int"></key>
+  <key id="kind" for="edge" attr.name="kind" attr.type="string"></key>
+  <key id="constraint" for="edge" attr.name="constraint" attr.type="string"></key>
+  <key id="optional" for="edge" attr.name="optional" attr.type="boolean"></key>
+  <graph id="dependencies" edgedefault="directed">
+    <node id="n1">
+      <data key="label">app@1.0.0</data>
+      <data key="name">app</data>
+      <data key="version">1.0.0</data>
+      <data key="depth">0</data>
+    </node>
+    <node id="n2">
+      <data key="label">@babel/core@7.23.5</data>
+      <data key="name">@babel/core</data>
+      <data key="version">7.23.5</data>
+      <data key="ecosystem">npm</data>
+      <data key="depth">1</data>
+    </node>
+    <node id="n3">
+      <data key="label">lodash</data>
+      <data key="name">lodash</data>
+      <data key="ecosystem">npm</data>
+      <data key="depth">1</data>
+    </node>
+    <edge id="e1" source="n1" target="n2">
+      <data key="kind">prod</data>
+      <data key="constraint">^7.0.0</data>
+      <data key="optional">false</data>
+    </edge>
+    <edge id="e2" source="n1" target="n3">
+      <data key="optional">true</data>
+    </edge>
+    <edge id="e3" source="n2" target="n3">
+      <data key="kind">peer</data>
+      <data key="optional">false</data>
+    </edge>
+  </graph>
+</graphml>
+`
+		var buf bytes.Buffer
+		assert.NoError(t, (&GraphMLSerializer{}).Serialize(app, edges, &buf))
+		assert.Equal(t, expected, buf.String())
+	})
+	t.Run("test names are escaped", func(t *testing.T) {
+		var buf bytes.Buffer
+		root := models.Package{Name: "a<b>"}
+		edges := []models.Edge{{From: root, To: models.Package{Name: `c"&d`}}}
+		assert.NoError(t, (&GraphMLSerializer{}).Serialize(root, edges, &buf))
+		var doc document
+		assert.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, []data{{Key: "label", Value: `c"&d`}, {Key: "name", Value: `c"&d`}, {Key: "depth", Value: "1"}}, doc.Graph.Nodes[1].Data)
+	})
+}
@@ -0,0 +1,67 @@
+package html
+
+import (
+	"depviz/internal/models"
+	"depviz/internal/serializer"
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"io"
+)
+
+// HTMLSerializer writes the graph as a single HTML page with the graph data
+// embedded and a viewer that works offline: packages are laid out in columns
+// by depth, the page can be panned, zoomed and searched, subtrees collapsed
+// and the ancestors and descendants of a package highlighted.
+type HTMLSerializer struct {
+}
+
+//go:embed viewer.html
+var viewer string
+
+var page = template.Must(template.New("viewer").Parse(viewer))
+
+type node struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Ecosystem string `json:"ecosystem"`
+	Depth     int    `json:"depth"`
+}
+
+// edge refers to the packages by their index in the nodes.
+type edge struct {
+	From       int    `json:"from"`
+	To         int    `json:"to"`
+	Constraint string `json:"constraint"`
+	Kind       string `json:"kind"`
+	Optional   bool   `json:"optional"`
+}
+
+func (s *HTMLSerializer) Serialize(root models.Package, graph []models.Edge, out io.Writer) error {
+	var data struct {
+		Nodes []node `json:"nodes"`
+		Edges []edge `json:"edges"`
+	}
+	data.Nodes = []node{}
+	data.Edges = []edge{}
+
+	depths := serializer.Depths(root, graph)
+	ids := make(map[models.Package]int)
+	for i, pkg := range serializer.Nodes(root, graph) {
+		ids[pkg] = i
+		data.Nodes = append(data.Nodes, node{Name: pkg.Name, Version: pkg.Version, Ecosystem: pkg.Ecosystem, Depth: depths[pkg]})
+	}
+	for _, e := range graph {
+		data.Edges = append(data.Edges, edge{From: ids[e.From], To: ids[e.To], Constraint: e.Constraint, Kind: e.Kind, Optional: e.Optional})
+	}
+	// json escapes "<", ">" and "&", so the data can't end the script
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return page.Execute(out, struct {
+		Title string
+		Graph template.JS
+	}{Title: root.String(), Graph: template.JS(encoded)})
+}
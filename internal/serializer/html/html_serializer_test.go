@@ -0,0 +1,49 @@
+package html
+
+import (
+	"bytes"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func Test_HTMLSerializer_Serialize(t *testing.T) {
+	t.Run("test HTMLSerializer embeds the graph", func(t *testing.T) {
+		app := models.Package{Name: "app", Version: "1.0.0"}
+		core := models.Package{Ecosystem: models.Npm, Name: "@babel/core", Version: "7.23.5"}
+		edges := []models.Edge{
+			{From: app, To: core, Constraint: "^7.0.0", Kind: "prod"},
+			{From: core, To: app, Optional: true},
+		}
+		var buf bytes.Buffer
+		assert.NoError(t, (&HTMLSerializer{}).Serialize(app, edges, &buf))
+		out := buf.String()
+		assert.Contains(t, out, "<title>app@1.0.0 – depviz</title>")
+		assert.Contains(t, out, `const graph = {"nodes":[`+
+			`{"name":"app","version":"1.0.0","ecosystem":"","depth":0},`+
+			`{"name":"@babel/core","version":"7.23.5","ecosystem":"npm","depth":1}],"edges":[`+
+			`{"from":0,"to":1,"constraint":"^7.0.0","kind":"prod","optional":false},`+
+			`{"from":1,"to":0,"constraint":"","kind":"","optional":true}]};`)
+	})
+	t.Run("test names can't break out of the page", func(t *testing.T) {
+		root := models.Package{Name: "</script><script>alert(1)"}
+		edges := []models.Edge{{From: root, To: models.Package{Name: "a&b"}}}
+		var buf bytes.Buffer
+		assert.NoError(t, (&HTMLSerializer{}).Serialize(root, edges, &buf))
+		out := buf.String()
+		assert.Equal(t, 1, strings.Count(out, "<script>"))
+		assert.Equal(t, 1, strings.Count(out, "</script>"))
+		assert.Contains(t, out, "<title>&lt;/script&gt;&lt;script&gt;alert(1) – depviz</title>")
+		assert.Contains(t, out, `"name":"\u003c/script\u003e\u003cscript\u003ealert(1)"`)
+		assert.Contains(t, out, `"name":"a\u0026b"`)
+	})
+	t.Run("test root without dependencies", func(t *testing.T) {
+		app := models.Package{Ecosystem: models.Npm, Name: "app", Version: "1.0.0"}
+		var buf bytes.Buffer
+		assert.NoError(t, (&HTMLSerializer{}).Serialize(app, nil, &buf))
+		assert.Contains(t, buf.String(), "<title>app@1.0.0 – depviz</title>")
+		assert.Contains(t, buf.String(),
+			`const graph = {"nodes":[{"name":"app","version":"1.0.0","ecosystem":"npm","depth":0}],"edges":[]};`)
+	})
+}
@@ -0,0 +1,92 @@
+package json
+
+import (
+	"depviz/internal/models"
+	"depviz/internal/serializer"
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// JSONSerializer writes the graph as a JSON document of nodes and edges.
+// Packages are identified by their package url. The output doesn't depend on
+// the order of the edges: nodes are sorted by depth, the root comes first,
+// then by ecosystem, name and version, edges are sorted by their ends.
+type JSONSerializer struct {
+}
+
+type node struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Ecosystem string `json:"ecosystem"`
+	// Depth is the length of the shortest path from the root
+	Depth int `json:"depth"`
+}
+
+type edge struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Constraint string `json:"constraint"`
+	Kind       string `json:"kind"`
+	Optional   bool   `json:"optional"`
+}
+
+type document struct {
+	Nodes []node `json:"nodes"`
+	Edges []edge `json:"edges"`
+}
+
+func (s *JSONSerializer) Serialize(root models.Package, graph []models.Edge, out io.Writer) error {
+	depths := serializer.Depths(root, graph)
+	result := document{Nodes: []node{}, Edges: []edge{}}
+	for _, pkg := range serializer.Nodes(root, graph) {
+		result.Nodes = append(result.Nodes, node{
+			ID:        pkg.PURL(),
+			Name:      pkg.Name,
+			Version:   pkg.Version,
+			Ecosystem: pkg.Ecosystem,
+			Depth:     depths[pkg],
+		})
+	}
+	nodes := result.Nodes[1:]
+	sort.Slice(nodes, func(i, j int) bool {
+		a, b := nodes[i], nodes[j]
+		if a.Depth != b.Depth {
+			return a.Depth < b.Depth
+		}
+		if a.Ecosystem != b.Ecosystem {
+			return a.Ecosystem < b.Ecosystem
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+
+	seen := make(map[edge]struct{}, len(graph))
+	for _, e := range graph {
+		item := edge{From: e.From.PURL(), To: e.To.PURL(), Constraint: e.Constraint, Kind: e.Kind, Optional: e.Optional}
+		if _, ok := seen[item]; !ok {
+			seen[item] = struct{}{}
+			result.Edges = append(result.Edges, item)
+		}
+	}
+	sort.Slice(result.Edges, func(i, j int) bool {
+		a, b := result.Edges[i], result.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Constraint < b.Constraint
+	})
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
@@ -0,0 +1,126 @@
+package json
+
+import (
+	"bytes"
+	"depviz/internal/models"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_JSONSerializer_Serialize(t *testing.T) {
+	app := models.Package{Ecosystem: models.Npm, Name: "app", Version: "1.0.0"}
+	core := models.Package{Ecosystem: models.Npm, Name: "@babel/core", Version: "7.23.5"}
+	lodash := models.Package{Ecosystem: models.Npm, Name: "lodash", Version: "4.17.21"}
+	debug := models.Package{Ecosystem: models.Npm, Name: "debug", Version: "4.3.4"}
+
+	expected := `{
+  "nodes": [
+    {
+      "id": "pkg:npm/app@1.0.0",
+      "name": "app",
+      "version": "1.0.0",
+      "ecosystem": "npm",
+      "depth": 0
+    },
+    {
+      "id": "pkg:npm/%40babel/core@7.23.5",
+      "name": "@babel/core",
+      "version": "7.23.5",
+      "ecosystem": "npm",
+      "depth": 1
+    },
+    {
+      "id": "pkg:npm/lodash@4.17.21",
+      "name": "lodash",
+      "version": "4.17.21",
+      "ecosystem": "npm",
+      "depth": 1
+    },
+    {
+      "id": "pkg:npm/debug@4.3.4",
+      "name": "debug",
+      "version": "4.3.4",
+      "ecosystem": "npm",
+      "depth": 2
+    }
+  ],
+  "edges": [
+    {
+      "from": "pkg:npm/%40babel/core@7.23.5",
+      "to": "pkg:npm/debug@4.3.4",
+      "constraint": "^4.1.0",
+      "kind": "prod",
+      "optional": false
+    },
+    {
+      "from": "pkg:npm/%40babel/core@7.23.5",
+      "to": "pkg:npm/lodash@4.17.21",
+      "constraint": "",
+      "kind": "peer",
+      "optional": false
+    },
+    {
+      "from": "pkg:npm/%40babel/core@7.23.5",
+      "to": "pkg:npm/lodash@4.17.21",
+      "constraint": "",
+      "kind": "prod",
+      "optional": false
+    },
+    {
+      "from": "pkg:npm/app@1.0.0",
+      "to": "pkg:npm/%40babel/core@7.23.5",
+      "constraint": "",
+      "kind": "",
+      "optional": true
+    },
+    {
+      "from": "pkg:npm/app@1.0.0",
+      "to": "pkg:npm/lodash@4.17.21",
+      "constraint": "^4.17.0",
+      "kind": "prod",
+      "optional": false
+    }
+  ]
+}
+`
+
+	t.Run("test JSONSerializer", func(t *testing.T) {
+		edges := []models.Edge{
+			{From: app, To: core, Optional: true},
+			{From: app, To: lodash, Constraint: "^4.17.0", Kind: "prod"},
+			{From: core, To: lodash, Kind: "prod"},
+			{From: core, To: lodash, Kind: "peer"},
+			{From: core, To: debug, Constraint: "^4.1.0", Kind: "prod"},
+		}
+		out := &bytes.Buffer{}
+		assert.NoError(t, (&JSONSerializer{}).Serialize(app, edges, out))
+		assert.Equal(t, expected, out.String())
+	})
+
+	t.Run("test JSONSerializer doesn't depend on the order of edges", func(t *testing.T) {
+		edges := []models.Edge{
+			{From: app, To: lodash, Constraint: "^4.17.0", Kind: "prod"},
+			{From: app, To: core, Optional: true},
+			{From: core, To: debug, Constraint: "^4.1.0", Kind: "prod"},
+			{From: core, To: lodash, Kind: "peer"},
+			{From: core, To: lodash, Kind: "prod"},
+			{From: core, To: lodash, Kind: "prod"},
+		}
+		out := &bytes.Buffer{}
+		assert.NoError(t, (&JSONSerializer{}).Serialize(app, edges, out))
+		assert.Equal(t, expected, out.String())
+	})
+
+	t.Run("test JSONSerializer root without dependencies", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		assert.NoError(t, (&JSONSerializer{}).Serialize(app, nil, out))
+		var doc document
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+		assert.Equal(t, document{
+			Nodes: []node{{ID: "pkg:npm/app@1.0.0", Name: "app", Version: "1.0.0", Ecosystem: models.Npm}},
+			Edges: []edge{},
+		}, doc)
+	})
+
+}
@@ -0,0 +1,48 @@
+package mermaid
+
+import (
+	"depviz/internal/models"
+	"depviz/internal/serializer"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MermaidSerializer writes the graph as a Mermaid flowchart, which GitHub
+// renders in markdown code blocks.
+type MermaidSerializer struct {
+}
+
+// labelEscaper replaces characters which end a quoted label or are read as
+// markup by their entity codes. Package names like "@babel/core" are fine
+// inside quotes, but break unquoted node text.
+var labelEscaper = strings.NewReplacer(
+	`#`, "#35;",
+	`"`, "#quot;",
+	`<`, "#lt;",
+	`>`, "#gt;",
+	"`", "#96;",
+)
+
+func (s *MermaidSerializer) Serialize(root models.Package, graph []models.Edge, out io.Writer) error {
+	if _, err := fmt.Fprintln(out, "graph TD"); err != nil {
+		return err
+	}
+	ids := make(map[models.Package]int)
+	for i, pkg := range serializer.Nodes(root, graph) {
+		ids[pkg] = i + 1
+		if _, err := fmt.Fprintf(out, "\tn%d[\"%s\"]\n", i+1, labelEscaper.Replace(pkg.String())); err != nil {
+			return err
+		}
+	}
+	for _, edge := range graph {
+		arrow := "-->"
+		if edge.Optional {
+			arrow = "-.->"
+		}
+		if _, err := fmt.Fprintf(out, "\tn%d %s n%d\n", ids[edge.From], arrow, ids[edge.To]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
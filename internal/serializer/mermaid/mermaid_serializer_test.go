@@ -0,0 +1,49 @@
+package mermaid
+
+import (
+	"bytes"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_MermaidSerializer_Serialize(t *testing.T) {
+	t.Run("test MermaidSerializer", func(t *testing.T) {
+		app := models.Package{Name: "app", Version: "1.0.0"}
+		core := models.Package{Ecosystem: models.Npm, Name: "@babel/core", Version: "7.23.5"}
+		lodash := models.Package{Ecosystem: models.Npm, Name: "lodash"}
+		edges := []models.Edge{
+			{From: app, To: core},
+			{From: app, To: lodash, Optional: true},
+			{From: core, To: lodash},
+		}
+		expected := `graph TD
+	n1["app@1.0.0"]
+	n2["@babel/core@7.23.5"]
+	n3["lodash"]
+	n1 --> n2
+	n1 -.-> n3
+	n2 --> n3
+`
+		var buf bytes.Buffer
+		assert.NoError(t, (&MermaidSerializer{}).Serialize(app, edges, &buf))
+		assert.Equal(t, expected, buf.String())
+	})
+	t.Run("test labels are escaped", func(t *testing.T) {
+		root := models.Package{Name: `a"b`}
+		edges := []models.Edge{{From: root, To: models.Package{Name: "c#<d>", Version: "`1`"}}}
+		expected := `graph TD
+	n1["a#quot;b"]
+	n2["c#35;#lt;d#gt;@#96;1#96;"]
+	n1 --> n2
+`
+		var buf bytes.Buffer
+		assert.NoError(t, (&MermaidSerializer{}).Serialize(root, edges, &buf))
+		assert.Equal(t, expected, buf.String())
+	})
+	t.Run("test root without dependencies", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, (&MermaidSerializer{}).Serialize(models.Package{Name: "app"}, nil, &buf))
+		assert.Equal(t, "graph TD\n\tn1[\"app\"]\n", buf.String())
+	})
+}
@@ -0,0 +1,62 @@
+package plantuml
+
+import (
+	"depviz/internal/models"
+	"depviz/internal/serializer"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PlantUMLSerializer writes the graph as a PlantUML component diagram.
+type PlantUMLSerializer struct {
+}
+
+// markup lists characters of creole markup, e.g. "__" underlines and "--"
+// strikes through. They only take effect when doubled.
+const markup = "*_-/~=^'\""
+
+// escapeLabel escapes a package name for a quoted component name. Quotes
+// can't be escaped inside it and are written as unicode, "~" escapes the
+// next character from creole markup.
+func escapeLabel(label string) string {
+	var b strings.Builder
+	var prev rune
+	for _, r := range label {
+		switch {
+		case r == '"':
+			b.WriteString("<U+0022>")
+		case r == '~' || r == '<' || r == prev && strings.ContainsRune(markup, r):
+			b.WriteRune('~')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+		prev = r
+	}
+	return b.String()
+}
+
+func (s *PlantUMLSerializer) Serialize(root models.Package, graph []models.Edge, out io.Writer) error {
+	if _, err := fmt.Fprintln(out, "@startuml"); err != nil {
+		return err
+	}
+	ids := make(map[models.Package]int)
+	for i, pkg := range serializer.Nodes(root, graph) {
+		ids[pkg] = i + 1
+		if _, err := fmt.Fprintf(out, "component \"%s\" as n%d\n", escapeLabel(pkg.String()), i+1); err != nil {
+			return err
+		}
+	}
+	for _, edge := range graph {
+		arrow := "-->"
+		if edge.Optional {
+			arrow = "..>"
+		}
+		if _, err := fmt.Fprintf(out, "n%d %s n%d\n", ids[edge.From], arrow, ids[edge.To]); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(out, "@enduml")
+	return err
+}
@@ -0,0 +1,39 @@
+package plantuml
+
+import (
+	"bytes"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_PlantUMLSerializer_Serialize(t *testing.T) {
+	t.Run("test PlantUMLSerializer", func(t *testing.T) {
+		app := models.Package{Name: "app", Version: "1.0.0"}
+		core := models.Package{Ecosystem: models.Npm, Name: "@babel/core", Version: "7.23.5"}
+		lodash := models.Package{Ecosystem: models.Npm, Name: "lodash"}
+		edges := []models.Edge{
+			{From: app, To: core},
+			{From: app, To: lodash, Optional: true},
+			{From: core, To: lodash},
+		}
+		expected := `@startuml
+component "app@1.0.0" as n1
+component "@babel/core@7.23.5" as n2
+component "lodash" as n3
+n1 --> n2
+n1 ..> n3
+n2 --> n3
+@enduml
+`
+		var buf bytes.Buffer
+		assert.NoError(t, (&PlantUMLSerializer{}).Serialize(app, edges, &buf))
+		assert.Equal(t, expected, buf.String())
+	})
+	t.Run("test escapeLabel", func(t *testing.T) {
+		assert.Equal(t, "@babel/core@7.23.5", escapeLabel("@babel/core@7.23.5"))
+		assert.Equal(t, "a<U+0022>b", escapeLabel(`a"b`))
+		assert.Equal(t, "_~_init_~_", escapeLabel("__init__"))
+		assert.Equal(t, "a-~-b~~~<b>", escapeLabel("a--b~<b>"))
+	})
+}
@@ -0,0 +1,43 @@
+package serializer
+
+import "depviz/internal/models"
+
+// Nodes returns the root and the packages of the graph in order of their
+// first appearance, so the root is the first node even without edges.
+func Nodes(root models.Package, graph []models.Edge) []models.Package {
+	seen := make(map[models.Package]struct{}, len(graph)+1)
+	var result []models.Package
+	add := func(pkg models.Package) {
+		if _, ok := seen[pkg]; !ok {
+			seen[pkg] = struct{}{}
+			result = append(result, pkg)
+		}
+	}
+	add(root)
+	for _, edge := range graph {
+		add(edge.From)
+		add(edge.To)
+	}
+	return result
+}
+
+// Depths returns the length of the shortest path from the root to every package.
+func Depths(root models.Package, graph []models.Edge) map[models.Package]int {
+	deps := make(map[models.Package][]models.Package)
+	for _, edge := range graph {
+		deps[edge.From] = append(deps[edge.From], edge.To)
+	}
+	result := map[models.Package]int{root: 0}
+	queue := []models.Package{root}
+	for len(queue) != 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		for _, dep := range deps[pkg] {
+			if _, ok := result[dep]; !ok {
+				result[dep] = result[pkg] + 1
+				queue = append(queue, dep)
+			}
+		}
+	}
+	return result
+}
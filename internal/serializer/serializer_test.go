@@ -0,0 +1,16 @@
+package serializer
+
+import (
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNodes(t *testing.T) {
+	a, b, c := models.Package{Name: "a"}, models.Package{Name: "b"}, models.Package{Name: "c"}
+	graph := []models.Edge{{From: a, To: b}, {From: a, To: c}, {From: b, To: c}, {From: c, To: a}}
+	assert.Equal(t, []models.Package{a, b, c}, Nodes(a, graph))
+	assert.Equal(t, map[models.Package]int{a: 0, b: 1, c: 1}, Depths(a, graph))
+	assert.Equal(t, []models.Package{a}, Nodes(a, nil))
+	assert.Equal(t, map[models.Package]int{a: 0}, Depths(a, nil))
+}
@@ -0,0 +1,155 @@
+package spdx
+
+import (
+	"crypto/sha256"
+	"depviz/internal/dependency_provider/cargo"
+	"depviz/internal/dependency_provider/gem"
+	"depviz/internal/dependency_provider/npm"
+	"depviz/internal/models"
+	"depviz/internal/serializer"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SPDXSerializer writes the graph as an SPDX 2.3 JSON document describing
+// the root package.
+type SPDXSerializer struct {
+	// Created is the creation time of the document, the current time if it is zero.
+	Created time.Time
+}
+
+type externalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxPackage struct {
+	SPDXID           string        `json:"SPDXID"`
+	Name             string        `json:"name"`
+	VersionInfo      string        `json:"versionInfo,omitempty"`
+	DownloadLocation string        `json:"downloadLocation"`
+	FilesAnalyzed    bool          `json:"filesAnalyzed"`
+	ExternalRefs     []externalRef `json:"externalRefs"`
+}
+
+type relationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+type document struct {
+	SPDXVersion       string `json:"spdxVersion"`
+	DataLicense       string `json:"dataLicense"`
+	SPDXID            string `json:"SPDXID"`
+	Name              string `json:"name"`
+	DocumentNamespace string `json:"documentNamespace"`
+	CreationInfo      struct {
+		Created  string   `json:"created"`
+		Creators []string `json:"creators"`
+	} `json:"creationInfo"`
+	Packages      []spdxPackage  `json:"packages"`
+	Relationships []relationship `json:"relationships"`
+}
+
+// relationshipType maps an edge to an SPDX relationship. Only DEPENDS_ON
+// points from the dependent package, the others point the other way.
+func relationshipType(edge models.Edge) (string, bool) {
+	switch {
+	case edge.Optional:
+		return "OPTIONAL_DEPENDENCY_OF", true
+	case edge.Kind == npm.KindDev || edge.Kind == cargo.KindDev || edge.Kind == gem.KindDevelopment:
+		return "DEV_DEPENDENCY_OF", true
+	case edge.Kind == cargo.KindBuild:
+		return "BUILD_DEPENDENCY_OF", true
+	}
+	return "DEPENDS_ON", false
+}
+
+// spdxID turns a package into an identifier, which may only contain
+// letters, numbers, "." and "-".
+func spdxID(pkg models.Package) string {
+	id := strings.Map(func(r rune) rune {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, strings.Trim(pkg.Ecosystem+"-"+pkg.Name+"-"+pkg.Version, "-"))
+	return "SPDXRef-Package-" + id
+}
+
+func (s *SPDXSerializer) Serialize(root models.Package, graph []models.Edge, out io.Writer) error {
+	created := s.Created
+	if created.IsZero() {
+		created = time.Now()
+	}
+	nodes := serializer.Nodes(root, graph)
+
+	doc := document{SPDXVersion: "SPDX-2.3", DataLicense: "CC0-1.0", SPDXID: "SPDXRef-DOCUMENT"}
+	doc.CreationInfo.Created = created.UTC().Format(time.RFC3339)
+	doc.CreationInfo.Creators = []string{"Tool: depviz"}
+	doc.Packages = []spdxPackage{}
+	doc.Relationships = []relationship{}
+
+	// the namespace must be unique for every document, the same graph gets the same one.
+	// Packages are keyed by package url, python packages with extras are the same package.
+	hash := sha256.New()
+	ids := make(map[string]string, len(nodes))
+	used := make(map[string]struct{}, len(nodes))
+	for _, pkg := range nodes {
+		purl := pkg.PURL()
+		if _, ok := ids[purl]; ok {
+			continue
+		}
+		pkg = pkg.WithoutExtras()
+		_, _ = fmt.Fprintln(hash, purl)
+		id := spdxID(pkg)
+		for i := 2; ; i++ {
+			if _, ok := used[id]; !ok {
+				break
+			}
+			id = fmt.Sprintf("%s-%d", spdxID(pkg), i)
+		}
+		used[id] = struct{}{}
+		ids[purl] = id
+		doc.Packages = append(doc.Packages, spdxPackage{
+			SPDXID:           id,
+			Name:             pkg.Name,
+			VersionInfo:      pkg.Version,
+			DownloadLocation: "NOASSERTION",
+			ExternalRefs: []externalRef{
+				{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: purl},
+			},
+		})
+	}
+	doc.Name = root.String()
+	doc.Relationships = append(doc.Relationships, relationship{Element: doc.SPDXID, Type: "DESCRIBES", Related: ids[root.PURL()]})
+	doc.DocumentNamespace = fmt.Sprintf("https://spdx.org/spdxdocs/depviz/%s-%s",
+		url.PathEscape(doc.Name), hex.EncodeToString(hash.Sum(nil))[:16])
+
+	seen := make(map[relationship]struct{}, len(graph))
+	for _, edge := range graph {
+		r := relationship{Element: ids[edge.From.PURL()], Related: ids[edge.To.PURL()]}
+		if r.Element == r.Related {
+			continue
+		}
+		var reversed bool
+		if r.Type, reversed = relationshipType(edge); reversed {
+			r.Element, r.Related = r.Related, r.Element
+		}
+		if _, ok := seen[r]; !ok {
+			seen[r] = struct{}{}
+			doc.Relationships = append(doc.Relationships, r)
+		}
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
@@ -0,0 +1,132 @@
+package spdx
+
+import (
+	"bytes"
+	"context"
+	"depviz/internal/dependency_provider/sbom"
+	"depviz/internal/models"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_SPDXSerializer_Serialize(t *testing.T) {
+	app := models.Package{Ecosystem: models.Npm, Name: "app", Version: "1.0.0"}
+	core := models.Package{Ecosystem: models.Npm, Name: "@babel/core", Version: "7.23.5"}
+	lodash := models.Package{Ecosystem: models.Npm, Name: "lodash", Version: "4.17.21"}
+	jest := models.Package{Ecosystem: models.Npm, Name: "jest", Version: "29.7.0"}
+	edges := []models.Edge{
+		{From: app, To: core, Optional: true},
+		{From: app, To: lodash, Kind: "prod"},
+		{From: app, To: jest, Kind: "dev"},
+		{From: core, To: lodash, Kind: "prod"},
+	}
+	s := SPDXSerializer{Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	t.Run("test SPDXSerializer", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, s.Serialize(app, edges, &buf))
+
+		var doc document
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+		assert.Equal(t, "app@1.0.0", doc.Name)
+		assert.Equal(t, "2024-01-02T03:04:05Z", doc.CreationInfo.Created)
+		assert.Regexp(t, `^https://spdx.org/spdxdocs/depviz/app@1.0.0-[0-9a-f]{16}$`, doc.DocumentNamespace)
+		assert.Equal(t, spdxPackage{
+			SPDXID:           "SPDXRef-Package-npm--babel-core-7.23.5",
+			Name:             "@babel/core",
+			VersionInfo:      "7.23.5",
+			DownloadLocation: "NOASSERTION",
+			ExternalRefs: []externalRef{
+				{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: "pkg:npm/%40babel/core@7.23.5"},
+			},
+		}, doc.Packages[1])
+		assert.Equal(t, []relationship{
+			{Element: "SPDXRef-DOCUMENT", Type: "DESCRIBES", Related: "SPDXRef-Package-npm-app-1.0.0"},
+			{Element: "SPDXRef-Package-npm--babel-core-7.23.5", Type: "OPTIONAL_DEPENDENCY_OF", Related: "SPDXRef-Package-npm-app-1.0.0"},
+			{Element: "SPDXRef-Package-npm-app-1.0.0", Type: "DEPENDS_ON", Related: "SPDXRef-Package-npm-lodash-4.17.21"},
+			{Element: "SPDXRef-Package-npm-jest-29.7.0", Type: "DEV_DEPENDENCY_OF", Related: "SPDXRef-Package-npm-app-1.0.0"},
+			{Element: "SPDXRef-Package-npm--babel-core-7.23.5", Type: "DEPENDS_ON", Related: "SPDXRef-Package-npm-lodash-4.17.21"},
+		}, doc.Relationships)
+	})
+
+	t.Run("test identifiers are unique", func(t *testing.T) {
+		var buf bytes.Buffer
+		root := models.Package{Name: "a_b"}
+		assert.NoError(t, s.Serialize(root, []models.Edge{
+			{From: root, To: models.Package{Name: "a-b"}},
+		}, &buf))
+		var doc document
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, "SPDXRef-Package-a-b", doc.Packages[0].SPDXID)
+		assert.Equal(t, "SPDXRef-Package-a-b-2", doc.Packages[1].SPDXID)
+	})
+
+	t.Run("test relationship types follow the kinds of providers", func(t *testing.T) {
+		crate := models.Package{Ecosystem: models.Cargo, Name: "app", Version: "0.1.0"}
+		cc := models.Package{Ecosystem: models.Cargo, Name: "cc", Version: "1.0.83"}
+		rails := models.Package{Ecosystem: models.Gem, Name: "rails", Version: "7.1.2"}
+		rspec := models.Package{Ecosystem: models.Gem, Name: "rspec", Version: "3.12.0"}
+
+		var buf bytes.Buffer
+		assert.NoError(t, s.Serialize(crate, []models.Edge{
+			{From: crate, To: cc, Kind: "build"},
+			{From: rails, To: rspec, Kind: "development"},
+		}, &buf))
+		var doc document
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, "BUILD_DEPENDENCY_OF", doc.Relationships[1].Type)
+		assert.Equal(t, "DEV_DEPENDENCY_OF", doc.Relationships[2].Type)
+	})
+
+	t.Run("test python extras are the same package", func(t *testing.T) {
+		app := models.Package{Ecosystem: models.PyPI, Name: "app", Version: "1.0.0"}
+		requests := models.Package{Ecosystem: models.PyPI, Name: "requests", Version: "2.31.0"}
+		socks := models.Package{Ecosystem: models.PyPI, Name: "requests[socks]", Version: "2.31.0"}
+
+		var buf bytes.Buffer
+		assert.NoError(t, s.Serialize(app, []models.Edge{
+			{From: app, To: socks},
+			{From: app, To: requests},
+		}, &buf))
+		var doc document
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		assert.Len(t, doc.Packages, 2)
+		assert.Equal(t, "requests", doc.Packages[1].Name)
+		assert.Equal(t, "pkg:pypi/requests@2.31.0", doc.Packages[1].ExternalRefs[0].ReferenceLocator)
+		assert.Equal(t, []relationship{
+			{Element: "SPDXRef-DOCUMENT", Type: "DESCRIBES", Related: "SPDXRef-Package-pypi-app-1.0.0"},
+			{Element: "SPDXRef-Package-pypi-app-1.0.0", Type: "DEPENDS_ON", Related: "SPDXRef-Package-pypi-requests-2.31.0"},
+		}, doc.Relationships)
+	})
+
+	t.Run("test document can be read back", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, s.Serialize(app, edges, &buf))
+		g, err := sbom.Parse(buf.Bytes(), "sbom")
+		assert.NoError(t, err)
+		assert.Equal(t, app, g.Root)
+
+		deps, err := g.FetchPackageDeps(context.Background(), app)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Dependency{
+			{Package: core, Optional: true},
+			{Package: lodash},
+			{Package: jest},
+		}, deps)
+	})
+
+	t.Run("test root without dependencies", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, s.Serialize(app, nil, &buf))
+		var doc document
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, "app@1.0.0", doc.Name)
+		assert.Len(t, doc.Packages, 1)
+		assert.Equal(t, []relationship{
+			{Element: "SPDXRef-DOCUMENT", Type: "DESCRIBES", Related: "SPDXRef-Package-npm-app-1.0.0"},
+		}, doc.Relationships)
+	})
+}
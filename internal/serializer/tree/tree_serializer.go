@@ -0,0 +1,126 @@
+package tree
+
+import (
+	"bufio"
+	"depviz/internal/models"
+	"io"
+	"sort"
+)
+
+// TreeSerializer writes the graph as an indented tree from the root, like
+// "npm ls" does. A package is expanded once, later occurrences are marked as
+// deduped and dependencies on a package of the current path as cycles.
+type TreeSerializer struct {
+	// ASCII draws the tree with ASCII instead of box drawing characters.
+	ASCII bool
+	// Color highlights the tree with ANSI escape codes, e.g. on a terminal.
+	Color bool
+}
+
+const (
+	bold   = "\x1b[1m"
+	dim    = "\x1b[2m"
+	yellow = "\x1b[33m"
+	reset  = "\x1b[0m"
+)
+
+type branches struct {
+	middle, last, line, space string
+}
+
+var (
+	unicodeBranches = branches{middle: "├── ", last: "└── ", line: "│   ", space: "    "}
+	asciiBranches   = branches{middle: "|-- ", last: "`-- ", line: "|   ", space: "    "}
+)
+
+// child is a dependency of a package, which is optional if all edges to it are.
+type child struct {
+	pkg      models.Package
+	optional bool
+}
+
+type printer struct {
+	out      *bufio.Writer
+	branches branches
+	color    bool
+	children map[models.Package][]child
+	expanded map[models.Package]struct{}
+	path     map[models.Package]struct{}
+}
+
+func (p *printer) colored(color, s string) string {
+	if !p.color || s == "" {
+		return s
+	}
+	return color + s + reset
+}
+
+// print writes a dependency and expands it unless it already was.
+func (p *printer) print(c child, prefix string) {
+	var note string
+	_, onPath := p.path[c.pkg]
+	_, expanded := p.expanded[c.pkg]
+	switch {
+	case onPath:
+		note = " " + p.colored(yellow, "(cycle)")
+	case expanded && len(p.children[c.pkg]) != 0:
+		note = " " + p.colored(dim, "(deduped)")
+	}
+	if c.optional {
+		note += " " + p.colored(dim, "(optional)")
+	}
+	_, _ = p.out.WriteString(c.pkg.String() + note + "\n")
+	if !onPath && !expanded {
+		p.printChildren(c.pkg, prefix)
+	}
+}
+
+func (p *printer) printChildren(pkg models.Package, prefix string) {
+	p.expanded[pkg] = struct{}{}
+	p.path[pkg] = struct{}{}
+	children := p.children[pkg]
+	for i, c := range children {
+		branch, indent := p.branches.middle, p.branches.line
+		if i == len(children)-1 {
+			branch, indent = p.branches.last, p.branches.space
+		}
+		_, _ = p.out.WriteString(prefix + p.colored(dim, branch))
+		p.print(c, prefix+p.colored(dim, indent))
+	}
+	delete(p.path, pkg)
+}
+
+func (s *TreeSerializer) Serialize(root models.Package, graph []models.Edge, out io.Writer) error {
+	p := &printer{
+		out:      bufio.NewWriter(out),
+		branches: unicodeBranches,
+		color:    s.Color,
+		children: make(map[models.Package][]child),
+		expanded: make(map[models.Package]struct{}),
+		path:     make(map[models.Package]struct{}),
+	}
+	if s.ASCII {
+		p.branches = asciiBranches
+	}
+
+	// edges come in no particular order, children are sorted to keep the output stable
+	index := make(map[models.Edge]int)
+	for _, edge := range graph {
+		key := models.Edge{From: edge.From, To: edge.To}
+		if i, ok := index[key]; ok {
+			p.children[edge.From][i].optional = p.children[edge.From][i].optional && edge.Optional
+			continue
+		}
+		index[key] = len(p.children[edge.From])
+		p.children[edge.From] = append(p.children[edge.From], child{pkg: edge.To, optional: edge.Optional})
+	}
+	for _, children := range p.children {
+		sort.Slice(children, func(i, j int) bool {
+			return children[i].pkg.String() < children[j].pkg.String()
+		})
+	}
+
+	_, _ = p.out.WriteString(p.colored(bold, root.String()) + "\n")
+	p.printChildren(root, "")
+	return p.out.Flush()
+}
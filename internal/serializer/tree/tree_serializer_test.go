@@ -0,0 +1,68 @@
+package tree
+
+import (
+	"bytes"
+	"depviz/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_TreeSerializer_Serialize(t *testing.T) {
+	app := models.Package{Name: "app", Version: "1.0.0"}
+	core := models.Package{Ecosystem: models.Npm, Name: "@babel/core", Version: "7.23.5"}
+	debug := models.Package{Ecosystem: models.Npm, Name: "debug", Version: "4.3.4"}
+	ms := models.Package{Ecosystem: models.Npm, Name: "ms", Version: "2.1.2"}
+	lodash := models.Package{Ecosystem: models.Npm, Name: "lodash", Version: "4.17.21"}
+	edges := []models.Edge{
+		{From: app, To: lodash, Optional: true},
+		{From: app, To: debug},
+		{From: app, To: core},
+		{From: core, To: debug, Kind: "prod"},
+		{From: core, To: debug, Kind: "peer"},
+		{From: debug, To: ms},
+		{From: ms, To: debug},
+		{From: core, To: lodash, Optional: true},
+	}
+
+	t.Run("test TreeSerializer", func(t *testing.T) {
+		expected := `app@1.0.0
+├── @babel/core@7.23.5
+│   ├── debug@4.3.4
+│   │   └── ms@2.1.2
+│   │       └── debug@4.3.4 (cycle)
+│   └── lodash@4.17.21 (optional)
+├── debug@4.3.4 (deduped)
+└── lodash@4.17.21 (optional)
+`
+		var buf bytes.Buffer
+		assert.NoError(t, (&TreeSerializer{}).Serialize(app, edges, &buf))
+		assert.Equal(t, expected, buf.String())
+	})
+	t.Run("test ascii", func(t *testing.T) {
+		expected := "app@1.0.0\n" +
+			"|-- @babel/core@7.23.5\n" +
+			"|   |-- debug@4.3.4\n" +
+			"|   |   `-- ms@2.1.2\n" +
+			"|   |       `-- debug@4.3.4 (cycle)\n" +
+			"|   `-- lodash@4.17.21 (optional)\n" +
+			"|-- debug@4.3.4 (deduped)\n" +
+			"`-- lodash@4.17.21 (optional)\n"
+		var buf bytes.Buffer
+		assert.NoError(t, (&TreeSerializer{ASCII: true}).Serialize(app, edges, &buf))
+		assert.Equal(t, expected, buf.String())
+	})
+	t.Run("test color", func(t *testing.T) {
+		edges := []models.Edge{{From: app, To: debug}, {From: debug, To: app}}
+		expected := "\x1b[1mapp@1.0.0\x1b[0m\n" +
+			"\x1b[2m└── \x1b[0mdebug@4.3.4\n" +
+			"\x1b[2m    \x1b[0m\x1b[2m└── \x1b[0mapp@1.0.0 \x1b[33m(cycle)\x1b[0m\n"
+		var buf bytes.Buffer
+		assert.NoError(t, (&TreeSerializer{Color: true}).Serialize(app, edges, &buf))
+		assert.Equal(t, expected, buf.String())
+	})
+	t.Run("test root without dependencies", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, (&TreeSerializer{}).Serialize(app, nil, &buf))
+		assert.Equal(t, "app@1.0.0\n", buf.String())
+	})
+}